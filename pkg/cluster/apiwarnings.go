@@ -0,0 +1,50 @@
+package cluster
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	apiWarningsMu sync.Mutex
+	apiWarnings   = make(map[string][]string)
+)
+
+// apiWarningCollector collects the Warning headers an API server returns, such as
+// deprecation notices, instead of printing them in the middle of the output
+type apiWarningCollector struct {
+	cluster string
+}
+
+// HandleWarningHeader implements rest.WarningHandler
+func (c apiWarningCollector) HandleWarningHeader(code int, agent string, text string) {
+	// 299 is the only warning code the Kubernetes API server uses
+	if code != 299 || text == "" {
+		return
+	}
+
+	apiWarningsMu.Lock()
+	defer apiWarningsMu.Unlock()
+	for _, seen := range apiWarnings[c.cluster] {
+		if seen == text {
+			return
+		}
+	}
+	apiWarnings[c.cluster] = append(apiWarnings[c.cluster], text)
+}
+
+// APIWarnings returns the distinct API server warnings of each cluster and the cluster
+// names in sorted order
+func APIWarnings() (map[string][]string, []string) {
+	apiWarningsMu.Lock()
+	defer apiWarningsMu.Unlock()
+
+	warnings := make(map[string][]string, len(apiWarnings))
+	var names []string
+	for name, texts := range apiWarnings {
+		warnings[name] = append([]string(nil), texts...)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return warnings, names
+}
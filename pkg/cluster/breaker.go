@@ -0,0 +1,130 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	breakerThreshold     = 5
+	breakerProbeInterval = 30 * time.Second
+
+	breakersMu sync.Mutex
+	breakers   = make(map[string]*circuitBreaker)
+)
+
+// SetCircuitBreaker configures after how many consecutive failures a cluster is marked
+// degraded and how often a degraded cluster is probed for recovery. A threshold of
+// zero disables the breaker.
+func SetCircuitBreaker(threshold int, probeInterval time.Duration) {
+	breakerThreshold = threshold
+	breakerProbeInterval = probeInterval
+}
+
+// circuitBreaker tracks the consecutive failures of one API server. Once it is open,
+// requests fail immediately until the probe interval has passed; then a single request
+// is let through, and its outcome closes the breaker or keeps it open.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	host     string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// breakerFor returns the breaker of an API server, shared by all clients built for it
+// during the session
+func breakerFor(host string) *circuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[host]
+	if !ok {
+		b = &circuitBreaker{host: host}
+		breakers[host] = b
+	}
+	return b
+}
+
+// allow reports whether a request may be sent
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < breakerThreshold {
+		return nil
+	}
+	if b.probing {
+		return fmt.Errorf("%s is degraded after %d consecutive failures and being probed for recovery", b.host, b.failures)
+	}
+	if wait := breakerProbeInterval - time.Since(b.openedAt); wait > 0 {
+		return fmt.Errorf("%s is degraded after %d consecutive failures, next probe in %s",
+			b.host, b.failures, wait.Round(time.Second))
+	}
+	b.probing = true
+	klog.V(2).Infof("Probing degraded API server %s", b.host)
+	return nil
+}
+
+// record updates the breaker with the outcome of a request
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := b.failures >= breakerThreshold
+	b.probing = false
+
+	if !failed {
+		if wasOpen {
+			klog.V(1).Infof("API server %s recovered", b.host)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= breakerThreshold {
+		if !wasOpen {
+			warnf("%s failed %d times in a row, marking it degraded and probing every %s", b.host, b.failures, breakerProbeInterval)
+		}
+		b.openedAt = time.Now()
+	}
+}
+
+// endProbe lets the next request probe again without recording an outcome
+func (b *circuitBreaker) endProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// breakerRoundTripper fails requests to degraded API servers fast instead of waiting
+// for yet another timeout
+type breakerRoundTripper struct {
+	delegate http.RoundTripper
+	breaker  *circuitBreaker
+}
+
+func (rt *breakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := rt.breaker.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := rt.delegate.RoundTrip(req)
+	// A cancelled request says nothing about the health of the server
+	if req.Context().Err() != nil {
+		rt.breaker.endProbe()
+	} else {
+		rt.breaker.record(tripsBreaker(resp, err))
+	}
+	return resp, err
+}
+
+// tripsBreaker reports whether the outcome of a request counts as a failure of the API
+// server. Throttled requests do not: the server is up, and the retries back off.
+func tripsBreaker(resp *http.Response, err error) bool {
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		return false
+	}
+	return isRetryable(resp, err)
+}
@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/disk"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	discoveryCacheDir string
+	discoveryCacheTTL time.Duration
+)
+
+// illegalCacheDirChars matches the characters kubectl also replaces when it derives a
+// per-server cache directory, so both tools share ~/.kube/cache/discovery entries
+var illegalCacheDirChars = regexp.MustCompile(`[^(\w/.)]`)
+
+// SetDiscoveryCache configures the on-disk discovery cache used for every cluster
+// client built afterwards. An empty dir disables caching.
+func SetDiscoveryCache(dir string, ttl time.Duration) {
+	discoveryCacheDir = dir
+	discoveryCacheTTL = ttl
+}
+
+// newDiscoveryClient returns a discovery client for the given config, backed by the
+// on-disk cache when one is configured. The cache wraps its own HTTP transport, so
+// httpClient is only shared when caching is disabled.
+func newDiscoveryClient(restCfg *rest.Config, httpClient *http.Client) (discovery.DiscoveryInterface, error) {
+	if discoveryCacheDir == "" {
+		return discovery.NewDiscoveryClientForConfigAndClient(restCfg, httpClient)
+	}
+
+	discoveryDir := computeDiscoveryCacheDir(filepath.Join(discoveryCacheDir, "discovery"), restCfg.Host)
+	httpDir := filepath.Join(discoveryCacheDir, "http")
+	return disk.NewCachedDiscoveryClientForConfig(restCfg, discoveryDir, httpDir, discoveryCacheTTL)
+}
+
+// computeDiscoveryCacheDir returns the cache directory for one API server
+func computeDiscoveryCacheDir(parentDir, host string) string {
+	schemelessHost := strings.Replace(strings.Replace(host, "https://", "", 1), "http://", "", 1)
+	safeHost := illegalCacheDirChars.ReplaceAllString(schemelessHost, "_")
+	return filepath.Join(parentDir, safeHost)
+}
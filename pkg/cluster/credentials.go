@@ -0,0 +1,147 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+
+	"kubectl-multi/pkg/config"
+)
+
+// defaultExecAPIVersion is used for exec providers that do not name their version
+const defaultExecAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+var credentialOverrides map[string]config.Credentials
+
+// SetCredentialOverrides configures the credentials that replace the kubeconfig user
+// per cluster name
+func SetCredentialOverrides(overrides map[string]config.Credentials) {
+	credentialOverrides = overrides
+}
+
+// credentialOverride returns the configured credentials of a context. Managed
+// clusters are named after their context, the local cluster after its kubeconfig
+// cluster.
+func credentialOverride(ctxName, clusterName string) (config.Credentials, bool) {
+	creds, ok := credentialOverrides[ctxName]
+	if !ok {
+		creds, ok = credentialOverrides[clusterName]
+	}
+	return creds, ok
+}
+
+// overrideCredentials points the context at a user built from the configured
+// credentials of the cluster
+func overrideCredentials(rawCfg *clientcmdapi.Config, ctxName, clusterName string) bool {
+	creds, ok := credentialOverride(ctxName, clusterName)
+	kubeCtx := rawCfg.Contexts[ctxName]
+	if !ok || kubeCtx == nil {
+		return false
+	}
+
+	user := "kubectl-multi/" + ctxName
+	if rawCfg.AuthInfos == nil {
+		rawCfg.AuthInfos = make(map[string]*clientcmdapi.AuthInfo)
+	}
+	rawCfg.AuthInfos[user] = credentialsAuthInfo(creds)
+	overridden := *kubeCtx
+	overridden.AuthInfo = user
+	rawCfg.Contexts[ctxName] = &overridden
+
+	klog.V(3).Infof("Using credentials from the config file for context %s", ctxName)
+	return true
+}
+
+// KubectlCredentialKubeconfig writes the context with its credential override applied
+// to a temporary kubeconfig only readable by the current user, for commands run
+// through kubectl. Tokens are not passed as flags, where other users could read them
+// from the process list. Exec plugins are run through the shared provider, so that
+// kubectl does not run them again for every cluster; plugins that return a client
+// certificate cannot be passed on. An empty path is returned for contexts without an
+// override; the returned function removes the file.
+func KubectlCredentialKubeconfig(kubeconfig, ctxName string) (string, func(), error) {
+	noop := func() {}
+	loading := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loading.ExplicitPath = kubeconfig
+	}
+	rawCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loading, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	kubeCtx := rawCfg.Contexts[ctxName]
+	if kubeCtx == nil {
+		return "", noop, nil
+	}
+	creds, ok := credentialOverride(ctxName, kubeCtx.Cluster)
+	if !ok {
+		return "", noop, nil
+	}
+
+	overrideCredentials(&rawCfg, ctxName, kubeCtx.Cluster)
+	if creds.Exec != nil {
+		authInfo := rawCfg.AuthInfos[rawCfg.Contexts[ctxName].AuthInfo]
+		credentials, err := execProviderFor(&rest.Config{ExecProvider: authInfo.Exec}).get()
+		if err != nil {
+			return "", noop, err
+		}
+		if credentials.Status.Token == "" {
+			return "", noop, fmt.Errorf("credential plugin %s of %s returns a client certificate, which kubectl commands cannot use; configure the plugin in the kubeconfig instead", authInfo.Exec.Command, ctxName)
+		}
+		authInfo.Exec = nil
+		authInfo.Token = credentials.Status.Token
+	}
+	rawCfg.CurrentContext = ctxName
+	if err := clientcmdapi.MinifyConfig(&rawCfg); err != nil {
+		return "", noop, fmt.Errorf("failed to write the credentials of %s: %v", ctxName, err)
+	}
+
+	// CreateTemp creates the file with mode 0600, which WriteToFile keeps
+	file, err := os.CreateTemp("", "kubectl-multi-kubeconfig-")
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to write the credentials of %s: %v", ctxName, err)
+	}
+	file.Close()
+	cleanup := func() { os.Remove(file.Name()) }
+	if err := clientcmd.WriteToFile(rawCfg, file.Name()); err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to write the credentials of %s: %v", ctxName, err)
+	}
+	return file.Name(), cleanup, nil
+}
+
+// credentialsAuthInfo converts configured credentials to a kubeconfig user
+func credentialsAuthInfo(creds config.Credentials) *clientcmdapi.AuthInfo {
+	authInfo := clientcmdapi.NewAuthInfo()
+	authInfo.Token = creds.Token
+	authInfo.TokenFile = creds.TokenFile
+	authInfo.ClientCertificate = creds.ClientCertificate
+	authInfo.ClientKey = creds.ClientKey
+
+	if creds.Exec != nil {
+		exec := &clientcmdapi.ExecConfig{
+			APIVersion:      creds.Exec.APIVersion,
+			Command:         creds.Exec.Command,
+			Args:            creds.Exec.Args,
+			InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+		}
+		if exec.APIVersion == "" {
+			exec.APIVersion = defaultExecAPIVersion
+		}
+		var names []string
+		for name := range creds.Exec.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{Name: name, Value: creds.Exec.Env[name]})
+		}
+		authInfo.Exec = exec
+	}
+	return authInfo
+}
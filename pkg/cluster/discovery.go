@@ -3,63 +3,167 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sort"
 	"strings"
+	"sync"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
 )
 
-// ClusterInfo contains information about a discovered cluster
+// ClusterInfo contains information about a discovered cluster. Its clients are built
+// on first use and shared by all copies of the ClusterInfo.
 type ClusterInfo struct {
-	Name            string
-	Context         string
-	Client          *kubernetes.Clientset
-	DynamicClient   dynamic.Interface
-	DiscoveryClient discovery.DiscoveryInterface
-	RestConfig      *rest.Config
+	Name       string
+	Context    string
+	RestConfig *rest.Config
+	clients    *clusterClients
+}
+
+// clusterClients builds the clients of one cluster at most once
+type clusterClients struct {
+	once      sync.Once
+	name      string
+	restCfg   *rest.Config
+	client    *kubernetes.Clientset
+	dynamic   dynamic.Interface
+	discovery discovery.DiscoveryInterface
+}
+
+func (c *clusterClients) build() *clusterClients {
+	c.once.Do(func() {
+		cs, dyn, disc := newClusterClients(c.restCfg)
+		if cs == nil {
+			RecordFailure(c.name, "connect", fmt.Errorf("failed to create the clients"))
+			return
+		}
+		c.client, c.dynamic, c.discovery = cs, dyn, disc
+	})
+	return c
+}
+
+// newClusterInfo returns the ClusterInfo of a cluster whose clients are built on first use
+func newClusterInfo(name, context string, restCfg *rest.Config) ClusterInfo {
+	return ClusterInfo{
+		Name:       name,
+		Context:    context,
+		RestConfig: restCfg,
+		clients:    &clusterClients{name: name, restCfg: restCfg},
+	}
+}
+
+// NewClusterInfo returns the ClusterInfo of a cluster whose clients are already built
+func NewClusterInfo(name, context string, client *kubernetes.Clientset, dyn dynamic.Interface, disc discovery.DiscoveryInterface, restCfg *rest.Config) ClusterInfo {
+	clients := &clusterClients{name: name, restCfg: restCfg, client: client, dynamic: dyn, discovery: disc}
+	clients.once.Do(func() {})
+	return ClusterInfo{Name: name, Context: context, RestConfig: restCfg, clients: clients}
+}
+
+// Client returns the typed client of the cluster, or nil when it cannot be built
+func (c ClusterInfo) Client() *kubernetes.Clientset {
+	if c.clients == nil {
+		return nil
+	}
+	return c.clients.build().client
+}
+
+// DynamicClient returns the dynamic client of the cluster, or nil when it cannot be built
+func (c ClusterInfo) DynamicClient() dynamic.Interface {
+	if c.clients == nil {
+		return nil
+	}
+	return c.clients.build().dynamic
+}
+
+// DiscoveryClient returns the discovery client of the cluster, or nil when it cannot be built
+func (c ClusterInfo) DiscoveryClient() discovery.DiscoveryInterface {
+	if c.clients == nil {
+		return nil
+	}
+	return c.clients.build().discovery
+}
+
+// WithContext returns a copy of the ClusterInfo with new clients whose requests are
+// cancelled with ctx, for calls such as discovery that take no context
+func (c ClusterInfo) WithContext(ctx context.Context) ClusterInfo {
+	restCfg := rest.CopyConfig(c.RestConfig)
+	restCfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &contextRoundTripper{delegate: rt, ctx: ctx}
+	})
+	return newClusterInfo(c.Name, c.Context, restCfg)
+}
+
+// contextRoundTripper sends every request with its context
+type contextRoundTripper struct {
+	delegate http.RoundTripper
+	ctx      context.Context
+}
+
+func (rt *contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.delegate.RoundTrip(req.WithContext(rt.ctx))
 }
 
 // DiscoverClusters finds all clusters including the local cluster and managed clusters
 func DiscoverClusters(kubeconfig, remoteCtx string) ([]ClusterInfo, error) {
 	var clusters []ClusterInfo
+	unreachable := 0
 
 	// Add managed clusters first (excluding WDS clusters)
 	if remoteCtx != "" {
 		managedClusters, err := listManagedClusters(kubeconfig, remoteCtx)
 		if err != nil {
-			fmt.Printf("Warning: could not list managed clusters: %v\n", err)
+			warnf("could not list managed clusters: %v", err)
 		} else {
+			klog.V(1).Infof("Found %d managed cluster(s) in context %s: %s", len(managedClusters), remoteCtx, strings.Join(managedClusters, ", "))
 			for _, mcName := range managedClusters {
 				// Skip WDS clusters - they are for workflow staging, not workload execution
 				if isWDSCluster(mcName) {
+					klog.V(1).Infof("Skipping managed cluster %s: it is a WDS", mcName)
+					continue
+				}
+				if !selected(mcName) {
+					klog.V(1).Infof("Skipping managed cluster %s: not selected by --clusters", mcName)
 					continue
 				}
 
 				// Use the managed cluster name as the context, not remoteCtx
-				_, _, cs, dyn, disc, restCfg := buildClusterClient(kubeconfig, mcName)
-				if cs != nil { // Only add if we can connect
-					clusters = append(clusters, ClusterInfo{
-						Name:            mcName,
-						Context:         mcName, // Use mcName as context, not remoteCtx
-						Client:          cs,
-						DynamicClient:   dyn,
-						DiscoveryClient: disc,
-						RestConfig:      restCfg,
-					})
+				_, _, restCfg := loadClusterConfig(kubeconfig, mcName)
+				if restCfg == nil {
+					klog.V(1).Infof("Skipping managed cluster %s: no usable kubeconfig context with that name", mcName)
+					RecordFailure(mcName, "connect", fmt.Errorf("no usable kubeconfig context %q", mcName))
+					unreachable++
+					continue
 				}
+				clusters = append(clusters, newClusterInfo(mcName, mcName, restCfg))
 			}
 		}
 	}
 
+	// Add the clusters of the configured cluster providers
+	if len(clusterProviders) > 0 {
+		known := make(map[string]bool, len(clusters))
+		for _, clusterInfo := range clusters {
+			known[clusterInfo.Name] = true
+		}
+		provided, failed := providedClusters(kubeconfig, known)
+		clusters = append(clusters, provided...)
+		unreachable += failed
+	}
+
 	// Add local cluster (ITS cluster) - but check if it's not already included
-	localCtx, localCluster, localClient, localDynamic, localDiscovery, localRestConfig := buildClusterClient(kubeconfig, "")
-	if localClient != nil && !isWDSCluster(localCluster) {
+	localCtx, localCluster, localRestConfig := loadClusterConfig(kubeconfig, "")
+	if localRestConfig != nil && isWDSCluster(localCluster) {
+		klog.V(1).Infof("Skipping local cluster %s: it is a WDS", localCluster)
+	} else if localRestConfig != nil && !selected(localCluster) {
+		klog.V(1).Infof("Skipping local cluster %s: not selected by --clusters", localCluster)
+	} else if localRestConfig != nil {
 		// Check if this cluster is already in the list (avoid duplicates)
 		found := false
 		for _, cluster := range clusters {
@@ -68,21 +172,45 @@ func DiscoverClusters(kubeconfig, remoteCtx string) ([]ClusterInfo, error) {
 				break
 			}
 		}
-		if !found {
-			clusters = append(clusters, ClusterInfo{
-				Name:            localCluster,
-				Context:         localCtx,
-				Client:          localClient,
-				DynamicClient:   localDynamic,
-				DiscoveryClient: localDiscovery,
-				RestConfig:      localRestConfig,
-			})
+		if found {
+			klog.V(1).Infof("Local context %s points at managed cluster %s, not adding it twice", localCtx, localCluster)
+		} else {
+			localRestConfig.WarningHandler = apiWarningCollector{cluster: localCluster}
+			clusters = append(clusters, newClusterInfo(localCluster, localCtx, localRestConfig))
 		}
 	}
 
+	clusters = vetoClusters(clusters)
+	for _, clusterInfo := range clusters {
+		clusterNames.Store(clusterInfo.Context, clusterInfo.Name)
+	}
+	startProgress(len(clusters) + unreachable)
 	return clusters, nil
 }
 
+// clusterNames maps the kubeconfig contexts of the discovered clusters to their names
+var clusterNames sync.Map
+
+// ClusterName returns the name of the discovered cluster of a kubeconfig context, or
+// an empty string when no discovered cluster uses the context
+func ClusterName(kubeContext string) string {
+	name, _ := clusterNames.Load(kubeContext)
+	clusterName, _ := name.(string)
+	return clusterName
+}
+
+// SkipReason returns why discovery skips the managed cluster or local cluster of a
+// name, or an empty string when it is used
+func SkipReason(name string) string {
+	switch {
+	case isWDSCluster(name):
+		return "its name marks it as a WDS"
+	case !selected(name):
+		return "not selected by --clusters"
+	}
+	return ""
+}
+
 // isWDSCluster checks if a cluster name indicates it's a Workload Description Space cluster
 func isWDSCluster(clusterName string) bool {
 	// WDS clusters typically have names like "wds1", "wds2", etc.
@@ -91,8 +219,9 @@ func isWDSCluster(clusterName string) bool {
 	return strings.HasPrefix(lowerName, "wds") || strings.Contains(lowerName, "-wds-") || strings.Contains(lowerName, "_wds_")
 }
 
-// buildClusterClient creates all necessary clients for a cluster
-func buildClusterClient(kcfg, ctxOverride string) (string, string, *kubernetes.Clientset, dynamic.Interface, discovery.DiscoveryInterface, *rest.Config) {
+// loadClusterConfig resolves the context, cluster name and rest config of a kubeconfig
+// context without creating any clients
+func loadClusterConfig(kcfg, ctxOverride string) (string, string, *rest.Config) {
 	loading := clientcmd.NewDefaultClientConfigLoadingRules()
 	if kcfg != "" {
 		loading.ExplicitPath = kcfg
@@ -105,57 +234,111 @@ func buildClusterClient(kcfg, ctxOverride string) (string, string, *kubernetes.C
 	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loading, overrides)
 	rawCfg, err := cfg.RawConfig()
 	if err != nil {
-		fmt.Printf("Warning: failed to load kubeconfig: %v\n", err)
-		return "", "", nil, nil, nil, nil
+		warnf("failed to load kubeconfig: %v", err)
+		return "", "", nil
+	}
+
+	ctxName := rawCfg.CurrentContext
+	if ctxOverride != "" {
+		ctxName = ctxOverride
+	}
+	clusterName := "<unknown>"
+	if ctx, ok := rawCfg.Contexts[ctxName]; ok {
+		clusterName = ctx.Cluster
+	}
+
+	credentialsOverridden := overrideCredentials(&rawCfg, ctxName, clusterName)
+	tlsOverridden := overrideTLS(&rawCfg, ctxName, clusterName)
+	if credentialsOverridden || tlsOverridden {
+		cfg = clientcmd.NewNonInteractiveClientConfig(rawCfg, ctxName, overrides, loading)
 	}
 
 	restCfg, err := cfg.ClientConfig()
 	if err != nil {
-		fmt.Printf("Warning: failed to create rest config: %v\n", err)
-		return "", "", nil, nil, nil, nil
+		warnf("failed to create rest config: %v", err)
+		return "", "", nil
 	}
+	applyClientOptions(restCfg)
 
-	cs, err := kubernetes.NewForConfig(restCfg)
+	// Managed clusters are named after their context; DiscoverClusters renames the
+	// collector of the local cluster, which is listed under its cluster name
+	restCfg.WarningHandler = apiWarningCollector{cluster: ctxName}
+
+	klog.V(3).Infof("Using context %s (cluster %s) at %s", ctxName, clusterName, restCfg.Host)
+	return ctxName, clusterName, restCfg
+}
+
+// newClusterClients creates the typed, dynamic and discovery clients of a cluster on
+// top of a single shared HTTP client, so TLS setup and connections are reused
+func newClusterClients(restCfg *rest.Config) (*kubernetes.Clientset, dynamic.Interface, discovery.DiscoveryInterface) {
+	httpClient, err := rest.HTTPClientFor(restCfg)
 	if err != nil {
-		fmt.Printf("Warning: failed to create kubernetes client: %v\n", err)
-		return "", "", nil, nil, nil, nil
+		warnf("failed to create http client: %v", err)
+		return nil, nil, nil
 	}
 
-	dyn, err := dynamic.NewForConfig(restCfg)
+	// Built-in types are requested as protobuf, which is considerably smaller and faster
+	// to decode than JSON for large lists; dynamic and discovery clients stay on JSON
+	typedCfg := rest.CopyConfig(restCfg)
+	typedCfg.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+	typedCfg.ContentType = runtime.ContentTypeProtobuf
+
+	cs, err := kubernetes.NewForConfigAndClient(typedCfg, httpClient)
 	if err != nil {
-		fmt.Printf("Warning: failed to create dynamic client: %v\n", err)
-		return "", "", nil, nil, nil, nil
+		warnf("failed to create kubernetes client: %v", err)
+		return nil, nil, nil
 	}
 
-	disc, err := discovery.NewDiscoveryClientForConfig(restCfg)
+	dyn, err := dynamic.NewForConfigAndClient(restCfg, httpClient)
 	if err != nil {
-		fmt.Printf("Warning: failed to create discovery client: %v\n", err)
-		return "", "", nil, nil, nil, nil
+		warnf("failed to create dynamic client: %v", err)
+		return nil, nil, nil
 	}
 
-	ctxName := rawCfg.CurrentContext
-	clusterName := "<unknown>"
-	if ctx, ok := rawCfg.Contexts[ctxName]; ok {
-		clusterName = ctx.Cluster
+	disc, err := newDiscoveryClient(restCfg, httpClient)
+	if err != nil {
+		warnf("failed to create discovery client: %v", err)
+		return nil, nil, nil
 	}
 
-	return ctxName, clusterName, cs, dyn, disc, restCfg
+	return cs, dyn, disc
 }
 
-// listManagedClusters discovers KubeStellar managed clusters
-func listManagedClusters(kubeconfig, remoteCtx string) ([]string, error) {
-	_, _, _, dyn, _, _ := buildClusterClient(kubeconfig, remoteCtx)
-	if dyn == nil {
-		return nil, fmt.Errorf("failed to create dynamic client for remote context %s", remoteCtx)
+// ContextClient returns the clients of any kubeconfig context, such as the ITS or a
+// WDS, that DiscoverClusters does not return; an empty context is the current one
+func ContextClient(kubeconfig, context string) (ClusterInfo, error) {
+	ctxName, _, restCfg := loadClusterConfig(kubeconfig, context)
+	if restCfg == nil {
+		return ClusterInfo{}, fmt.Errorf("no usable kubeconfig context %q", context)
+	}
+	info := newClusterInfo(ctxName, ctxName, restCfg)
+	if info.Client() == nil {
+		return ClusterInfo{}, fmt.Errorf("failed to create the clients of context %q", ctxName)
+	}
+	return info, nil
+}
+
+// GetRemoteDynamicClient returns a dynamic client for the remote hosting (ITS) context
+func GetRemoteDynamicClient(kubeconfig, remoteCtx string) (dynamic.Interface, error) {
+	_, _, restCfg := loadClusterConfig(kubeconfig, remoteCtx)
+	if restCfg == nil {
+		return nil, fmt.Errorf("failed to load config for remote context %s", remoteCtx)
 	}
+	dyn, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client for remote context %s: %v", remoteCtx, err)
+	}
+	return dyn, nil
+}
 
-	gvr := schema.GroupVersionResource{
-		Group:    "cluster.open-cluster-management.io",
-		Version:  "v1",
-		Resource: "managedclusters",
+// listManagedClusters discovers KubeStellar managed clusters
+func listManagedClusters(kubeconfig, remoteCtx string) ([]string, error) {
+	dyn, err := GetRemoteDynamicClient(kubeconfig, remoteCtx)
+	if err != nil {
+		return nil, err
 	}
 
-	mcs, err := dyn.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+	mcs, err := dyn.Resource(ManagedClusterGVR).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list managed clusters: %v", err)
 	}
@@ -166,6 +349,8 @@ func listManagedClusters(kubeconfig, remoteCtx string) ([]string, error) {
 		// Filter out WDS clusters at the discovery level too
 		if !isWDSCluster(clusterName) {
 			clusters = append(clusters, clusterName)
+		} else {
+			klog.V(1).Infof("Skipping managed cluster %s: it is a WDS", clusterName)
 		}
 	}
 	sort.Strings(clusters)
@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// Error classes reported for cluster failures
+const (
+	ErrorClassAuth         = "auth"
+	ErrorClassForbidden    = "forbidden"
+	ErrorClassNotFound     = "not-found"
+	ErrorClassThrottled    = "throttled"
+	ErrorClassTimeout      = "timeout"
+	ErrorClassConnectivity = "connectivity"
+	ErrorClassServer       = "server"
+	ErrorClassOther        = "other"
+)
+
+// ClassifyError returns the class of a cluster failure and whether retrying the
+// operation later may succeed
+func ClassifyError(err error) (string, bool) {
+	var execErr *ExecCredentialError
+	switch {
+	case apierrors.IsUnauthorized(err), errors.As(err, &execErr):
+		return ErrorClassAuth, false
+	case apierrors.IsForbidden(err):
+		return ErrorClassForbidden, false
+	case apierrors.IsNotFound(err):
+		return ErrorClassNotFound, false
+	case apierrors.IsTooManyRequests(err):
+		return ErrorClassThrottled, true
+	case errors.Is(err, context.DeadlineExceeded), apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), utilnet.IsTimeout(err):
+		return ErrorClassTimeout, true
+	case utilnet.IsConnectionRefused(err), utilnet.IsConnectionReset(err), utilnet.IsProbableEOF(err), utilnet.IsHTTP2ConnectionLost(err):
+		return ErrorClassConnectivity, true
+	case apierrors.IsInternalError(err), apierrors.IsServiceUnavailable(err), apierrors.IsUnexpectedServerError(err):
+		return ErrorClassServer, true
+	}
+	return ErrorClassOther, false
+}
@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is the kind of a progress event
+type EventType string
+
+const (
+	// ClusterStarted is emitted when a command first queries a cluster
+	ClusterStarted EventType = "ClusterStarted"
+	// ClusterSucceeded is emitted when a cluster answered without a failure
+	ClusterSucceeded EventType = "ClusterSucceeded"
+	// ClusterFailed is emitted on the first failure of a cluster
+	ClusterFailed EventType = "ClusterFailed"
+	// RowEmitted is emitted for every row or object a command prints
+	RowEmitted EventType = "RowEmitted"
+)
+
+// Event is a structured progress event of the running command
+type Event struct {
+	Type      EventType         `json:"type"`
+	Time      time.Time         `json:"time"`
+	Cluster   string            `json:"cluster"`
+	Operation string            `json:"operation,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Row       map[string]string `json:"row,omitempty"`
+}
+
+var (
+	eventsMu       sync.RWMutex
+	eventSinks     = make(map[int]func(Event))
+	nextEventSink  int
+	finishedEvents = make(map[string]bool)
+)
+
+// Subscribe calls sink for every event until the returned function is called. Sinks
+// run synchronously on the goroutine of the cluster operation, so they must be fast.
+func Subscribe(sink func(Event)) func() {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	id := nextEventSink
+	nextEventSink++
+	eventSinks[id] = sink
+	return func() {
+		eventsMu.Lock()
+		defer eventsMu.Unlock()
+		delete(eventSinks, id)
+	}
+}
+
+// EmitRow emits a RowEmitted event for a row printed for a cluster. Objects are
+// described by their kind, namespace and name, table rows by their printed line.
+func EmitRow(clusterName string, row map[string]string) {
+	emit(Event{Type: RowEmitted, Cluster: clusterName, Row: row})
+}
+
+// finishCluster emits ClusterSucceeded once for a cluster that answered without failing
+func finishCluster(name string) {
+	resultsMu.Lock()
+	_, failed := clusterFailures[name]
+	done := failed || skippedClusters[name] || !attemptedClusters[name]
+	resultsMu.Unlock()
+	if done {
+		return
+	}
+
+	eventsMu.Lock()
+	if finishedEvents[name] {
+		eventsMu.Unlock()
+		return
+	}
+	finishedEvents[name] = true
+	eventsMu.Unlock()
+	emit(Event{Type: ClusterSucceeded, Cluster: name})
+}
+
+func emit(event Event) {
+	eventsMu.RLock()
+	sinks := make([]func(Event), 0, len(eventSinks))
+	for _, sink := range eventSinks {
+		sinks = append(sinks, sink)
+	}
+	eventsMu.RUnlock()
+	if len(sinks) == 0 {
+		return
+	}
+
+	event.Time = time.Now()
+	for _, sink := range sinks {
+		sink(event)
+	}
+}
@@ -0,0 +1,347 @@
+package cluster
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/transport"
+	"k8s.io/klog/v2"
+)
+
+// execExpiryMargin refreshes exec credentials shortly before they expire
+const execExpiryMargin = 30 * time.Second
+
+// execRetryBackoff is how long a failed plugin is not run again, so the clusters of one
+// command fail fast while a long-running command such as serve recovers once the user
+// has logged in
+const execRetryBackoff = 30 * time.Second
+
+// ExecCredentialError is returned for the clusters whose exec credential plugin failed
+type ExecCredentialError struct {
+	Command string
+	Err     error
+}
+
+func (e *ExecCredentialError) Error() string {
+	return fmt.Sprintf("credential plugin %s failed: %v (if it needs an interactive login, run it once in a terminal and retry)", e.Command, e.Err)
+}
+
+func (e *ExecCredentialError) Unwrap() error {
+	return e.Err
+}
+
+// execCredential is the part of the client.authentication.k8s.io ExecCredential
+// printed by a plugin that is used here
+type execCredential struct {
+	Status *struct {
+		Token                 string       `json:"token,omitempty"`
+		ClientCertificateData string       `json:"clientCertificateData,omitempty"`
+		ClientKeyData         string       `json:"clientKeyData,omitempty"`
+		ExpirationTimestamp   *metav1.Time `json:"expirationTimestamp,omitempty"`
+	} `json:"status,omitempty"`
+}
+
+// execProvider runs one exec credential plugin configuration at most once at a time and
+// shares its credentials between all clusters using the same configuration, which is
+// the usual case for clusters behind the same OIDC issuer or cloud account
+type execProvider struct {
+	config  *clientcmdapi.ExecConfig
+	cluster *clientcmdapi.Cluster
+
+	mu          sync.Mutex
+	credentials *execCredential
+	expiry      time.Time
+	err         error
+	failedAt    time.Time
+}
+
+var (
+	execProvidersMu sync.Mutex
+	execProviders   = make(map[string]*execProvider)
+	// execPrompted is set once a plugin was allowed to prompt, so a fleet of clusters
+	// with different plugins does not open one login after another
+	execPrompted bool
+)
+
+// execProviderFor returns the shared provider of an exec configuration. Plugins that
+// receive the cluster info are shared per API server only.
+func execProviderFor(restCfg *rest.Config) *execProvider {
+	key, _ := json.Marshal(struct {
+		APIVersion string
+		Command    string
+		Args       []string
+		Env        []clientcmdapi.ExecEnvVar
+	}{restCfg.ExecProvider.APIVersion, restCfg.ExecProvider.Command, restCfg.ExecProvider.Args, restCfg.ExecProvider.Env})
+	var cluster *clientcmdapi.Cluster
+	if restCfg.ExecProvider.ProvideClusterInfo {
+		cluster = &clientcmdapi.Cluster{
+			Server:                   restCfg.Host,
+			TLSServerName:            restCfg.ServerName,
+			InsecureSkipTLSVerify:    restCfg.Insecure,
+			CertificateAuthorityData: restCfg.CAData,
+		}
+		key = append(key, restCfg.Host...)
+	}
+
+	execProvidersMu.Lock()
+	defer execProvidersMu.Unlock()
+	provider, ok := execProviders[string(key)]
+	if !ok {
+		provider = &execProvider{config: restCfg.ExecProvider, cluster: cluster}
+		execProviders[string(key)] = provider
+	}
+	return provider
+}
+
+// get returns valid credentials, running the plugin when there are none yet or they
+// are about to expire. A failed plugin is not run again until execRetryBackoff has
+// passed.
+func (p *execProvider) get() (*execCredential, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil && time.Since(p.failedAt) < execRetryBackoff {
+		return nil, p.err
+	}
+	if p.credentials != nil && (p.expiry.IsZero() || time.Until(p.expiry) > execExpiryMargin) {
+		return p.credentials, nil
+	}
+
+	credentials, err := p.run()
+	if err != nil {
+		p.err = &ExecCredentialError{Command: p.config.Command, Err: err}
+		p.failedAt = time.Now()
+		return nil, p.err
+	}
+	p.err = nil
+	p.credentials = credentials
+	p.expiry = time.Time{}
+	if credentials.Status.ExpirationTimestamp != nil {
+		p.expiry = credentials.Status.ExpirationTimestamp.Time
+	} else if certificate, err := parseCertificate(credentials); err == nil && certificate.Leaf != nil {
+		// Plugins need not repeat the expiry of a certificate
+		p.expiry = certificate.Leaf.NotAfter
+	}
+	return credentials, nil
+}
+
+// invalidate drops credentials the API server rejected, so the next get runs the
+// plugin again. Credentials that were already replaced are kept, so the clusters
+// rejecting the same credentials at once run the plugin only once.
+func (p *execProvider) invalidate(rejected *execCredential) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.credentials == rejected {
+		p.credentials = nil
+	}
+}
+
+// certificate returns the client certificate of the provider for a TLS handshake
+func (p *execProvider) certificate() (*tls.Certificate, error) {
+	credentials, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	return parseCertificate(credentials)
+}
+
+// parseCertificate returns the client certificate and key of exec credentials
+func parseCertificate(credentials *execCredential) (*tls.Certificate, error) {
+	certificate, err := tls.X509KeyPair([]byte(credentials.Status.ClientCertificateData), []byte(credentials.Status.ClientKeyData))
+	if err != nil {
+		return nil, err
+	}
+	if certificate.Leaf == nil && len(certificate.Certificate) > 0 {
+		certificate.Leaf, _ = x509.ParseCertificate(certificate.Certificate[0])
+	}
+	return &certificate, nil
+}
+
+// run executes the plugin like client-go does. Only the first plugin that runs while
+// stdin is a terminal may interact with the user.
+func (p *execProvider) run() (*execCredential, error) {
+	execProvidersMu.Lock()
+	interactive := !execPrompted && p.config.InteractiveMode != clientcmdapi.NeverExecInteractiveMode && term.IsTerminal(int(os.Stdin.Fd()))
+	if interactive {
+		execPrompted = true
+	}
+	execProvidersMu.Unlock()
+	if !interactive && p.config.InteractiveMode == clientcmdapi.AlwaysExecInteractiveMode {
+		return nil, fmt.Errorf("the plugin requires an interactive login, which is only offered for one plugin per command")
+	}
+
+	info := map[string]interface{}{
+		"apiVersion": p.config.APIVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": interactive},
+	}
+	if p.cluster != nil {
+		info["spec"].(map[string]interface{})["cluster"] = map[string]interface{}{
+			"server":                     p.cluster.Server,
+			"tls-server-name":            p.cluster.TLSServerName,
+			"insecure-skip-tls-verify":   p.cluster.InsecureSkipTLSVerify,
+			"certificate-authority-data": p.cluster.CertificateAuthorityData,
+		}
+	}
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(p.config.Command, p.config.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(infoJSON))
+	for _, env := range p.config.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if interactive {
+		cmd.Stdin = os.Stdin
+	}
+
+	klog.V(2).Infof("Running credential plugin %s (interactive: %v)", p.config.Command, interactive)
+	ClearProgress()
+	if err := cmd.Run(); err != nil {
+		if p.config.InstallHint != "" && errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%v\n%s", err, p.config.InstallHint)
+		}
+		return nil, err
+	}
+
+	credentials := &execCredential{}
+	if err := json.Unmarshal(stdout.Bytes(), credentials); err != nil {
+		return nil, fmt.Errorf("failed to decode plugin output: %v", err)
+	}
+	if credentials.Status == nil || (credentials.Status.Token == "" && credentials.Status.ClientCertificateData == "") {
+		return nil, fmt.Errorf("plugin returned neither a token nor a client certificate")
+	}
+	return credentials, nil
+}
+
+// applyExecCredentials replaces client-go's own exec handling, which runs the plugin
+// once per cluster and lets every cluster prompt for a login, with a shared provider.
+// Tokens are added by the round tripper and client certificates are presented at
+// every TLS handshake, so both are refreshed when they expire.
+func applyExecCredentials(restCfg *rest.Config) {
+	if restCfg.ExecProvider == nil {
+		return
+	}
+	provider := execProviderFor(restCfg)
+	restCfg.ExecProvider = nil
+
+	var certTransport *http.Transport
+	if credentials, err := provider.get(); err == nil && credentials.Status.ClientCertificateData != "" {
+		if certTransport, err = certificateTransport(restCfg, provider); err != nil {
+			klog.V(1).Infof("Using the client certificate of %s until it expires: %v", provider.config.Command, err)
+			restCfg.CertData = []byte(credentials.Status.ClientCertificateData)
+			restCfg.KeyData = []byte(credentials.Status.ClientKeyData)
+			return
+		}
+		// The TLS settings moved into the transport, which client-go refuses to combine
+		restCfg.Transport = certTransport
+		restCfg.TLSClientConfig = rest.TLSClientConfig{}
+	}
+	restCfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &execRoundTripper{delegate: rt, provider: provider, certTransport: certTransport}
+	})
+}
+
+// certificateTransport returns a transport with the TLS settings of a rest config that
+// asks the provider for the client certificate at every handshake, like client-go does
+// for exec plugins
+func certificateTransport(restCfg *rest.Config, provider *execProvider) (*http.Transport, error) {
+	transportCfg, err := restCfg.TransportConfig()
+	if err != nil {
+		return nil, err
+	}
+	transportCfg.TLS.GetCertHolder = &transport.GetCertHolder{GetCert: provider.certificate}
+	tlsConfig, err := transport.TLSConfigFor(transportCfg)
+	if err != nil {
+		return nil, err
+	}
+	dial := restCfg.Dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	proxy := restCfg.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	return utilnet.SetTransportDefaults(&http.Transport{
+		Proxy:               proxy,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConnsPerHost: 25,
+		DialContext:         dial,
+		DisableCompression:  restCfg.DisableCompression,
+	}), nil
+}
+
+// execRoundTripper adds the token of a shared exec provider to every request. Requests
+// the API server rejects as unauthorized are sent once more with new credentials, as
+// credentials can be revoked before they expire.
+type execRoundTripper struct {
+	delegate http.RoundTripper
+	provider *execProvider
+	// certTransport presents the client certificates, its connections are closed when
+	// they are rejected so new ones present the new certificate
+	certTransport *http.Transport
+}
+
+func (rt *execRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	credentials, err := rt.provider.get()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rt.send(req, credentials)
+	// Only requests whose body can be read again are replayed
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || (req.Body != nil && req.GetBody == nil) {
+		return resp, err
+	}
+
+	rt.provider.invalidate(credentials)
+	refreshed, refreshErr := rt.provider.get()
+	if refreshErr != nil || refreshed == credentials {
+		return resp, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if rt.certTransport != nil {
+		rt.certTransport.CloseIdleConnections()
+	}
+	klog.V(2).Infof("Retrying %s %s with refreshed credentials of %s", req.Method, req.URL.Host, rt.provider.config.Command)
+	return rt.send(req, refreshed)
+}
+
+// send sends a request with the token of credentials, if they have one
+func (rt *execRoundTripper) send(req *http.Request, credentials *execCredential) (*http.Response, error) {
+	req = utilnet.CloneRequest(req)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	if credentials.Status.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+credentials.Status.Token)
+	}
+	return rt.delegate.RoundTrip(req)
+}
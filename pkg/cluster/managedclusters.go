@@ -0,0 +1,71 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ManagedClusterGVR is the resource of the clusters registered with the ITS
+var ManagedClusterGVR = schema.GroupVersionResource{
+	Group:    "cluster.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "managedclusters",
+}
+
+// ManagedCluster is the metadata of a cluster registered with the ITS
+type ManagedCluster struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+	// Available is the status of the ManagedClusterConditionAvailable condition
+	Available string
+	Created   time.Time
+}
+
+// ListManagedClusters returns the clusters registered with the ITS that are selected
+// by --clusters, without WDSes, sorted by name
+func ListManagedClusters(kubeconfig, remoteCtx string) ([]ManagedCluster, error) {
+	dyn, err := GetRemoteDynamicClient(kubeconfig, remoteCtx)
+	if err != nil {
+		return nil, err
+	}
+	mcs, err := dyn.Resource(ManagedClusterGVR).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed clusters: %v", err)
+	}
+
+	var clusters []ManagedCluster
+	for _, mc := range mcs.Items {
+		if isWDSCluster(mc.GetName()) || !selected(mc.GetName()) {
+			continue
+		}
+		clusters = append(clusters, ManagedCluster{
+			Name:        mc.GetName(),
+			Labels:      mc.GetLabels(),
+			Annotations: mc.GetAnnotations(),
+			Available:   managedClusterAvailable(mc),
+			Created:     mc.GetCreationTimestamp().Time,
+		})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	return clusters, nil
+}
+
+func managedClusterAvailable(mc unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(mc.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "ManagedClusterConditionAvailable" {
+			if status, ok := condition["status"].(string); ok {
+				return status
+			}
+		}
+	}
+	return "Unknown"
+}
@@ -0,0 +1,166 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+var (
+	clientQPS      float32
+	clientBurst    int
+	requestTimeout time.Duration
+	clusterTimeout time.Duration
+	clusterFilter  map[string]bool
+
+	namespaceDefaults map[string]string
+	clusterVeto       func(ClusterInfo) error
+)
+
+// SetClientOptions configures the rate limits and request timeout applied to the rest
+// config of every cluster built afterwards. Zero values keep the client-go defaults.
+func SetClientOptions(qps float32, burst int, timeout time.Duration) {
+	clientQPS = qps
+	clientBurst = burst
+	requestTimeout = timeout
+}
+
+// SetClusterTimeout bounds the total time the API calls of one command may take per
+// cluster. Zero disables the limit.
+func SetClusterTimeout(timeout time.Duration) {
+	clusterTimeout = timeout
+}
+
+// SetClusterFilter limits discovery to the named clusters. An empty list selects all
+// clusters.
+func SetClusterFilter(names []string) {
+	clusterFilter = nil
+	if len(names) == 0 {
+		return
+	}
+	clusterFilter = make(map[string]bool, len(names))
+	for _, name := range names {
+		clusterFilter[name] = true
+	}
+}
+
+// SetClusterVeto sets a function that is asked for every discovered cluster before a
+// command uses it. Clusters it returns an error for are left out.
+func SetClusterVeto(veto func(ClusterInfo) error) {
+	clusterVeto = veto
+}
+
+// SetNamespaceDefaults configures the namespace used per cluster name when no
+// namespace is given on the command line
+func SetNamespaceDefaults(defaults map[string]string) {
+	namespaceDefaults = defaults
+}
+
+// DefaultNamespace returns the configured default namespace of the cluster, or an
+// empty string when it has none
+func (c ClusterInfo) DefaultNamespace() string {
+	return namespaceDefaults[c.Name]
+}
+
+// TargetNamespace determines the target namespace for operations on this cluster,
+// preferring the cluster's configured default namespace over "default"
+func (c ClusterInfo) TargetNamespace(namespace string) string {
+	if namespace == "" && c.DefaultNamespace() != "" {
+		return c.DefaultNamespace()
+	}
+	return GetTargetNamespace(namespace)
+}
+
+// selected reports whether a cluster passes the cluster filter
+func selected(name string) bool {
+	return clusterFilter == nil || clusterFilter[name]
+}
+
+// vetoClusters drops the clusters refused by the cluster veto
+func vetoClusters(clusters []ClusterInfo) []ClusterInfo {
+	if clusterVeto == nil {
+		return clusters
+	}
+	var allowed []ClusterInfo
+	for _, clusterInfo := range clusters {
+		if err := clusterVeto(clusterInfo); err != nil {
+			warnf("skipping cluster %s: %v", clusterInfo.Name, err)
+			continue
+		}
+		allowed = append(allowed, clusterInfo)
+	}
+	return allowed
+}
+
+// applyClientOptions applies the configured client options to a rest config
+func applyClientOptions(restCfg *rest.Config) {
+	if clientQPS > 0 {
+		restCfg.QPS = clientQPS
+	}
+	if clientBurst > 0 {
+		restCfg.Burst = clientBurst
+	}
+	if requestTimeout > 0 {
+		restCfg.Timeout = requestTimeout
+	}
+	if maxRetries > 0 {
+		restCfg.Wrap(newRetryRoundTripper)
+	}
+	// Wrapped after the retries, so a request that failed all its retries counts once
+	if breakerThreshold > 0 {
+		breaker := breakerFor(restCfg.Host)
+		restCfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &breakerRoundTripper{delegate: rt, breaker: breaker}
+		})
+	}
+	// Wrapped last, so a failed plugin neither trips the breaker nor is retried
+	applyExecCredentials(restCfg)
+}
+
+// RequestContext returns the context for the API calls made to this cluster, bounded by the
+// cluster timeout and cancelled when --fail-fast aborts the command. Calling the
+// returned cancel function records the cluster as timed out when the deadline was hit.
+func (c ClusterInfo) RequestContext() (context.Context, context.CancelFunc) {
+	recordAttempt(c.Name)
+	updateProgress()
+	start := time.Now()
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if clusterTimeout > 0 {
+		ctx, cancel = context.WithTimeout(abortCtx, clusterTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(abortCtx)
+	}
+
+	return ctx, func() {
+		ClearProgress()
+		klog.V(2).Infof("Cluster %s answered in %s", c.Name, time.Since(start).Round(time.Millisecond))
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			RecordFailure(c.Name, "answer", fmt.Errorf("timed out after %s: %w", clusterTimeout, context.DeadlineExceeded))
+		}
+		cancel()
+		finishCluster(c.Name)
+	}
+}
+
+// StartOperation records an operation on this cluster that does not go through
+// RequestContext, such as a kubectl command, like RequestContext does. It returns the
+// function to call once the operation and any failure were recorded, and false when
+// --fail-fast aborted the command and the cluster is skipped instead.
+func (c ClusterInfo) StartOperation() (func(), bool) {
+	recordAttempt(c.Name)
+	updateProgress()
+	if Aborted() {
+		return func() {}, false
+	}
+	return func() {
+		ClearProgress()
+		finishCluster(c.Name)
+	}, true
+}
@@ -0,0 +1,19 @@
+package cluster
+
+import "fmt"
+
+// quiet suppresses the warnings printed while clusters are discovered
+var quiet bool
+
+// SetQuiet enables or disables discovery warnings
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// warnf prints a warning unless quiet mode is enabled
+func warnf(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Printf("Warning: "+format+"\n", args...)
+}
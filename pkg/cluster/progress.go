@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// progressDelay keeps fast commands free of progress output
+const progressDelay = time.Second
+
+var (
+	progressEnabled bool
+	progressMu      sync.Mutex
+	progressTotal   int
+	progressStart   time.Time
+	progressShown   bool
+)
+
+// SetProgress enables the progress line on stderr. The caller decides whether the
+// terminal supports it.
+func SetProgress(enabled bool) {
+	progressEnabled = enabled
+}
+
+// startProgress sets the number of clusters the running command is going to query
+func startProgress(total int) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	progressTotal = total
+	progressStart = time.Now()
+}
+
+// updateProgress redraws the progress line. The cursor is moved back to the start of
+// the line, so output written meanwhile overwrites the line instead of trailing it.
+func updateProgress() {
+	if !progressEnabled {
+		return
+	}
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	if progressTotal == 0 || time.Since(progressStart) < progressDelay {
+		return
+	}
+
+	resultsMu.Lock()
+	queried := len(attemptedClusters)
+	failed := len(clusterFailures)
+	resultsMu.Unlock()
+
+	fmt.Fprintf(os.Stderr, "\r\033[Kqueried %d/%d clusters, %d errors\r", queried, progressTotal, failed)
+	progressShown = true
+}
+
+// ClearProgress removes the progress line so regular output starts on a clean line
+func ClearProgress() {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	if progressShown {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		progressShown = false
+	}
+}
@@ -0,0 +1,106 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"kubectl-multi/pkg/config"
+)
+
+// providerTimeout bounds how long a cluster provider may take to print its clusters
+const providerTimeout = 30 * time.Second
+
+var clusterProviders []config.ClusterProvider
+
+// SetClusterProviders configures the executables that add clusters to discovery
+func SetClusterProviders(providers []config.ClusterProvider) {
+	clusterProviders = providers
+}
+
+// ProvidedCluster is one cluster printed by a cluster provider. Context names the
+// kubeconfig context to connect with; Kubeconfig is only needed when the context is
+// not in the kubeconfig kubectl multi uses. Name defaults to the context.
+type ProvidedCluster struct {
+	Name       string `json:"name,omitempty"`
+	Context    string `json:"context"`
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+}
+
+// providerOutput is what a cluster provider prints on stdout
+type providerOutput struct {
+	Clusters []ProvidedCluster `json:"clusters"`
+}
+
+// runClusterProvider runs a provider and returns the clusters it printed
+func runClusterProvider(provider config.ClusterProvider) ([]ProvidedCluster, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, provider.Command, provider.Args...)
+	cmd.Env = os.Environ()
+	for name, value := range provider.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var output providerOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("failed to decode provider output: %v", err)
+	}
+	for i, provided := range output.Clusters {
+		if provided.Context == "" {
+			return nil, fmt.Errorf("cluster %d of the provider output has no context", i)
+		}
+		if provided.Name == "" {
+			output.Clusters[i].Name = provided.Context
+		}
+	}
+	return output.Clusters, nil
+}
+
+// providedClusters returns the clusters of all configured providers that
+// are not already known. Failed providers and clusters are warned about and skipped.
+func providedClusters(kubeconfig string, known map[string]bool) ([]ClusterInfo, int) {
+	var clusters []ClusterInfo
+	unreachable := 0
+	for _, provider := range clusterProviders {
+		provided, err := runClusterProvider(provider)
+		if err != nil {
+			warnf("cluster provider %s failed: %v", provider.Name, err)
+			continue
+		}
+		klog.V(1).Infof("Cluster provider %s returned %d cluster(s)", provider.Name, len(provided))
+
+		for _, pc := range provided {
+			if known[pc.Name] || !selected(pc.Name) {
+				continue
+			}
+			known[pc.Name] = true
+
+			kcfg := kubeconfig
+			if pc.Kubeconfig != "" {
+				kcfg = pc.Kubeconfig
+			}
+			_, _, restCfg := loadClusterConfig(kcfg, pc.Context)
+			if restCfg == nil {
+				RecordFailure(pc.Name, "connect", fmt.Errorf("no usable kubeconfig context %q", pc.Context))
+				unreachable++
+				continue
+			}
+			clusters = append(clusters, newClusterInfo(pc.Name, pc.Context, restCfg))
+		}
+	}
+	return clusters, unreachable
+}
@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// ClusterFailure is the first error a command hit for one cluster
+type ClusterFailure struct {
+	Cluster   string
+	Operation string
+	Err       error
+}
+
+var (
+	resultsMu         sync.Mutex
+	attemptedClusters = make(map[string]bool)
+	clusterFailures   = make(map[string]ClusterFailure)
+	skippedClusters   = make(map[string]bool)
+
+	// abortCtx is the parent of every cluster context; it is cancelled on the first
+	// failure when fail-fast is enabled
+	failFast        bool
+	abortCtx, abort = context.WithCancel(context.Background())
+)
+
+// SetFailFast makes the first cluster failure abort all remaining cluster operations
+func SetFailFast(enabled bool) {
+	failFast = enabled
+}
+
+// Aborted reports whether a failure aborted the remaining cluster operations
+func Aborted() bool {
+	return abortCtx.Err() != nil
+}
+
+// recordAttempt marks a cluster as queried by the running command, or as skipped
+// once the command was aborted
+func recordAttempt(name string) {
+	resultsMu.Lock()
+	if Aborted() {
+		if _, failed := clusterFailures[name]; !failed && !attemptedClusters[name] {
+			skippedClusters[name] = true
+		}
+		resultsMu.Unlock()
+		return
+	}
+	started := !attemptedClusters[name]
+	attemptedClusters[name] = true
+	resultsMu.Unlock()
+
+	if started {
+		emit(Event{Type: ClusterStarted, Cluster: name})
+	}
+}
+
+// RecordFailure records that an operation failed for a cluster. Only the first failure
+// of each cluster is kept, since later ones are usually a consequence of it. Failures
+// after an abort are the cancellation itself and mark the cluster as skipped instead.
+func RecordFailure(name, operation string, err error) {
+	recordFailure(name, operation, err)
+	updateProgress()
+}
+
+func recordFailure(name, operation string, err error) {
+	resultsMu.Lock()
+	if Aborted() {
+		if _, failed := clusterFailures[name]; !failed {
+			skippedClusters[name] = true
+		}
+		resultsMu.Unlock()
+		return
+	}
+
+	attemptedClusters[name] = true
+	_, known := clusterFailures[name]
+	if !known {
+		clusterFailures[name] = ClusterFailure{Cluster: name, Operation: operation, Err: err}
+	}
+	if failFast {
+		abort()
+	}
+	resultsMu.Unlock()
+
+	if !known {
+		emit(Event{Type: ClusterFailed, Cluster: name, Operation: operation, Error: err.Error()})
+	}
+}
+
+// Results returns the clusters the running command queried successfully, the
+// failures of others and the clusters skipped after an abort, all sorted by name
+func Results() ([]string, []ClusterFailure, []string) {
+	resultsMu.Lock()
+	defer resultsMu.Unlock()
+
+	var succeeded, skipped []string
+	var failed []ClusterFailure
+	for name := range attemptedClusters {
+		if failure, ok := clusterFailures[name]; ok {
+			failed = append(failed, failure)
+		} else if skippedClusters[name] {
+			skipped = append(skipped, name)
+		} else {
+			succeeded = append(succeeded, name)
+		}
+	}
+	for name := range skippedClusters {
+		if !attemptedClusters[name] {
+			skipped = append(skipped, name)
+		}
+	}
+	sort.Strings(succeeded)
+	sort.Strings(skipped)
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Cluster < failed[j].Cluster })
+	return succeeded, failed, skipped
+}
@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/klog/v2"
+)
+
+const (
+	retryInitialBackoff = 250 * time.Millisecond
+	retryMaxBackoff     = 5 * time.Second
+)
+
+// maxRetries is how often a failed read request to a cluster is retried
+var maxRetries = 3
+
+// SetRetries configures how often transient failures are retried per request
+func SetRetries(retries int) {
+	maxRetries = retries
+}
+
+// retryRoundTripper retries read requests that failed with throttling, transient
+// server errors or network errors, backing off exponentially between attempts
+type retryRoundTripper struct {
+	delegate http.RoundTripper
+	retries  int
+}
+
+func newRetryRoundTripper(delegate http.RoundTripper) http.RoundTripper {
+	return &retryRoundTripper{delegate: delegate, retries: maxRetries}
+}
+
+func (rt *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only requests without a body can be replayed safely
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return rt.delegate.RoundTrip(req)
+	}
+
+	backoff := retryInitialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := rt.delegate.RoundTrip(req)
+		if attempt >= rt.retries || !isRetryable(resp, err) {
+			return resp, err
+		}
+
+		wait := backoff
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			if retryAfter, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && retryAfter > 0 {
+				wait = time.Duration(retryAfter) * time.Second
+			}
+			resp.Body.Close()
+		}
+		klog.V(2).Infof("Retrying %s %s (retry %d/%d) in %s: %s", req.Method, req.URL.Host, attempt+1, rt.retries, wait, reason)
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > retryMaxBackoff {
+			backoff = retryMaxBackoff
+		}
+	}
+}
+
+// isRetryable reports whether a response or error is likely to succeed when retried
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) ||
+			utilnet.IsProbableEOF(err) || utilnet.IsTimeout(err) || utilnet.IsHTTP2ConnectionLost(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+
+	"kubectl-multi/pkg/config"
+)
+
+var (
+	tlsOverrides map[string]config.TLS
+	tlsFlags     config.TLS
+)
+
+// SetTLSOverrides configures the TLS verification per cluster name. The flag values
+// apply to every cluster and win over the per-cluster entries.
+func SetTLSOverrides(overrides map[string]config.TLS, flags config.TLS) {
+	tlsOverrides = overrides
+	tlsFlags = flags
+}
+
+// withTLSFlags applies the flag values on top of the TLS override of a cluster
+func withTLSFlags(tls config.TLS) config.TLS {
+	if tlsFlags.InsecureSkipTLSVerify {
+		tls.InsecureSkipTLSVerify = true
+	}
+	if tlsFlags.CertificateAuthority != "" {
+		tls.CertificateAuthority = tlsFlags.CertificateAuthority
+		tls.InsecureSkipTLSVerify = tlsFlags.InsecureSkipTLSVerify
+	}
+	return tls
+}
+
+// overrideTLS changes the kubeconfig cluster of the context according to the TLS
+// overrides. Clusters are looked up by name like in overrideCredentials.
+func overrideTLS(rawCfg *clientcmdapi.Config, ctxName, clusterName string) bool {
+	tls, ok := tlsOverrides[ctxName]
+	if !ok {
+		tls = tlsOverrides[clusterName]
+	}
+	tls = withTLSFlags(tls)
+
+	kubeCtx := rawCfg.Contexts[ctxName]
+	if tls == (config.TLS{}) || kubeCtx == nil || rawCfg.Clusters[kubeCtx.Cluster] == nil {
+		return false
+	}
+
+	overridden := *rawCfg.Clusters[kubeCtx.Cluster]
+	// client-go refuses a CA together with skipping verification
+	if tls.InsecureSkipTLSVerify {
+		overridden.InsecureSkipTLSVerify = true
+		overridden.CertificateAuthority = ""
+		overridden.CertificateAuthorityData = nil
+	} else if tls.CertificateAuthority != "" {
+		overridden.InsecureSkipTLSVerify = false
+		overridden.CertificateAuthority = tls.CertificateAuthority
+		overridden.CertificateAuthorityData = nil
+	}
+	rawCfg.Clusters[kubeCtx.Cluster] = &overridden
+
+	klog.V(3).Infof("Using TLS overrides for context %s: insecure %v, CA %q", ctxName, overridden.InsecureSkipTLSVerify, overridden.CertificateAuthority)
+	return true
+}
+
+// KubectlTLSArgs returns the kubectl flags that apply the TLS overrides of a context
+// to commands run through kubectl
+func KubectlTLSArgs(ctxName string) []string {
+	tls := withTLSFlags(tlsOverrides[ctxName])
+	if tls.InsecureSkipTLSVerify {
+		return []string{"--insecure-skip-tls-verify"}
+	}
+	if tls.CertificateAuthority != "" {
+		return []string{"--certificate-authority", tls.CertificateAuthority}
+	}
+	return nil
+}
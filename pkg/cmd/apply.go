@@ -3,8 +3,12 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
 
 	"kubectl-multi/pkg/cluster"
 	"kubectl-multi/pkg/util"
@@ -52,21 +56,26 @@ func newApplyCommand() *cobra.Command {
 	var filename string
 	var recursive bool
 	var dryRun string
+	var skipAccessCheck bool
+	var templated bool
 
 	cmd := &cobra.Command{
-		Use:   "apply (-f FILENAME | --filename=FILENAME)",
-		Short: "Apply a configuration to resources across all managed clusters",
+		Use:         "apply (-f FILENAME | --filename=FILENAME)",
+		Short:       "Apply a configuration to resources across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		Long: `Apply a configuration to resources across all managed clusters.
 This command applies manifests to all KubeStellar managed clusters.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
-			return handleApplyCommand(filename, recursive, dryRun, kubeconfig, remoteCtx, namespace, allNamespaces)
+			return handleApplyCommand(filename, recursive, templated, dryRun, skipAccessCheck, kubeconfig, remoteCtx, namespace, allNamespaces)
 		},
 	}
 
 	cmd.Flags().StringVarP(&filename, "filename", "f", "", "filename, directory, or URL to files to use to apply the resource")
 	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "process the directory used in -f, --filename recursively")
 	cmd.Flags().StringVar(&dryRun, "dry-run", "none", "must be \"none\", \"server\", or \"client\"")
+	cmd.Flags().BoolVar(&skipAccessCheck, "skip-access-check", false, "do not check the create and patch permissions in every cluster before applying")
+	cmd.Flags().BoolVar(&templated, "template", false, "render the manifests per cluster as Go templates with the .Name, .Labels and .Annotations of its ManagedCluster")
 
 	// Set custom help function
 	cmd.SetHelpFunc(applyHelpFunc)
@@ -79,7 +88,7 @@ This command applies manifests to all KubeStellar managed clusters.`,
 	return cmd
 }
 
-func handleApplyCommand(filename string, recursive bool, dryRun, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+func handleApplyCommand(filename string, recursive, templated bool, dryRun string, skipAccessCheck bool, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
 	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
 	if err != nil {
 		return fmt.Errorf("failed to discover clusters: %v", err)
@@ -105,6 +114,38 @@ func handleApplyCommand(filename string, recursive bool, dryRun, kubeconfig, rem
 	// Identify ITS (control) cluster context
 	itsContext := remoteCtx
 
+	if err := checkMutationTargets(clusters, itsContext); err != nil {
+		return err
+	}
+
+	// Templates are rendered for all clusters first, so that an error applies nothing
+	// and the access check sees the objects each cluster gets
+	manifest := func(c cluster.ClusterInfo) string { return filename }
+	if templated {
+		rendered, cleanup, err := renderClusterManifests(filename, recursive, clusters, kubeconfig, remoteCtx)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		manifest = func(c cluster.ClusterInfo) string { return rendered[c.Name] }
+	}
+
+	// Dry runs change nothing, so there is nothing to protect
+	if !skipAccessCheck && (dryRun == "none" || dryRun == "") {
+		if err := checkApplyAccess(clusters, itsContext, manifest, recursive && !templated, namespace); err != nil {
+			return err
+		}
+	}
+
+	confirmed, err := confirmCommand("apply", clusters, itsContext)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Apply cancelled...")
+		return nil
+	}
+
 	// Build maps for quick lookup
 	contextToCluster := make(map[string]cluster.ClusterInfo)
 	for _, c := range clusters {
@@ -113,8 +154,8 @@ func handleApplyCommand(filename string, recursive bool, dryRun, kubeconfig, rem
 
 	// 1. Run for current context (if present)
 	if cinfo, ok := contextToCluster[currentContext]; ok {
-		args := []string{"apply", "-f", filename, "--context", cinfo.Context}
-		if recursive {
+		args := []string{"apply", "-f", manifest(cinfo), "--context", cinfo.Context}
+		if recursive && !templated {
 			args = append(args, "-R")
 		}
 		if dryRun != "none" && dryRun != "" {
@@ -123,14 +164,7 @@ func handleApplyCommand(filename string, recursive bool, dryRun, kubeconfig, rem
 		if namespace != "" {
 			args = append(args, "-n", namespace)
 		}
-		output, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
-		}
-		fmt.Println()
+		runClusterKubectl(cinfo, args, kubeconfig, "apply")
 	}
 
 	// 2. Run for KubeStellar clusters (excluding ITS and current)
@@ -138,8 +172,8 @@ func handleApplyCommand(filename string, recursive bool, dryRun, kubeconfig, rem
 		if c.Context == currentContext || c.Context == itsContext {
 			continue
 		}
-		args := []string{"apply", "-f", filename, "--context", c.Context}
-		if recursive {
+		args := []string{"apply", "-f", manifest(c), "--context", c.Context}
+		if recursive && !templated {
 			args = append(args, "-R")
 		}
 		if dryRun != "none" && dryRun != "" {
@@ -148,19 +182,12 @@ func handleApplyCommand(filename string, recursive bool, dryRun, kubeconfig, rem
 		if namespace != "" {
 			args = append(args, "-n", namespace)
 		}
-		output, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", c.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
-		}
-		fmt.Println()
+		runClusterKubectl(c, args, kubeconfig, "apply")
 	}
 
 	// 3. Print warning for ITS (control) cluster
 	if cinfo, ok := contextToCluster[itsContext]; ok {
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
+		printClusterHeader(cinfo.Context)
 		fmt.Printf("Cannot perform this operation on ITS (control) cluster: %s\n", cinfo.Context)
 		fmt.Println()
 	}
@@ -168,6 +195,28 @@ func handleApplyCommand(filename string, recursive bool, dryRun, kubeconfig, rem
 	return nil
 }
 
+// checkApplyAccess checks the create and patch permissions for the objects of the
+// manifests of every cluster. Manifests that cannot be read ahead of time fail the
+// check, as nothing would protect the fleet from a half applied change.
+func checkApplyAccess(clusters []cluster.ClusterInfo, itsContext string, manifest func(cluster.ClusterInfo) string, recursive bool, namespace string) error {
+	checks := make(map[string][]accessCheck)
+	for _, c := range clusters {
+		if c.Context == itsContext {
+			continue
+		}
+		path := manifest(c)
+		if _, ok := checks[path]; ok {
+			continue
+		}
+		pathChecks, err := manifestAccessChecks(path, recursive, []string{"create", "patch"})
+		if err != nil {
+			return fmt.Errorf("%v (use --skip-access-check to apply without checking)", err)
+		}
+		checks[path] = pathChecks
+	}
+	return checkClusterAccess(clusters, itsContext, func(c cluster.ClusterInfo) []accessCheck { return checks[manifest(c)] }, namespace)
+}
+
 func newViewLastAppliedCommand() *cobra.Command {
 	var filename string
 	var output string
@@ -238,14 +287,7 @@ func handleViewLastAppliedCommand(filename, output string, recursive bool, extra
 			args = append(args, extraArgs...)
 		}
 		args = append(args, "--context", cinfo.Context)
-		cmdOutput, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(cmdOutput)
-		}
-		fmt.Println()
+		runClusterKubectl(cinfo, args, kubeconfig, "apply view-last-applied")
 	}
 
 	// 2. Run for KubeStellar clusters (excluding ITS and current)
@@ -267,19 +309,12 @@ func handleViewLastAppliedCommand(filename, output string, recursive bool, extra
 			args = append(args, extraArgs...)
 		}
 		args = append(args, "--context", c.Context)
-		cmdOutput, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", c.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(cmdOutput)
-		}
-		fmt.Println()
+		runClusterKubectl(c, args, kubeconfig, "apply view-last-applied")
 	}
 
 	// 3. Print warning for ITS (control) cluster
 	if cinfo, ok := contextToCluster[itsContext]; ok {
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
+		printClusterHeader(cinfo.Context)
 		fmt.Printf("Cannot perform this operation on ITS (control) cluster: %s\n", cinfo.Context)
 		fmt.Println()
 	}
@@ -293,9 +328,10 @@ func newEditLastAppliedCommand() *cobra.Command {
 	var recursive bool
 
 	cmd := &cobra.Command{
-		Use:   "edit-last-applied",
-		Short: "Edit the last-applied-configuration annotations across all managed clusters",
-		Long:  `Edit the latest last-applied-configuration annotations by type/name or file across all KubeStellar managed clusters. Opens your default editor for each resource.`,
+		Use:         "edit-last-applied",
+		Short:       "Edit the last-applied-configuration annotations across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
+		Long:        `Edit the latest last-applied-configuration annotations by type/name or file across all KubeStellar managed clusters. Opens your default editor for each resource.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
 			return handleEditLastAppliedCommand(filename, output, recursive, args, kubeconfig, remoteCtx, namespace, allNamespaces)
@@ -322,9 +358,10 @@ func newSetLastAppliedCommand() *cobra.Command {
 	var recursive bool
 
 	cmd := &cobra.Command{
-		Use:   "set-last-applied",
-		Short: "Set the last-applied-configuration annotations across all managed clusters",
-		Long:  `Set the latest last-applied-configuration annotations by file across all KubeStellar managed clusters.`,
+		Use:         "set-last-applied",
+		Short:       "Set the last-applied-configuration annotations across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
+		Long:        `Set the latest last-applied-configuration annotations by file across all KubeStellar managed clusters.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return handleSetLastAppliedCommand(filename, output, createAnnotation, dryRun, recursive)
 		},
@@ -344,9 +381,35 @@ func handleSetLastAppliedCommand(filename, output string, createAnnotation bool,
 	return nil
 }
 
+// withClusterArgs adds the TLS and credential overrides of the cluster named by
+// --context right after it. Credentials are passed in a temporary kubeconfig, which the
+// returned function removes once kubectl is done.
+func withClusterArgs(args []string, kubeconfig string) ([]string, func(), error) {
+	for i, arg := range args {
+		if arg == "--context" && i+1 < len(args) {
+			credentialKubeconfig, cleanup, err := cluster.KubectlCredentialKubeconfig(kubeconfig, args[i+1])
+			if err != nil {
+				return nil, nil, err
+			}
+			clusterArgs := cluster.KubectlTLSArgs(args[i+1])
+			if credentialKubeconfig != "" {
+				clusterArgs = append(clusterArgs, "--kubeconfig", credentialKubeconfig)
+			}
+			return append(append(append([]string{}, args[:i+2]...), clusterArgs...), args[i+2:]...), cleanup, nil
+		}
+	}
+	return args, func() {}, nil
+}
+
 // runKubectl runs a kubectl command with the given args and kubeconfig, returns output and error
 func runKubectl(args []string, kubeconfig string) (string, error) {
-	cmd := exec.Command("kubectl", args...)
+	kubectlArgs, cleanup, err := withClusterArgs(args, kubeconfig)
+	if err != nil {
+		auditKubectl(args, "", err)
+		return err.Error(), err
+	}
+	defer cleanup()
+	cmd := exec.Command("kubectl", kubectlArgs...)
 	if kubeconfig != "" {
 		cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfig)
 	}
@@ -354,7 +417,135 @@ func runKubectl(args []string, kubeconfig string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
+		auditKubectl(args, stdout.String()+stderr.String(), err)
 		return stdout.String() + stderr.String(), err
 	}
+	auditKubectl(args, stdout.String(), nil)
 	return stdout.String(), nil
 }
+
+// runClusterKubectl runs a kubectl command for one cluster and prints its section. The
+// outcome is recorded like the API calls of the read commands, so failures count
+// towards the failure summary, --error-policy, --fail-fast and --ignore-errors.
+func runClusterKubectl(clusterInfo cluster.ClusterInfo, args []string, kubeconfig, operation string) {
+	done, ok := clusterInfo.StartOperation()
+	defer done()
+	if !ok {
+		return
+	}
+
+	output, err := runKubectl(args, kubeconfig)
+	printClusterHeader(clusterInfo.Context)
+	if err != nil {
+		warnClusterFailure(clusterInfo.Name, fmt.Errorf("%v: %s", err, strings.TrimSpace(output)), "%s", operation)
+	} else {
+		fmt.Print(output)
+	}
+	fmt.Println()
+}
+
+// clusterTemplateData is the data of the manifests rendered by apply --template
+type clusterTemplateData struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// renderClusterManifests renders the manifests of filename as Go templates for every
+// cluster and returns the rendered file of each cluster by name, and a function that
+// removes the files
+func renderClusterManifests(filename string, recursive bool, clusters []cluster.ClusterInfo, kubeconfig, remoteCtx string) (map[string]string, func(), error) {
+	files, err := manifestFiles(filename, recursive)
+	if err != nil {
+		return nil, nil, err
+	}
+	var templates []*template.Template
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+		tmpl, err := template.New(file).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse template %s: %v", file, err)
+		}
+		templates = append(templates, tmpl)
+	}
+
+	// Clusters that are not registered with the ITS only have a name
+	metadata := make(map[string]cluster.ManagedCluster)
+	if remoteCtx != "" {
+		managedClusters, err := cluster.ListManagedClusters(kubeconfig, remoteCtx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, mc := range managedClusters {
+			metadata[mc.Name] = mc
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "kubectl-multi-apply-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	rendered := make(map[string]string)
+	for _, c := range clusters {
+		mc := metadata[c.Name]
+		data := clusterTemplateData{Name: c.Name, Labels: mc.Labels, Annotations: mc.Annotations}
+		var out bytes.Buffer
+		for _, tmpl := range templates {
+			out.WriteString("---\n")
+			if err := tmpl.Execute(&out, data); err != nil {
+				cleanup()
+				return nil, nil, fmt.Errorf("failed to render %s for cluster %s: %v", tmpl.Name(), c.Name, err)
+			}
+			out.WriteString("\n")
+		}
+		path := filepath.Join(dir, c.Name+".yaml")
+		if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to write rendered manifests: %v", err)
+		}
+		rendered[c.Name] = path
+	}
+	return rendered, cleanup, nil
+}
+
+// manifestFiles returns the YAML and JSON files of a file or directory, including the
+// subdirectories when recursive
+func manifestFiles(filename string, recursive bool) ([]string, error) {
+	if filename == "" || filename == "-" || strings.Contains(filename, "://") {
+		return nil, fmt.Errorf("--template needs local manifest files")
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+	if !info.IsDir() {
+		return []string{filename}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(filename, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != filename && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+	return files, nil
+}
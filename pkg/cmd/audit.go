@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// auditEntry is one line of the --audit-log file, written per cluster a mutating
+// command ran against
+type auditEntry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	// Cluster is the name of the cluster and Context its kubeconfig context. Changes
+	// of the ITS, e.g. of ManagedClusters, only have a context.
+	Cluster string   `json:"cluster,omitempty"`
+	Context string   `json:"context,omitempty"`
+	Objects []string `json:"objects,omitempty"`
+	DryRun  bool     `json:"dryRun,omitempty"`
+	Result  string   `json:"result"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// readOnlyVerbs are the kubectl verbs that never change a cluster. Every other verb,
+// including kubectl plugins, is treated as a mutation and recorded in the audit log.
+var readOnlyVerbs = map[string]bool{
+	"api-resources": true,
+	"api-versions":  true,
+	"cluster-info":  true,
+	"describe":      true,
+	"diff":          true,
+	"events":        true,
+	"explain":       true,
+	"get":           true,
+	"kustomize":     true,
+	"logs":          true,
+	"top":           true,
+	"version":       true,
+	"wait":          true,
+}
+
+// readOnlySubcommands are the read-only subcommands of verbs that otherwise change
+// clusters
+var readOnlySubcommands = map[string]map[string]bool{
+	"apply":   {"view-last-applied": true},
+	"auth":    {"can-i": true, "whoami": true},
+	"rollout": {"history": true, "status": true},
+}
+
+var auditFile *os.File
+
+// openAuditLog opens the audit log for appending. It is opened before any command
+// runs, so a log that cannot be written stops the command before it mutates anything.
+func openAuditLog(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	auditFile = f
+	return nil
+}
+
+// closeAuditLog closes the audit log opened by openAuditLog
+func closeAuditLog() {
+	if auditFile != nil {
+		auditFile.Close()
+		auditFile = nil
+	}
+}
+
+// isMutation reports whether kubectl args change the cluster
+func isMutation(args []string) bool {
+	if len(args) == 0 || readOnlyVerbs[args[0]] {
+		return false
+	}
+	return len(args) < 2 || !readOnlySubcommands[args[0]][args[1]]
+}
+
+// auditKubectl records a kubectl command run against one cluster. The objects are
+// taken from kubectl's "kind/name action" output lines.
+func auditKubectl(args []string, output string, err error) {
+	if auditFile == nil || !isMutation(args) {
+		return
+	}
+
+	kubeContext := ""
+	dryRun := false
+	for i, arg := range args {
+		if arg == "--context" && i+1 < len(args) {
+			kubeContext = args[i+1]
+		}
+		if strings.HasPrefix(arg, "--dry-run=") && arg != "--dry-run=none" {
+			dryRun = true
+		}
+	}
+
+	var objects []string
+	if err != nil {
+		err = fmt.Errorf("%v: %s", err, strings.TrimSpace(output))
+	} else {
+		for _, line := range strings.Split(output, "\n") {
+			if fields := strings.Fields(line); len(fields) > 1 && strings.Contains(fields[0], "/") {
+				objects = append(objects, fields[0])
+			}
+		}
+	}
+
+	writeAudit(cluster.ClusterName(kubeContext), kubeContext, objects, dryRun, err)
+}
+
+// writeAudit appends an entry for the running command to the audit log
+func writeAudit(clusterName, kubeContext string, objects []string, dryRun bool, err error) {
+	if auditFile == nil {
+		return
+	}
+
+	entry := auditEntry{
+		Time:    time.Now().UTC(),
+		User:    os.Getenv("USER"),
+		Command: "kubectl multi " + strings.Join(os.Args[1:], " "),
+		Cluster: clusterName,
+		Context: kubeContext,
+		Objects: objects,
+		DryRun:  dryRun,
+		Result:  "success",
+	}
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	}
+	entry.Host, _ = os.Hostname()
+	if err != nil {
+		entry.Result = "error"
+		entry.Error = err.Error()
+	}
+
+	data, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		printWarning("failed to encode audit entry: %v", marshalErr)
+		return
+	}
+	if _, writeErr := auditFile.Write(append(data, '\n')); writeErr != nil {
+		printWarning("failed to write audit log: %v", writeErr)
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestIsMutation ensures only known read-only kubectl commands skip the safety checks
+func TestIsMutation(t *testing.T) {
+	tests := []struct {
+		args     string
+		mutating bool
+	}{
+		{"get pods", false},
+		{"logs nginx", false},
+		{"auth can-i create deployments", false},
+		{"rollout status deployment/nginx", false},
+		{"apply view-last-applied deployment/nginx", false},
+		{"apply -f app.yaml", true},
+		{"rollout restart deployment/nginx", true},
+		{"drain node1", true},
+		{"exec nginx -- ls", true},
+		{"certificate approve csr1", true},
+		{"auth reconcile -f rbac.yaml", true},
+		{"neat get deployment nginx", true},
+	}
+
+	for _, tt := range tests {
+		if got := isMutation(strings.Fields(tt.args)); got != tt.mutating {
+			t.Errorf("isMutation(%q) = %v, want %v", tt.args, got, tt.mutating)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// clusterCapacity is the summed capacity and consumption of a single cluster
+type clusterCapacity struct {
+	Name        string
+	Nodes       int
+	AllocCPU    resource.Quantity
+	AllocMemory resource.Quantity
+	ReqCPU      resource.Quantity
+	ReqMemory   resource.Quantity
+	LimCPU      resource.Quantity
+	LimMemory   resource.Quantity
+	Pods        int
+	PodCapacity resource.Quantity
+}
+
+func (c *clusterCapacity) add(other clusterCapacity) {
+	c.Nodes += other.Nodes
+	c.AllocCPU.Add(other.AllocCPU)
+	c.AllocMemory.Add(other.AllocMemory)
+	c.ReqCPU.Add(other.ReqCPU)
+	c.ReqMemory.Add(other.ReqMemory)
+	c.LimCPU.Add(other.LimCPU)
+	c.LimMemory.Add(other.LimMemory)
+	c.Pods += other.Pods
+	c.PodCapacity.Add(other.PodCapacity)
+}
+
+func newCapacityCommand() *cobra.Command {
+	var selector string
+
+	cmd := &cobra.Command{
+		Use:   "capacity",
+		Short: "Summarize allocatable capacity and resource requests across managed clusters",
+		Long: `Summarize the capacity of every managed cluster: allocatable CPU and memory,
+the total requests and limits of all scheduled pods, the remaining headroom,
+and the pod count compared to the pod capacity of the nodes.
+
+A final <fleet> row rolls up all clusters.`,
+		Example: `# Show the capacity of all managed clusters
+kubectl multi capacity
+
+# Only take worker nodes into account
+kubectl multi capacity -l node-role.kubernetes.io/worker`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleCapacityCommand(selector, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "node selector (label query) to filter on")
+
+	return cmd
+}
+
+func handleCapacityCommand(selector, kubeconfig, remoteCtx string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "CLUSTER\tNODES\tCPU ALLOCATABLE\tCPU REQUESTS\tCPU LIMITS\tCPU HEADROOM\tMEMORY ALLOCATABLE\tMEMORY REQUESTS\tMEMORY LIMITS\tMEMORY HEADROOM\tPODS\n")
+
+	fleet := clusterCapacity{Name: "<fleet>"}
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		capacity, err := getClusterCapacity(clusterInfo, selector)
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "compute capacity")
+			continue
+		}
+		printCapacityRow(tw, capacity)
+		fleet.add(capacity)
+	}
+	printCapacityRow(tw, fleet)
+
+	return nil
+}
+
+func printCapacityRow(tw tableWriter, c clusterCapacity) {
+	fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d/%d\n",
+		c.Name, c.Nodes,
+		formatCPU(c.AllocCPU), formatCPU(c.ReqCPU), formatCPU(c.LimCPU), formatHeadroom(c.ReqCPU, c.AllocCPU),
+		formatMemory(c.AllocMemory), formatMemory(c.ReqMemory), formatMemory(c.LimMemory), formatHeadroom(c.ReqMemory, c.AllocMemory),
+		c.Pods, c.PodCapacity.Value())
+}
+
+// getClusterCapacity sums the allocatable resources of the selected nodes and the
+// requests and limits of all non-terminated pods scheduled onto them
+func getClusterCapacity(clusterInfo cluster.ClusterInfo, selector string) (clusterCapacity, error) {
+	capacity := clusterCapacity{Name: clusterInfo.Name}
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	nodes, err := clusterInfo.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return capacity, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	nodeNames := make(map[string]bool)
+	for _, node := range nodes.Items {
+		nodeNames[node.Name] = true
+		capacity.Nodes++
+		capacity.AllocCPU.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		capacity.AllocMemory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+		capacity.PodCapacity.Add(node.Status.Allocatable[corev1.ResourcePods])
+	}
+
+	pods, err := clusterInfo.Client().CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return capacity, fmt.Errorf("failed to list pods: %v", err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !nodeNames[pod.Spec.NodeName] {
+			continue
+		}
+		capacity.Pods++
+		requests, limits := resourcehelper.PodRequestsAndLimits(pod)
+		capacity.ReqCPU.Add(requests[corev1.ResourceCPU])
+		capacity.ReqMemory.Add(requests[corev1.ResourceMemory])
+		capacity.LimCPU.Add(limits[corev1.ResourceCPU])
+		capacity.LimMemory.Add(limits[corev1.ResourceMemory])
+	}
+
+	return capacity, nil
+}
+
+// formatHeadroom renders the percentage of allocatable capacity that is not yet requested
+func formatHeadroom(requested, allocatable resource.Quantity) string {
+	if allocatable.IsZero() {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("%d%%", 100-requested.MilliValue()*100/allocatable.MilliValue())
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newClustersCommand() *cobra.Command {
+	var annotationColumns []string
+
+	cmd := &cobra.Command{
+		Use:   "clusters",
+		Short: "List the managed clusters registered with the ITS",
+		Long: `List the managed clusters registered with the ITS with their availability. Annotations
+of the ManagedCluster objects, such as the ones set with annotate-clusters, can be
+shown as extra columns.`,
+		Example: `# List the managed clusters with their owner and maintenance window
+kubectl multi clusters --annotation-columns owner,maintenance-window`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleClustersCommand(annotationColumns, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&annotationColumns, "annotation-columns", nil, "comma separated annotation keys to show as columns")
+
+	return cmd
+}
+
+func newAnnotateClustersCommand() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "annotate-clusters (CLUSTER... | --all) KEY=VALUE... [KEY-]",
+		Short: "Set or remove annotations of ManagedCluster objects",
+		Long: `Set annotations such as the owner, cost center or maintenance window on the
+ManagedCluster objects of the ITS. KEY- removes an annotation.
+
+The annotations can be shown with kubectl multi clusters --annotation-columns and are
+available to the manifests of kubectl multi apply --template.`,
+		Example: `# Record the owner and cost center of two clusters
+kubectl multi annotate-clusters cluster1 cluster2 owner=team-a cost-center=cc-42
+
+# Set the maintenance window of all clusters and remove the freeze annotation
+kubectl multi annotate-clusters --all maintenance-window=sun-02:00 freeze-`,
+		Annotations: map[string]string{mutatingAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleAnnotateClustersCommand(args, all, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "annotate all managed clusters selected by --clusters")
+
+	return cmd
+}
+
+func handleClustersCommand(annotationColumns []string, kubeconfig, remoteCtx string) error {
+	clusters, err := cluster.ListManagedClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return err
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	if len(clusters) == 0 {
+		fmt.Fprintf(tw, "No resource found.\n")
+		return tw.Flush()
+	}
+
+	header := "CLUSTER\tAVAILABLE\tAGE"
+	for _, key := range annotationColumns {
+		header += "\t" + strings.ToUpper(key)
+	}
+	fmt.Fprintln(tw, header)
+	for _, mc := range clusters {
+		row := fmt.Sprintf("%s\t%s\t%s", mc.Name, mc.Available, util.FormatAge(mc.Created))
+		for _, key := range annotationColumns {
+			value, ok := mc.Annotations[key]
+			if !ok {
+				value = "<none>"
+			}
+			row += "\t" + value
+		}
+		fmt.Fprintln(tw, row)
+	}
+	return tw.Flush()
+}
+
+func handleAnnotateClustersCommand(args []string, all bool, kubeconfig, remoteCtx string) error {
+	annotations := make(map[string]interface{})
+	var names []string
+	for _, arg := range args {
+		if key, value, ok := strings.Cut(arg, "="); ok {
+			annotations[key] = value
+		} else if strings.HasSuffix(arg, "-") {
+			// null removes the annotation in a merge patch
+			annotations[strings.TrimSuffix(arg, "-")] = nil
+		} else {
+			names = append(names, arg)
+		}
+	}
+	if len(annotations) == 0 {
+		return fmt.Errorf("at least one KEY=VALUE or KEY- is required")
+	}
+	if all == (len(names) > 0) {
+		return fmt.Errorf("either cluster names or --all is required")
+	}
+
+	if all {
+		clusters, err := cluster.ListManagedClusters(kubeconfig, remoteCtx)
+		if err != nil {
+			return err
+		}
+		for _, mc := range clusters {
+			names = append(names, mc.Name)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no clusters discovered")
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %v", err)
+	}
+	dyn, err := cluster.GetRemoteDynamicClient(kubeconfig, remoteCtx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		_, err := dyn.Resource(cluster.ManagedClusterGVR).Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+		writeAudit("", remoteCtx, []string{"managedcluster/" + name}, false, err)
+		if err != nil {
+			warnClusterFailure(name, err, "annotate managed cluster")
+			continue
+		}
+		fmt.Printf("managedcluster/%s annotated\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// compatRule is a known problem of plugin releases with KubeStellar releases. The
+// ranges include their minimum and exclude their maximum; empty bounds are open.
+type compatRule struct {
+	PluginMin, PluginMax           string
+	KubeStellarMin, KubeStellarMax string
+	// Refuse stops commands instead of warning
+	Refuse bool
+	Reason string
+}
+
+// compatRules is the compatibility matrix of the plugin with KubeStellar
+var compatRules = []compatRule{
+	{KubeStellarMax: "0.20.0", Refuse: true,
+		Reason: "the plugin needs the BindingPolicy API of control.kubestellar.io/v1alpha1, added in KubeStellar 0.20"},
+	{KubeStellarMin: "0.20.0", KubeStellarMax: "0.25.0",
+		Reason: "KubeStellar releases before 0.25 are not tested with the plugin"},
+}
+
+// kubeStellarFingerprints are the newest APIs a WDS serves by KubeStellar release,
+// for guessing the release when the core chart cannot be read
+var kubeStellarFingerprints = []struct {
+	Resource string
+	Since    string
+}{
+	{"combinedstatuses", "0.23.0"},
+	{"customtransforms", "0.21.0"},
+	{"bindingpolicies", "0.20.0"},
+}
+
+// kubeStellarVersion is the detected KubeStellar release, exactly from the core chart
+// or as the range [Low, High) from the APIs of a WDS
+type kubeStellarVersion struct {
+	Low, High *version.Version
+	Exact     bool
+	Source    string
+}
+
+func (v kubeStellarVersion) String() string {
+	switch {
+	case v.Exact:
+		return v.Low.String()
+	case v.High == nil:
+		return ">=" + v.Low.String()
+	}
+	return fmt.Sprintf(">=%s <%s", v.Low, v.High)
+}
+
+func newCompatCommand() *cobra.Command {
+	var wdsContext string
+
+	cmd := &cobra.Command{
+		Use:   "compat",
+		Short: "Check the plugin version against the installed KubeStellar",
+		Long: `Compare the plugin version with the KubeStellar release of the current context and
+report known problems of the combination.
+
+The release is read from the KubeStellar core chart in the hosting cluster. When the
+chart cannot be read, it is estimated from the APIs the WDS serves. Other commands
+check the estimate on startup with the cached API discovery of the WDS and warn about
+known incompatible releases; --compat-check refuse stops them and off disables it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, _, _, _, _ := GetGlobalFlags()
+			return handleCompatCommand(kubeconfig, wdsContext)
+		},
+	}
+
+	cmd.Flags().StringVar(&wdsContext, "wds-context", defaultWDSContext, "kubeconfig context of the WDS whose APIs estimate the release when the core chart cannot be read")
+	return cmd
+}
+
+func handleCompatCommand(kubeconfig, wdsContext string) error {
+	fmt.Printf("Plugin:      %s\n", util.Version)
+	ksVersion, err := detectKubeStellarVersion(kubeconfig)
+	if err != nil {
+		printStderrWarning("cannot read the KubeStellar core chart: %v", err)
+		if ksVersion, err = fingerprintKubeStellar(context.Background(), kubeconfig, wdsContext); err != nil {
+			return fmt.Errorf("cannot detect the KubeStellar version: %v", err)
+		}
+	}
+	fmt.Printf("KubeStellar: %s (from %s)\n", ksVersion, ksVersion.Source)
+
+	warnings, refusal := checkCompatibility(util.Version, ksVersion)
+	for _, warning := range warnings {
+		printStderrWarning("%s", warning)
+	}
+	if refusal != nil {
+		return refusal
+	}
+	if len(warnings) == 0 {
+		fmt.Println("No known compatibility problems.")
+	}
+	return nil
+}
+
+// checkCompatibility returns the reasons of the matched rules that warn, and an error
+// for the first matched rule that refuses. Development builds match every plugin range.
+func checkCompatibility(pluginVersion string, ksVersion kubeStellarVersion) ([]string, error) {
+	plugin, _ := version.ParseGeneric(pluginVersion)
+	var warnings []string
+	for _, rule := range compatRules {
+		if plugin != nil && !inRange(plugin, rule.PluginMin, rule.PluginMax) {
+			continue
+		}
+		if !ksVersion.within(rule.KubeStellarMin, rule.KubeStellarMax) {
+			continue
+		}
+		if rule.Refuse {
+			return warnings, fmt.Errorf("plugin %s does not work with KubeStellar %s: %s", pluginVersion, ksVersion, rule.Reason)
+		}
+		warnings = append(warnings, rule.Reason)
+	}
+	return warnings, nil
+}
+
+// within reports whether the whole detected range is in [min, max), so an estimate
+// only matches rules that hold for every release it may be
+func (v kubeStellarVersion) within(min, max string) bool {
+	if min != "" && v.Low.LessThan(version.MustParseGeneric(min)) {
+		return false
+	}
+	if max == "" {
+		return true
+	}
+	if v.Exact {
+		return v.Low.LessThan(version.MustParseGeneric(max))
+	}
+	return v.High != nil && !version.MustParseGeneric(max).LessThan(v.High)
+}
+
+func inRange(v *version.Version, min, max string) bool {
+	if min != "" && v.LessThan(version.MustParseGeneric(min)) {
+		return false
+	}
+	return max == "" || v.LessThan(version.MustParseGeneric(max))
+}
+
+// detectKubeStellarVersion returns the version of the KubeStellar core chart
+// installed in the hosting cluster of the current context
+func detectKubeStellarVersion(kubeconfig string) (kubeStellarVersion, error) {
+	args := []string{"list", "-A", "-o", "json"}
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+	output, err := exec.Command("helm", args...).Output()
+	if err != nil {
+		return kubeStellarVersion{}, fmt.Errorf("helm list failed: %v", err)
+	}
+	var releases []struct {
+		Name       string `json:"name"`
+		Chart      string `json:"chart"`
+		AppVersion string `json:"app_version"`
+	}
+	if err := json.Unmarshal(output, &releases); err != nil {
+		return kubeStellarVersion{}, err
+	}
+	for _, release := range releases {
+		if !strings.HasPrefix(release.Chart, "core-chart-") {
+			continue
+		}
+		raw := release.AppVersion
+		if raw == "" {
+			raw = strings.TrimPrefix(release.Chart, "core-chart-")
+		}
+		parsed, err := version.ParseGeneric(raw)
+		if err != nil {
+			return kubeStellarVersion{}, fmt.Errorf("invalid KubeStellar version %q: %v", raw, err)
+		}
+		return kubeStellarVersion{Low: parsed, Exact: true, Source: "helm release " + release.Name}, nil
+	}
+	return kubeStellarVersion{}, fmt.Errorf("no KubeStellar core chart release in the current context")
+}
+
+// fingerprintKubeStellar estimates the KubeStellar release from the newest
+// control.kubestellar.io API the WDS context serves. Requests to the WDS are cancelled
+// with ctx.
+func fingerprintKubeStellar(ctx context.Context, kubeconfig, wdsContext string) (kubeStellarVersion, error) {
+	clusterInfo, err := cluster.ContextClient(kubeconfig, wdsContext)
+	if err != nil {
+		return kubeStellarVersion{}, err
+	}
+	resources, err := clusterInfo.WithContext(ctx).DiscoveryClient().ServerResourcesForGroupVersion("control.kubestellar.io/v1alpha1")
+	if err != nil {
+		return kubeStellarVersion{}, fmt.Errorf("context %s serves no KubeStellar API: %v", wdsContext, err)
+	}
+	served := map[string]bool{}
+	for _, resource := range resources.APIResources {
+		served[resource.Name] = true
+	}
+	var high *version.Version
+	for _, fingerprint := range kubeStellarFingerprints {
+		since := version.MustParseGeneric(fingerprint.Since)
+		if served[fingerprint.Resource] {
+			return kubeStellarVersion{Low: since, High: high, Source: "the APIs of " + wdsContext}, nil
+		}
+		high = since
+	}
+	return kubeStellarVersion{Low: version.MustParseGeneric("0.0.0"), High: high, Source: "the APIs of " + wdsContext}, nil
+}
+
+// compatTimeout bounds the startup check
+const compatTimeout = 3 * time.Second
+
+// compatExempt are the commands that do not use KubeStellar, or repair the plugin
+var compatExempt = map[string]bool{
+	"compat": true, "version": true, "install": true, "upgrade": true, "uninstall": true,
+	"completion": true, "config": true, "help": true, "doctor": true,
+}
+
+// configuredWDSContext returns the WDS context of a command: its --wds-context, else
+// the wdsContext of the selected profile or of the defaults of the config file, else
+// wds1
+func configuredWDSContext(cmd *cobra.Command) string {
+	if flag := cmd.Flags().Lookup("wds-context"); flag != nil {
+		return flag.Value.String()
+	}
+	if profileName != "" {
+		if profile, err := userConfig.Profile(profileName); err == nil && profile.WDSContext != "" {
+			return profile.WDSContext
+		}
+	}
+	if userConfig.Defaults.WDSContext != "" {
+		return userConfig.Defaults.WDSContext
+	}
+	return defaultWDSContext
+}
+
+// checkStartupCompat runs the compatibility check of --compat-check before a command
+// against the configured WDS context. It reads the cached API discovery of the WDS
+// and gives up after compatTimeout when the WDS has to be asked and does not answer.
+// It stays silent when there is no WDS context or the plugin is a development build.
+func checkStartupCompat(cmd *cobra.Command, kubeconfig string) error {
+	if compatCheck == "off" || util.Version == "dev" {
+		return nil
+	}
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	if compatExempt[top.Name()] || !top.HasParent() {
+		return nil
+	}
+
+	loading := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loading.ExplicitPath = kubeconfig
+	}
+	rawCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loading, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return nil
+	}
+	wdsContext := configuredWDSContext(cmd)
+	if _, ok := rawCfg.Contexts[wdsContext]; !ok {
+		return nil
+	}
+	// An unreachable WDS without cached discovery must not delay every command
+	ctx, cancel := context.WithTimeout(context.Background(), compatTimeout)
+	defer cancel()
+	ksVersion, err := fingerprintKubeStellar(ctx, kubeconfig, wdsContext)
+	if err != nil {
+		klog.V(1).Infof("Skipping the compatibility check: %v", err)
+		return nil
+	}
+	warnings, refusal := checkCompatibility(util.Version, ksVersion)
+	if refusal != nil {
+		if compatCheck == "refuse" {
+			return fmt.Errorf("%v (run kubectl multi compat for details, or use --compat-check warn)", refusal)
+		}
+		warnings = append(warnings, refusal.Error())
+	}
+	for _, warning := range warnings {
+		printStderrWarning("%s", warning)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"kubectl-multi/pkg/config"
+)
+
+// TestCheckCompatibility ensures estimated KubeStellar versions only match the rules
+// that hold for every release they may be
+func TestCheckCompatibility(t *testing.T) {
+	exact := func(v string) kubeStellarVersion {
+		return kubeStellarVersion{Low: version.MustParseGeneric(v), Exact: true}
+	}
+	estimate := func(low, high string) kubeStellarVersion {
+		v := kubeStellarVersion{Low: version.MustParseGeneric(low)}
+		if high != "" {
+			v.High = version.MustParseGeneric(high)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		plugin   string
+		ks       kubeStellarVersion
+		warnings int
+		refused  bool
+	}{
+		{"supported release", "v0.3.0", exact("0.27.2"), 0, false},
+		{"untested release", "v0.3.0", exact("0.22.0"), 1, false},
+		{"release without BindingPolicy", "v0.3.0", exact("0.19.1"), 0, true},
+		{"development build", "dev", exact("0.19.1"), 0, true},
+		{"estimate of new releases", "v0.3.0", estimate("0.23.0", ""), 0, false},
+		{"estimate within untested releases", "v0.3.0", estimate("0.21.0", "0.23.0"), 1, false},
+		{"estimate without KubeStellar APIs", "v0.3.0", estimate("0.0.0", "0.20.0"), 0, true},
+	}
+
+	for _, tt := range tests {
+		warnings, err := checkCompatibility(tt.plugin, tt.ks)
+		if len(warnings) != tt.warnings || (err != nil) != tt.refused {
+			t.Errorf("%s: got %d warnings and error %v, want %d warnings and refused %v", tt.name, len(warnings), err, tt.warnings, tt.refused)
+		}
+	}
+}
+
+// TestConfiguredWDSContext ensures the startup check uses the --wds-context of the
+// command, then the config file, then wds1
+func TestConfiguredWDSContext(t *testing.T) {
+	saved := userConfig
+	defer func() { userConfig = saved }()
+
+	plain := &cobra.Command{Use: "get"}
+	withFlag := &cobra.Command{Use: "edit"}
+	withFlag.Flags().String("wds-context", defaultWDSContext, "")
+
+	userConfig = &config.Config{}
+	if got := configuredWDSContext(plain); got != defaultWDSContext {
+		t.Errorf("without config: got %q, want %q", got, defaultWDSContext)
+	}
+	userConfig = &config.Config{Defaults: config.Settings{WDSContext: "wds2"}}
+	if got := configuredWDSContext(plain); got != "wds2" {
+		t.Errorf("with config: got %q, want wds2", got)
+	}
+	withFlag.Flags().Set("wds-context", "wds3")
+	if got := configuredWDSContext(withFlag); got != "wds3" {
+		t.Errorf("with flag: got %q, want wds3", got)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/config"
+)
+
+// completionTimeout keeps an unreachable cluster from blocking the shell
+const completionTimeout = 3 * time.Second
+
+func newCompletionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Output the shell completion script for kubectl multi",
+		Long: `Output the shell completion script for kubectl multi.
+
+Resource types are completed from the cached API discovery of the managed clusters,
+--clusters from the discovered cluster names and -n from the namespaces of all
+clusters.
+
+For kubectl to complete "kubectl multi", install the script as the completion of
+the kubectl-multi binary and add an executable named kubectl_complete-multi to your
+PATH that runs: kubectl-multi __complete "$@"`,
+		Example: `# Load completion in the current bash session
+source <(kubectl multi completion bash)
+
+# Install completion for zsh
+kubectl multi completion zsh > "${fpath[1]}/_kubectl-multi"`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(os.Stdout)
+			case "fish":
+				return cmd.Root().GenFishCompletion(os.Stdout, true)
+			}
+			return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", args[0])
+		},
+	}
+
+	return cmd
+}
+
+// completionClusters discovers the clusters for a completion request. Warnings would
+// end up among the completions, so they are silenced.
+func completionClusters() []cluster.ClusterInfo {
+	cluster.SetQuiet(true)
+	cluster.SetDiscoveryCache(cacheDir, cacheTTL)
+	cluster.SetClusterTimeout(completionTimeout)
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return nil
+	}
+	return clusters
+}
+
+// completeResourceTypes completes the first argument with the resource names and
+// short names served by the clusters
+func completeResourceTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := make(map[string]bool)
+	for _, clusterInfo := range completionClusters() {
+		if clusterInfo.DiscoveryClient() == nil {
+			continue
+		}
+		// Partial results are fine for completion, so the error is ignored
+		lists, _ := clusterInfo.DiscoveryClient().ServerPreferredResources()
+		for _, list := range lists {
+			for _, resource := range list.APIResources {
+				if strings.Contains(resource.Name, "/") {
+					continue
+				}
+				seen[resource.Name] = true
+				for _, shortName := range resource.ShortNames {
+					seen[shortName] = true
+				}
+			}
+		}
+	}
+	return completionsWithPrefix(seen, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeClusterNames completes --clusters with the discovered clusters and the
+// cluster groups of the config file
+func completeClusterNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	seen := make(map[string]bool)
+	for _, clusterInfo := range completionClusters() {
+		seen[clusterInfo.Name] = true
+	}
+	// Completion runs without the persistent hooks, so the config is not loaded yet
+	if cfg, err := config.Load(configFile, false); err == nil {
+		for group := range cfg.ClusterGroups {
+			seen[group] = true
+		}
+	}
+	return completionsWithPrefix(seen, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNamespaces completes -n with the namespaces of all clusters
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	seen := make(map[string]bool)
+	for _, clusterInfo := range completionClusters() {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+		ctx, cancel := clusterInfo.RequestContext()
+		namespaces, err := clusterInfo.Client().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			continue
+		}
+		for _, ns := range namespaces.Items {
+			seen[ns.Name] = true
+		}
+	}
+	return completionsWithPrefix(seen, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionsWithPrefix returns the sorted values starting with prefix
+func completionsWithPrefix(values map[string]bool, prefix string) []string {
+	var completions []string
+	for value := range values {
+		if strings.HasPrefix(value, prefix) {
+			completions = append(completions, value)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
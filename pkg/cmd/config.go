@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"kubectl-multi/pkg/config"
+	"kubectl-multi/pkg/multicluster"
+	"kubectl-multi/pkg/util"
+)
+
+// userConfig is the config file loaded before every command
+var userConfig = &config.Config{}
+
+// envPrefix is the prefix of the environment variables bound to the global flags
+const envPrefix = "KUBECTL_MULTI_"
+
+// envVarName returns the environment variable bound to a global flag, e.g.
+// KUBECTL_MULTI_REMOTE_CONTEXT for --remote-context
+func envVarName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// explicitFlags are never read from the environment. They lift safety protections and
+// must be given on every command that needs them, rather than once in a shell or CI
+// environment for all later commands.
+var explicitFlags = map[string]bool{
+	"override-safety": true,
+}
+
+// applyEnv sets the global flags that were not given on the command line from their
+// environment variables
+func applyEnv(cmd *cobra.Command) error {
+	var err error
+	cmd.Root().PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if explicitFlags[f.Name] {
+			return
+		}
+		value, ok := os.LookupEnv(envVarName(f.Name))
+		if !ok || err != nil {
+			return
+		}
+		flag := cmd.Flags().Lookup(f.Name)
+		if flag == nil || flag.Changed {
+			return
+		}
+		if setErr := cmd.Flags().Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value for %s: %v", envVarName(f.Name), setErr)
+		}
+	})
+	return err
+}
+
+// configPath returns the path of the config file and whether it was given with --config
+func configPath(cmd *cobra.Command) (string, bool) {
+	if cmd.Flags().Changed("config") {
+		return configFile, true
+	}
+	return config.DefaultPath(), false
+}
+
+// loadUserConfig applies, to every flag of cmd that was not set on the command line,
+// the environment variables, then the selected profile of the config file, then its
+// defaults
+func loadUserConfig(cmd *cobra.Command) error {
+	if err := applyEnv(cmd); err != nil {
+		return err
+	}
+
+	path, explicit := configPath(cmd)
+	cfg, err := config.Load(path, explicit)
+	if err != nil {
+		return err
+	}
+	userConfig = cfg
+	if err := registerConfigColumns(cfg.Columns); err != nil {
+		return err
+	}
+
+	if profileName != "" {
+		profile, err := cfg.Profile(profileName)
+		if err != nil {
+			return err
+		}
+		if err := applySettings(cmd, profile); err != nil {
+			return err
+		}
+	}
+	return applySettings(cmd, cfg.Defaults)
+}
+
+// applySettings sets the flags of cmd that were not given on the command line from
+// settings. Flags the command does not have are ignored.
+func applySettings(cmd *cobra.Command, settings config.Settings) error {
+	values := []struct{ flag, value string }{
+		{"kubeconfig", settings.Kubeconfig},
+		{"remote-context", settings.RemoteContext},
+		{"namespace", settings.Namespace},
+		{"clusters", strings.Join(settings.Clusters, ",")},
+		{"theme", settings.Theme},
+		{"layout", settings.Layout},
+		{"audit-log", settings.AuditLog},
+		{"notify", settings.Notify},
+		{"notify-after", settings.NotifyAfter},
+		{"max-column-width", settings.ColumnWidth},
+		{"compat-check", settings.CompatCheck},
+		{"wds-context", settings.WDSContext},
+	}
+
+	// Other commands use -o for different formats, e.g. apply only knows yaml and json
+	if cmd.Name() == "get" || cmd.Name() == "multiget" {
+		values = append(values, struct{ flag, value string }{"output", settings.Output})
+	}
+
+	for _, v := range values {
+		if v.value == "" {
+			continue
+		}
+		flag := cmd.Flags().Lookup(v.flag)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(v.flag, v.value); err != nil {
+			return fmt.Errorf("invalid config value for %s: %v", v.flag, err)
+		}
+	}
+	return nil
+}
+
+// registerConfigColumns registers the columns of the config file as resource handlers,
+// so get prints them instead of its built-in columns
+func registerConfigColumns(columns map[string][]config.Column) error {
+	for resourceType, configColumns := range columns {
+		handler := multicluster.ResourceHandler{Names: util.ResourceNames(resourceType)}
+		for _, column := range configColumns {
+			var priority int32
+			if column.Wide {
+				priority = 1
+			}
+			handler.Columns = append(handler.Columns, multicluster.Column{
+				Header:   column.Name,
+				JSONPath: column.JSONPath,
+				Priority: priority,
+				Format:   column.Format,
+			})
+		}
+		if err := multicluster.RegisterResourceHandler(handler); err != nil {
+			return fmt.Errorf("invalid columns for %s in config file: %v", resourceType, err)
+		}
+	}
+	return nil
+}
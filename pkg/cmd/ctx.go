@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"kubectl-multi/pkg/config"
+	"kubectl-multi/pkg/util"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newCtxCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ctx",
+		Short: "Save named cluster sets and select one for the shell session",
+		Long: `Save named sets of clusters in the config file and select one as the implicit
+--clusters of the following commands of the shell session.
+
+Cluster sets are the clusterGroups of the config file, so a saved set can also be
+passed to --clusters by name.`,
+		Example: `# Save the production clusters
+kubectl multi ctx save prod --clusters cluster1,cluster2
+
+# List the saved cluster sets
+kubectl multi ctx list
+
+# Target the production clusters in this shell
+eval "$(kubectl multi ctx use prod)"`,
+	}
+	cmd.AddCommand(newCtxSaveCommand())
+	cmd.AddCommand(newCtxListCommand())
+	cmd.AddCommand(newCtxUseCommand())
+	return cmd
+}
+
+func newCtxSaveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "save NAME --clusters CLUSTERS",
+		Short: "Save the clusters given with --clusters as a named cluster set",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleCtxSaveCommand(cmd, args[0])
+		},
+	}
+	return cmd
+}
+
+func newCtxListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the saved cluster sets",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleCtxListCommand()
+		},
+	}
+	return cmd
+}
+
+func newCtxUseCommand() *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "use [NAME]",
+		Short: "Print the shell command that selects a saved cluster set for the session",
+		Long: `Print the export command that makes a saved cluster set the implicit --clusters of
+the following commands. Evaluate it in the shell, since a command cannot change the
+environment of its shell:
+
+  eval "$(kubectl multi ctx use prod)"
+
+--clusters on the command line still wins. Use --unset to target all clusters again.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if unset {
+				fmt.Printf("unset %s\n", envVarName("clusters"))
+				return nil
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("a cluster set name or --unset is required")
+			}
+			return handleCtxUseCommand(args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&unset, "unset", false, "print the command that clears the selected cluster set")
+
+	return cmd
+}
+
+func handleCtxSaveCommand(cmd *cobra.Command, name string) error {
+	if len(clusterNames) == 0 {
+		return fmt.Errorf("no clusters given, pass them with --clusters")
+	}
+
+	// The file is read again so that profile and environment settings are not saved
+	path, explicit := configPath(cmd)
+	cfg, err := config.Load(path, explicit)
+	if err != nil {
+		return err
+	}
+	if cfg.ClusterGroups == nil {
+		cfg.ClusterGroups = make(map[string][]string)
+	}
+	clusters := userConfig.ExpandClusters(clusterNames)
+	cfg.ClusterGroups[name] = clusters
+	if err := config.Save(path, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved cluster set %s: %s\n", name, strings.Join(clusters, ", "))
+	return nil
+}
+
+func handleCtxListCommand() error {
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	if len(userConfig.ClusterGroups) == 0 {
+		fmt.Fprintf(tw, "No resource found.\n")
+		return tw.Flush()
+	}
+
+	names := make([]string, 0, len(userConfig.ClusterGroups))
+	for name := range userConfig.ClusterGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	current := os.Getenv(envVarName("clusters"))
+	fmt.Fprintf(tw, "CURRENT\tNAME\tCLUSTERS\n")
+	for _, name := range names {
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", marker, name, strings.Join(userConfig.ClusterGroups[name], ","))
+	}
+	return tw.Flush()
+}
+
+func handleCtxUseCommand(name string) error {
+	if _, ok := userConfig.ClusterGroups[name]; !ok {
+		return fmt.Errorf("cluster set %q not found in config file", name)
+	}
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Fprintf(os.Stderr, "# Run: eval \"$(kubectl multi ctx use %s)\"\n", name)
+	}
+	fmt.Printf("export %s=%s\n", envVarName("clusters"), name)
+	return nil
+}
@@ -3,8 +3,6 @@ package cmd
 import (
 	"fmt"
 	"strings"
-	"bufio"
-	"os"
 
 	"kubectl-multi/pkg/cluster"
 	"kubectl-multi/pkg/util"
@@ -55,20 +53,23 @@ func newDeleteCommand() *cobra.Command {
 	var filename string
 	var recursive bool
 	var dryRun string
+	var skipAccessCheck bool
 
 	cmd := &cobra.Command{
-		Use:   "delete [TYPE[.VERSION][.GROUP] [NAME | -l label] | TYPE[.VERSION][.GROUP]/NAME ...]",
-		Short: "Delete resources across all managed clusters",
+		Use:         "delete [TYPE[.VERSION][.GROUP] [NAME | -l label] | TYPE[.VERSION][.GROUP]/NAME ...]",
+		Short:       "Delete resources across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
-			return handleDeleteCommand(args, filename, recursive, dryRun, kubeconfig, remoteCtx, namespace, allNamespaces)
+			return handleDeleteCommand(args, filename, recursive, dryRun, skipAccessCheck, kubeconfig, remoteCtx, namespace, allNamespaces)
 		},
 	}
 
 	cmd.Flags().StringVarP(&filename, "filename", "f", "", "filename, directory, or URL to files to use to delete the resource")
 	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "process the directory used in -f, --filename recursively")
 	cmd.Flags().StringVar(&dryRun, "dry-run", "none", "must be \"none\", \"server\", or \"client\"")
+	cmd.Flags().BoolVar(&skipAccessCheck, "skip-access-check", false, "do not check the delete permission in every cluster before deleting")
 
 	// Set custom help function
 	cmd.SetHelpFunc(deleteHelpFunc)
@@ -76,7 +77,7 @@ func newDeleteCommand() *cobra.Command {
 	return cmd
 }
 
-func handleDeleteCommand(args []string, filename string, recursive bool, dryRun, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+func handleDeleteCommand(args []string, filename string, recursive bool, dryRun string, skipAccessCheck bool, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
 
 	var isFileProvided bool
 	var resourceName string
@@ -105,17 +106,35 @@ func handleDeleteCommand(args []string, filename string, recursive bool, dryRun,
 		return fmt.Errorf("no clusters discovered")
 	}
 
-	fmt.Println("Are you sure you want to delete these resources ?")
-	fmt.Println("Type 'yes' to confirm, or anything else to cancel.")
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
+	if err := checkMutationTargets(clusters, remoteCtx); err != nil {
+		return err
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %v", err)
+	// Checked before asking, so the user is not asked about a deletion that would fail
+	if !skipAccessCheck && (dryRun == "none" || dryRun == "") {
+		var checks []accessCheck
+		if isFileProvided {
+			checks, err = manifestAccessChecks(filename, recursive, []string{"delete"})
+		} else {
+			checkType, checkName := resourceType, resourceName
+			if before, after, found := strings.Cut(resourceType, "/"); found {
+				checkType, checkName = before, after
+			}
+			checks = []accessCheck{{Verbs: []string{"delete"}, ResourceType: checkType, Name: checkName}}
+		}
+		if err != nil {
+			return fmt.Errorf("%v (use --skip-access-check to delete without checking)", err)
+		}
+		if err := checkAccess(clusters, remoteCtx, checks, namespace); err != nil {
+			return err
+		}
 	}
 
-	response = strings.TrimSpace(strings.ToLower(response))
-	if response != "yes" {
+	confirmed, err := confirmCommand("delete", clusters, remoteCtx)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
 		fmt.Println("Deletion cancelled...")
 		return nil
 	}
@@ -160,14 +179,7 @@ func handleDeleteCommand(args []string, filename string, recursive bool, dryRun,
 		if namespace != "" {
 			args = append(args, "-n", namespace)
 		}
-		output, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
-		}
-		fmt.Println()
+		runClusterKubectl(cinfo, args, kubeconfig, "delete")
 	}
 
 	// 2. Run for KubeStellar clusters (excluding ITS and current)
@@ -190,19 +202,12 @@ func handleDeleteCommand(args []string, filename string, recursive bool, dryRun,
 		if namespace != "" {
 			args = append(args, "-n", namespace)
 		}
-		output, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", c.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
-		}
-		fmt.Println()
+		runClusterKubectl(c, args, kubeconfig, "delete")
 	}
 
 	// 3. Print warning for ITS (control) cluster
 	if cinfo, ok := contextToCluster[itsContext]; ok {
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
+		printClusterHeader(cinfo.Context)
 		fmt.Printf("Cannot perform this operation on ITS (control) cluster: %s\n", cinfo.Context)
 		fmt.Println()
 	}
@@ -212,8 +217,9 @@ func handleDeleteCommand(args []string, filename string, recursive bool, dryRun,
 
 func newExecCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "exec POD [-c CONTAINER] -- COMMAND [args...]",
-		Short: "Execute a command in a container across managed clusters",
+		Use:         "exec POD [-c CONTAINER] -- COMMAND [args...]",
+		Short:       "Execute a command in a container across managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("exec command not yet implemented")
 		},
@@ -223,8 +229,9 @@ func newExecCommand() *cobra.Command {
 
 func newCreateCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "create -f FILENAME",
-		Short: "Create a resource from a file or from stdin across managed clusters",
+		Use:         "create -f FILENAME",
+		Short:       "Create a resource from a file or from stdin across managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("create command not yet implemented")
 		},
@@ -232,21 +239,11 @@ func newCreateCommand() *cobra.Command {
 	return cmd
 }
 
-func newEditCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "edit [TYPE[.VERSION][.GROUP]/]NAME",
-		Short: "Edit a resource on the server across managed clusters",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return fmt.Errorf("edit command not yet implemented")
-		},
-	}
-	return cmd
-}
-
 func newPatchCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "patch [TYPE[.VERSION][.GROUP]/]NAME --patch PATCH",
-		Short: "Update field(s) of a resource across managed clusters",
+		Use:         "patch [TYPE[.VERSION][.GROUP]/]NAME --patch PATCH",
+		Short:       "Update field(s) of a resource across managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("patch command not yet implemented")
 		},
@@ -256,8 +253,9 @@ func newPatchCommand() *cobra.Command {
 
 func newScaleCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "scale [TYPE[.VERSION][.GROUP]/]NAME --replicas=COUNT",
-		Short: "Set a new size for a deployment, replica set, or stateful set across managed clusters",
+		Use:         "scale [TYPE[.VERSION][.GROUP]/]NAME --replicas=COUNT",
+		Short:       "Set a new size for a deployment, replica set, or stateful set across managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("scale command not yet implemented")
 		},
@@ -275,14 +273,3 @@ func newPortForwardCommand() *cobra.Command {
 	}
 	return cmd
 }
-
-func newTopCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "top [TYPE]",
-		Short: "Display resource (CPU/memory/storage) usage across managed clusters",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return fmt.Errorf("top command not yet implemented")
-		},
-	}
-	return cmd
-}
@@ -34,9 +34,14 @@ type InstallOptions struct {
 	// Installation options
 	InstallPCHs bool
 	DryRun      bool
+	Check       bool
+	Demo        bool
 	Wait        bool
 	Timeout     string
 	Verbosity   int
+
+	// Kubeconfig is the --kubeconfig the demo tools create and use their contexts in
+	Kubeconfig string
 }
 
 func NewInstallOptions(streams genericclioptions.IOStreams) *InstallOptions {
@@ -64,8 +69,9 @@ func NewInstallCmd(streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewInstallOptions(streams)
 
 	cmd := &cobra.Command{
-		Use:   "install",
-		Short: "Install KubeStellar core components using Helm chart",
+		Use:         "install",
+		Short:       "Install KubeStellar core components using Helm chart",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		Long: `Install KubeStellar core components using the official Helm chart.
 
 This command simplifies the installation of KubeStellar by providing a more
@@ -92,11 +98,25 @@ Examples:
   kubectl multi install --version v0.28.0
   
   # Dry run to see what would be installed
-  kubectl multi install --dry-run --its its1 --wds wds1`,
+  kubectl multi install --dry-run --its its1 --wds wds1
+
+  # Create a local demo environment: kind clusters, KubeStellar and two registered WECs
+  kubectl multi install --demo
+
+  # Check the prerequisites without installing
+  kubectl multi install --check --its its1 --wds wds1`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if o.Check {
+				kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+				return o.RunChecks(kubeconfig, remoteCtx)
+			}
 			if err := o.Validate(); err != nil {
 				return err
 			}
+			if o.Demo {
+				o.Kubeconfig, _, _, _, _ = GetGlobalFlags()
+				return o.RunDemo(cmd.Context())
+			}
 			return o.Run(cmd.Context())
 		},
 	}
@@ -123,6 +143,8 @@ Examples:
 	// Installation flags
 	cmd.Flags().BoolVar(&o.InstallPCHs, "install-pchs", o.InstallPCHs, "Install Post Create Hooks")
 	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Show what would be installed without actually installing")
+	cmd.Flags().BoolVar(&o.Check, "check", o.Check, "Check kubectl, helm, the kubeconfig, the control planes and RBAC instead of installing")
+	cmd.Flags().BoolVar(&o.Demo, "demo", o.Demo, "Create kind clusters (hosting and cluster1, cluster2), install KubeStellar and register the clusters")
 	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "Wait for installation to complete")
 	cmd.Flags().StringVar(&o.Timeout, "timeout", o.Timeout, "Timeout for installation")
 
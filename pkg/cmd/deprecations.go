@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// deprecatedAPI describes an API version that is deprecated or removed for a resource
+type deprecatedAPI struct {
+	GroupVersion string
+	Resource     string
+	Kind         string
+	RemovedIn    int // Kubernetes 1.x minor version in which the API is no longer served
+	Replacement  string
+}
+
+// deprecatedAPIs lists the deprecated API versions of the most commonly used kinds
+var deprecatedAPIs = []deprecatedAPI{
+	{"extensions/v1beta1", "deployments", "Deployment", 16, "apps/v1"},
+	{"extensions/v1beta1", "daemonsets", "DaemonSet", 16, "apps/v1"},
+	{"extensions/v1beta1", "replicasets", "ReplicaSet", 16, "apps/v1"},
+	{"extensions/v1beta1", "networkpolicies", "NetworkPolicy", 16, "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "ingresses", "Ingress", 22, "networking.k8s.io/v1"},
+	{"apps/v1beta1", "deployments", "Deployment", 16, "apps/v1"},
+	{"apps/v1beta1", "statefulsets", "StatefulSet", 16, "apps/v1"},
+	{"apps/v1beta2", "deployments", "Deployment", 16, "apps/v1"},
+	{"apps/v1beta2", "statefulsets", "StatefulSet", 16, "apps/v1"},
+	{"apps/v1beta2", "daemonsets", "DaemonSet", 16, "apps/v1"},
+	{"apps/v1beta2", "replicasets", "ReplicaSet", 16, "apps/v1"},
+	{"networking.k8s.io/v1beta1", "ingresses", "Ingress", 22, "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "ingressclasses", "IngressClass", 22, "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "roles", "Role", 22, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "rolebindings", "RoleBinding", 22, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "clusterroles", "ClusterRole", 22, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "clusterrolebindings", "ClusterRoleBinding", 22, "rbac.authorization.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "customresourcedefinitions", "CustomResourceDefinition", 22, "apiextensions.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "mutatingwebhookconfigurations", "MutatingWebhookConfiguration", 22, "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "validatingwebhookconfigurations", "ValidatingWebhookConfiguration", 22, "admissionregistration.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "priorityclasses", "PriorityClass", 22, "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "storageclasses", "StorageClass", 22, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "csidrivers", "CSIDriver", 22, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "csistoragecapacities", "CSIStorageCapacity", 27, "storage.k8s.io/v1"},
+	{"batch/v1beta1", "cronjobs", "CronJob", 25, "batch/v1"},
+	{"policy/v1beta1", "poddisruptionbudgets", "PodDisruptionBudget", 25, "policy/v1"},
+	{"policy/v1beta1", "podsecuritypolicies", "PodSecurityPolicy", 25, "<none>"},
+	{"discovery.k8s.io/v1beta1", "endpointslices", "EndpointSlice", 25, "discovery.k8s.io/v1"},
+	{"autoscaling/v2beta1", "horizontalpodautoscalers", "HorizontalPodAutoscaler", 25, "autoscaling/v2"},
+	{"autoscaling/v2beta2", "horizontalpodautoscalers", "HorizontalPodAutoscaler", 26, "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "flowschemas", "FlowSchema", 26, "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "flowschemas", "FlowSchema", 29, "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "flowschemas", "FlowSchema", 32, "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// deprecatedObject is an object that was written through a deprecated API version
+type deprecatedObject struct {
+	API       deprecatedAPI
+	Namespace string
+	Name      string
+}
+
+func newDeprecationsCommand() *cobra.Command {
+	var targetVersion string
+
+	cmd := &cobra.Command{
+		Use:   "deprecations",
+		Short: "Find objects using deprecated or removed API versions across managed clusters",
+		Long: `Find objects that were created or updated through deprecated or removed API
+versions on every managed cluster, and report which clusters are at risk for a
+Kubernetes upgrade.
+
+An object is reported when its managed fields or its last-applied-configuration
+annotation record a deprecated apiVersion, which means the manifests or clients
+that manage it still need to be migrated.
+
+By default each cluster is checked against the next minor release of its
+own server version; use --target-version to check the whole fleet against a
+specific release.`,
+		Example: `# Find deprecated API usage across all clusters
+kubectl multi deprecations
+
+# Check which clusters are ready to be upgraded to Kubernetes 1.29
+kubectl multi deprecations --target-version v1.29`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleDeprecationsCommand(targetVersion, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&targetVersion, "target-version", "", "Kubernetes version to check the clusters against (e.g. v1.29)")
+
+	return cmd
+}
+
+func handleDeprecationsCommand(targetVersion, kubeconfig, remoteCtx string) error {
+	targetMinor := 0
+	if targetVersion != "" {
+		minor, err := parseMinorVersion(targetVersion)
+		if err != nil {
+			return fmt.Errorf("invalid --target-version: %v", err)
+		}
+		targetMinor = minor
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tAPI VERSION\tKIND\tNAMESPACE\tNAME\tREMOVED IN\tREPLACEMENT\n")
+
+	atRisk := make(map[string][]string)
+	var clusterOrder []string
+	found := false
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil || clusterInfo.DynamicClient() == nil {
+			continue
+		}
+
+		version, err := clusterInfo.Client().Discovery().ServerVersion()
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "get server version")
+			continue
+		}
+		clusterTarget := targetMinor
+		if clusterTarget == 0 {
+			current, err := parseMinorVersion(version.GitVersion)
+			if err != nil {
+				printWarning("cannot parse server version %q of cluster %s", version.GitVersion, clusterInfo.Name)
+				continue
+			}
+			clusterTarget = current + 1
+		}
+		clusterOrder = append(clusterOrder, fmt.Sprintf("%s (%s -> 1.%d)", clusterInfo.Name, version.GitVersion, clusterTarget))
+
+		objects := findDeprecatedObjects(clusterInfo)
+		for _, obj := range objects {
+			found = true
+			namespace := obj.Namespace
+			if namespace == "" {
+				namespace = "<none>"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t1.%d\t%s\n",
+				clusterInfo.Name, obj.API.GroupVersion, obj.API.Kind, namespace, obj.Name, obj.API.RemovedIn, obj.API.Replacement)
+			if obj.API.RemovedIn <= clusterTarget {
+				key := clusterOrder[len(clusterOrder)-1]
+				atRisk[key] = append(atRisk[key], fmt.Sprintf("%s %s", obj.API.GroupVersion, obj.API.Kind))
+			}
+		}
+	}
+
+	if !found {
+		fmt.Fprintf(tw, "No objects using deprecated API versions found.\n")
+	}
+	tw.Flush()
+
+	fmt.Println()
+	for _, name := range clusterOrder {
+		apis := uniqueSorted(atRisk[name])
+		if len(apis) == 0 {
+			fmt.Printf("✅ %s: ready for upgrade\n", name)
+		} else {
+			fmt.Printf("⚠️  %s: at risk, %d object(s) use removed APIs: %s\n", name, len(atRisk[name]), strings.Join(apis, ", "))
+		}
+	}
+
+	return nil
+}
+
+// findDeprecatedObjects lists the kinds covered by deprecatedAPIs through their
+// replacement version and reports objects whose writers used a deprecated version
+func findDeprecatedObjects(clusterInfo cluster.ClusterInfo) []deprecatedObject {
+	byResource := make(map[string][]deprecatedAPI)
+	var resources []string
+	for _, api := range deprecatedAPIs {
+		// Objects are listed through the group that replaces the deprecated one, so that
+		// e.g. deployments written through extensions/v1beta1 are found under apps
+		group := api.GroupVersion
+		if api.Replacement != "<none>" {
+			group = api.Replacement
+		}
+		gv, _ := schema.ParseGroupVersion(group)
+		key := api.Resource + "." + gv.Group
+		if _, ok := byResource[key]; !ok {
+			resources = append(resources, key)
+		}
+		byResource[key] = append(byResource[key], api)
+	}
+
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	var objects []deprecatedObject
+	for _, key := range resources {
+		apis := byResource[key]
+		gvr, _, err := util.DiscoverGVR(clusterInfo.DiscoveryClient(), key)
+		if err != nil {
+			continue
+		}
+
+		list, err := clusterInfo.DynamicClient().Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			continue
+		}
+
+		for _, item := range list.Items {
+			used := objectAPIVersions(item)
+			for _, api := range apis {
+				if used[api.GroupVersion] {
+					objects = append(objects, deprecatedObject{API: api, Namespace: item.GetNamespace(), Name: item.GetName()})
+				}
+			}
+		}
+	}
+	return objects
+}
+
+// objectAPIVersions returns the API versions recorded by the writers of an object
+func objectAPIVersions(item unstructured.Unstructured) map[string]bool {
+	used := make(map[string]bool)
+	for _, entry := range item.GetManagedFields() {
+		used[entry.APIVersion] = true
+	}
+	if lastApplied, ok := item.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+		var applied struct {
+			APIVersion string `json:"apiVersion"`
+		}
+		if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil && applied.APIVersion != "" {
+			used[applied.APIVersion] = true
+		}
+	}
+	return used
+}
+
+// parseMinorVersion extracts the minor version from strings like v1.28.3, 1.29 or v1.27.2+k3s1
+func parseMinorVersion(version string) (int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, fmt.Errorf("unsupported version %q", version)
+	}
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	return strconv.Atoi(minor)
+}
+
+func uniqueSorted(items []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
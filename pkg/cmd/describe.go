@@ -3,13 +3,19 @@ package cmd
 import (
 	"bytes"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/describe"
 
 	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/multicluster"
 	"kubectl-multi/pkg/util"
 )
 
@@ -35,6 +41,15 @@ kubectl multi describe pod nginx
 # Describe all pods with a specific label across all clusters
 kubectl multi describe pods -l app=nginx
 
+# Describe at most 5 pods of a misbehaving app per cluster
+kubectl multi describe pods -l app=api --max 5
+
+# Count the warning events of a deployment in every cluster
+kubectl multi describe deployment/nginx -o json | jq '.cluster + " " + (.objects[0].summary.warningEvents | tostring)'
+
+# Find out why a deployment works in cluster1 but not in cluster2
+kubectl multi describe deploy/foo --compare cluster1,cluster2
+
 # Describe a service across all clusters
 kubectl multi describe service/my-service
 
@@ -53,6 +68,9 @@ func newDescribeCommand() *cobra.Command {
 	var selector string
 	var showEvents bool
 	var chunkSize int
+	var max int
+	var outputFormat string
+	var compare []string
 
 	cmd := &cobra.Command{
 		Use:   "describe [TYPE[.VERSION][.GROUP] [NAME_PREFIX | -l label] | TYPE[.VERSION][.GROUP]/NAME]",
@@ -66,18 +84,28 @@ kubectl multi describe pod nginx
 # Describe all pods with a specific label across all clusters
 kubectl multi describe pods -l app=nginx
 
+# Describe at most 5 pods of a misbehaving app per cluster
+kubectl multi describe pods -l app=api --max 5
+
+# Count the warning events of a deployment in every cluster
+kubectl multi describe deployment/nginx -o json | jq '.cluster + " " + (.objects[0].summary.warningEvents | tostring)'
+
+# Find out why a deployment works in cluster1 but not in cluster2
+kubectl multi describe deploy/foo --compare cluster1,cluster2
+
 # Describe a service across all clusters
 kubectl multi describe service/my-service
 
 # Describe nodes across all clusters
 kubectl multi describe nodes`,
+		ValidArgsFunction: completeResourceTypes,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("resource type must be specified")
 			}
 
 			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
-			return handleDescribeCommand(args, selector, showEvents, chunkSize, kubeconfig, remoteCtx, namespace, allNamespaces)
+			return handleDescribeCommand(args, selector, showEvents, chunkSize, max, outputFormat, compare, kubeconfig, remoteCtx, namespace, allNamespaces)
 		},
 	}
 
@@ -85,6 +113,9 @@ kubectl multi describe nodes`,
 	cmd.Flags().StringVarP(&selector, "selector", "l", "", "selector (label query) to filter on, supports '=', '==', '!=', 'in', 'notin'")
 	cmd.Flags().BoolVar(&showEvents, "show-events", true, "if true, display events related to the described object")
 	cmd.Flags().IntVar(&chunkSize, "chunk-size", 500, "return large lists in chunks rather than all at once")
+	cmd.Flags().IntVar(&max, "max", 20, "describe at most this many objects per cluster, 0 for no limit")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "output format (json|yaml): a document per cluster with the objects, their events and summaries")
+	cmd.Flags().StringSliceVar(&compare, "compare", nil, "CLUSTER1,CLUSTER2: print the differences of the descriptions in the two clusters as a unified diff")
 
 	// Set custom help function
 	cmd.SetHelpFunc(describeHelpFunc)
@@ -92,7 +123,15 @@ kubectl multi describe nodes`,
 	return cmd
 }
 
-func handleDescribeCommand(args []string, selector string, showEvents bool, chunkSize int, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+func handleDescribeCommand(args []string, selector string, showEvents bool, chunkSize, max int, outputFormat string, compare []string, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	resourceType, names, err := parseDescribeArgs(args)
+	if err != nil {
+		return err
+	}
+	if outputFormat != "" && outputFormat != "json" && outputFormat != "yaml" {
+		return fmt.Errorf("unsupported output format %q, describe supports json and yaml", outputFormat)
+	}
+
 	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
 	if err != nil {
 		return fmt.Errorf("failed to discover clusters: %v", err)
@@ -102,32 +141,50 @@ func handleDescribeCommand(args []string, selector string, showEvents bool, chun
 		return fmt.Errorf("no clusters discovered")
 	}
 
-	// Parse resource type and name from args
-	resourceType := args[0]
-	// Note: resourceName is not currently used but kept for future enhancement
-	// resourceName := ""
-	// if len(args) > 1 {
-	// 	resourceName = args[1]
-	// }
+	if len(compare) > 0 {
+		return compareDescriptions(clusters, compare, resourceType, names, selector, showEvents, chunkSize, max, outputFormat, namespace, allNamespaces)
+	}
+	if outputFormat != "" {
+		return printDescribeDocuments(clusters, resourceType, names, selector, showEvents, chunkSize, max, outputFormat, namespace, allNamespaces)
+	}
 
-	fmt.Printf("Describing %s across %d clusters...\n\n", resourceType, len(clusters))
+	if !quiet {
+		fmt.Printf("Describing %s across %d clusters...\n\n", resourceType, len(clusters))
+	}
 
 	// Track if any cluster had successful output
 	anyOutput := false
+	resolver := util.NewGVRResolver(resourceType)
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
-			fmt.Printf("Warning: skipping cluster %s (no client available)\n", clusterInfo.Name)
+		if clusterInfo.Client() == nil {
+			printWarning("skipping cluster %s (no client available)", clusterInfo.Name)
 			continue
 		}
 
-		fmt.Printf("=== Cluster: %s (Context: %s) ===\n", clusterInfo.Name, clusterInfo.Context)
-
-		// Build kubectl describe command
-		kubectlArgs := buildDescribeArgs(args, selector, showEvents, chunkSize, namespace, allNamespaces, clusterInfo.Name)
+		if !mergedLayout() {
+			printClusterHeader(fmt.Sprintf("%s (Context: %s)%s", clusterInfo.Name, clusterInfo.Context, namespaceNote(clusterInfo, namespace, allNamespaces)))
+		}
 
-		// Execute kubectl describe for this cluster
-		output, err := executeKubectlDescribe(kubectlArgs, kubeconfig, clusterInfo.Name)
+		var output string
+		if handler, ok := multicluster.LookupResourceHandler(resourceType); ok && handler.Describe != nil {
+			// Registered handlers describe the objects themselves
+			resourceName := ""
+			if len(names) > 0 {
+				resourceName = names[0]
+			}
+			ctx, cancel := clusterInfo.RequestContext()
+			output, err = handler.Describe(ctx, clusterInfo, clusterInfo.TargetNamespace(namespace), resourceName)
+			cancel()
+			if err == nil && showEvents && resourceName != "" && strings.TrimSpace(output) != "" {
+				var events string
+				if events, err = describeEvents(clusterInfo, resolver, clusterInfo.TargetNamespace(namespace), resourceName); err == nil {
+					output = strings.TrimRight(output, "\n") + "\n" + events
+				}
+			}
+		} else {
+			output, err = describeObjects(clusterInfo, resolver, names, selector, showEvents, chunkSize, max, clusterInfo.TargetNamespace(namespace), allNamespaces)
+		}
 		if err != nil {
 			fmt.Printf("Error describing %s in cluster %s: %v\n", resourceType, clusterInfo.Name, err)
 			fmt.Printf("\n")
@@ -136,13 +193,19 @@ func handleDescribeCommand(args []string, selector string, showEvents bool, chun
 
 		// If we got output, display it
 		if strings.TrimSpace(output) != "" {
-			fmt.Print(output)
+			if mergedLayout() {
+				printPrefixed(clusterInfo.Name, output)
+			} else {
+				fmt.Print(output)
+			}
 			anyOutput = true
 		} else {
 			fmt.Printf("No %s found in cluster %s\n", resourceType, clusterInfo.Name)
 		}
 
-		fmt.Printf("\n")
+		if !mergedLayout() {
+			fmt.Printf("\n")
+		}
 	}
 
 	if !anyOutput {
@@ -152,80 +215,174 @@ func handleDescribeCommand(args []string, selector string, showEvents bool, chun
 	return nil
 }
 
-// buildDescribeArgs constructs the kubectl describe command arguments
-func buildDescribeArgs(args []string, selector string, showEvents bool, chunkSize int, namespace string, allNamespaces bool, clusterContext string) []string {
-	var kubectlArgs []string
-
-	// Add the describe command and resource type
-	kubectlArgs = append(kubectlArgs, "describe")
-	kubectlArgs = append(kubectlArgs, args...)
-
-	// Add selector if specified
-	if selector != "" {
-		kubectlArgs = append(kubectlArgs, "-l", selector)
+// parseDescribeArgs accepts TYPE [NAME_PREFIX...] or TYPE/NAME arguments of one type
+func parseDescribeArgs(args []string) (string, []string, error) {
+	if !strings.Contains(args[0], "/") {
+		return args[0], args[1:], nil
 	}
-
-	// Add namespace flags
-	if allNamespaces {
-		kubectlArgs = append(kubectlArgs, "-A")
-	} else if namespace != "" {
-		kubectlArgs = append(kubectlArgs, "-n", namespace)
+	var resourceType string
+	var names []string
+	for _, arg := range args {
+		before, after, found := strings.Cut(arg, "/")
+		if !found || before == "" || after == "" {
+			return "", nil, fmt.Errorf("expected TYPE/NAME, got %q", arg)
+		}
+		if resourceType != "" && before != resourceType {
+			return "", nil, fmt.Errorf("all TYPE/NAME arguments must have the same type, got %s and %s", resourceType, before)
+		}
+		resourceType = before
+		names = append(names, after)
 	}
+	return resourceType, names, nil
+}
 
-	// Add show-events flag
-	if !showEvents {
-		kubectlArgs = append(kubectlArgs, "--show-events=false")
+// describeObjects describes objects of one cluster with the describers of kubectl, so
+// the output matches kubectl describe, including events, conditions and volumes. Types
+// without a specific describer, like custom resources, get genericDescriber. Names
+// that match no object are used as prefixes, like kubectl does; without names the
+// objects matching the selector are described. An empty string means no object matched.
+func describeObjects(clusterInfo cluster.ClusterInfo, resolver *util.GVRResolver, names []string, selector string, showEvents bool, chunkSize, max int, namespace string, allNamespaces bool) (string, error) {
+	if clusterInfo.RestConfig == nil {
+		return "", fmt.Errorf("no REST config available")
 	}
-
-	// Add chunk-size flag
-	if chunkSize != 500 {
-		kubectlArgs = append(kubectlArgs, "--chunk-size", fmt.Sprintf("%d", chunkSize))
+	gvr, namespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+	if err != nil {
+		return "", err
+	}
+	var describer describe.ResourceDescriber = genericDescriber{clusterInfo: clusterInfo, gvr: gvr}
+	if gvk, err := util.NewRESTMapper(clusterInfo.DiscoveryClient()).KindFor(gvr); err == nil {
+		if specific, ok := describe.DescriberFor(gvk.GroupKind(), clusterInfo.RestConfig); ok {
+			describer = specific
+		}
 	}
 
-	// Add context for this specific cluster
-	kubectlArgs = append(kubectlArgs, "--context", clusterContext)
+	objects, matched, err := describeTargets(clusterInfo, gvr, namespaced, names, selector, chunkSize, max, namespace, allNamespaces)
+	if err != nil {
+		return "", err
+	}
+	if matched > len(objects) {
+		printWarning("%d %s match in cluster %s, describing the first %d (raise --max to describe more)", matched, gvr.Resource, clusterInfo.Name, len(objects))
+	}
 
-	return kubectlArgs
+	settings := describe.DescriberSettings{ShowEvents: showEvents, ChunkSize: int64(chunkSize)}
+	var descriptions []string
+	for _, obj := range objects {
+		description, err := describer.Describe(obj.GetNamespace(), obj.GetName(), settings)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", fmt.Errorf("failed to describe %s: %v", obj.GetName(), err)
+		}
+		descriptions = append(descriptions, description)
+	}
+	return strings.Join(descriptions, "\n\n"), nil
 }
 
-// executeKubectlDescribe executes kubectl describe command for a specific cluster
-func executeKubectlDescribe(args []string, kubeconfig, clusterName string) (string, error) {
-	// Create the command
-	cmd := exec.Command("kubectl", args...)
-
-	// Set environment variables
-	cmd.Env = os.Environ()
-	if kubeconfig != "" {
-		cmd.Env = append(cmd.Env, "KUBECONFIG="+kubeconfig)
+// describeTargets returns the objects to describe: each name exactly or else as a
+// prefix, or all objects matching the selector. Across namespaces names are only
+// matched against the listed objects. At most max objects are returned when max is
+// above 0, so a broad selector does not flood the terminal, together with the number
+// of objects that matched.
+func describeTargets(clusterInfo cluster.ClusterInfo, gvr schema.GroupVersionResource, namespaced bool, names []string, selector string, chunkSize, max int, namespace string, allNamespaces bool) ([]unstructured.Unstructured, int, error) {
+	acrossNamespaces := namespaced && allNamespaces
+	if !namespaced || allNamespaces {
+		namespace = metav1.NamespaceAll
 	}
+	objects, err := matchDescribeTargets(clusterInfo, gvr, names, selector, chunkSize, namespace, acrossNamespaces)
+	if err != nil {
+		return nil, 0, err
+	}
+	matched := len(objects)
+	if max > 0 && matched > max {
+		objects = objects[:max]
+	}
+	return objects, matched, nil
+}
 
-	// Capture stdout and stderr
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	// Execute the command
-	err := cmd.Run()
+func matchDescribeTargets(clusterInfo cluster.ClusterInfo, gvr schema.GroupVersionResource, names []string, selector string, chunkSize int, namespace string, acrossNamespaces bool) ([]unstructured.Unstructured, error) {
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+	resource := clusterInfo.DynamicClient().Resource(gvr).Namespace(namespace)
 
-	// Get the output
-	output := stdout.String()
-	stderrOutput := stderr.String()
+	var listed []unstructured.Unstructured
+	listAll := func() error {
+		if listed != nil {
+			return nil
+		}
+		listed = []unstructured.Unstructured{}
+		opts := metav1.ListOptions{LabelSelector: selector, Limit: int64(chunkSize)}
+		for {
+			list, err := resource.List(ctx, opts)
+			if err != nil {
+				return err
+			}
+			listed = append(listed, list.Items...)
+			if opts.Continue = list.GetContinue(); opts.Continue == "" {
+				return nil
+			}
+		}
+	}
 
-	// Handle different types of errors
-	if err != nil {
-		// Check if it's a "not found" error (which is expected for some resources)
-		if strings.Contains(stderrOutput, "not found") || strings.Contains(stderrOutput, "No resources found") {
-			return "", nil // Return empty string for not found, not an error
+	if len(names) == 0 {
+		if err := listAll(); err != nil {
+			return nil, err
 		}
+		return listed, nil
+	}
 
-		// For other errors, return the error with context
-		return "", fmt.Errorf("kubectl command failed: %v\nStderr: %s", err, stderrOutput)
+	var objects []unstructured.Unstructured
+	for _, name := range names {
+		if !acrossNamespaces {
+			obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+			if err == nil {
+				objects = append(objects, *obj)
+				continue
+			}
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+		}
+		if err := listAll(); err != nil {
+			return nil, err
+		}
+		for _, obj := range listed {
+			if strings.HasPrefix(obj.GetName(), name) {
+				objects = append(objects, obj)
+			}
+		}
 	}
+	return objects, nil
+}
 
-	// If we got stderr output but no error, it might be warnings
-	if stderrOutput != "" && !strings.Contains(stderrOutput, "not found") {
-		output = stderrOutput + "\n" + output
+// describeEvents renders the events of one object like kubectl describe, for the
+// descriptions of registered handlers. The events are read from the cluster of the
+// object, as events are not propagated between clusters.
+func describeEvents(clusterInfo cluster.ClusterInfo, resolver *util.GVRResolver, namespace, name string) (string, error) {
+	gvr, namespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+	if err != nil {
+		return "", err
+	}
+	gvk, err := util.NewRESTMapper(clusterInfo.DiscoveryClient()).KindFor(gvr)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve kind of %s: %v", gvr.String(), err)
+	}
+	selector := fields.Set{"involvedObject.kind": gvk.Kind, "involvedObject.name": name}
+	if !namespaced {
+		namespace = metav1.NamespaceAll
+	} else {
+		selector["involvedObject.namespace"] = namespace
 	}
 
-	return output, nil
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+	events, err := clusterInfo.Client().CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.AsSelector().String()})
+	if err != nil {
+		return "", fmt.Errorf("failed to list events: %v", err)
+	}
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	describe.DescribeEvents(events, describe.NewPrefixWriter(tw))
+	tw.Flush()
+	return buf.String(), nil
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// compareDescriptions prints the unified diff of the descriptions of the same objects
+// in two clusters. With -o yaml the objects are compared instead, including their
+// status, without the fields the API server sets on every object.
+func compareDescriptions(clusters []cluster.ClusterInfo, compare []string, resourceType string, names []string, selector string, showEvents bool, chunkSize, max int, outputFormat, namespace string, allNamespaces bool) error {
+	if len(compare) != 2 || compare[0] == compare[1] {
+		return fmt.Errorf("--compare expects two different clusters, e.g. --compare cluster1,cluster2")
+	}
+	if outputFormat != "" && outputFormat != "yaml" {
+		return fmt.Errorf("--compare compares the descriptions or, with -o yaml, the objects")
+	}
+	byName := make(map[string]cluster.ClusterInfo, len(clusters))
+	for _, clusterInfo := range clusters {
+		byName[clusterInfo.Name] = clusterInfo
+	}
+
+	resolver := util.NewGVRResolver(resourceType)
+	var texts [2]string
+	for i, name := range compare {
+		clusterInfo, ok := byName[name]
+		if !ok || clusterInfo.Client() == nil {
+			return fmt.Errorf("cluster %s is not among the discovered clusters", name)
+		}
+		var err error
+		if outputFormat == "yaml" {
+			texts[i], err = comparedObjects(clusterInfo, resolver, names, selector, chunkSize, max, clusterInfo.TargetNamespace(namespace), allNamespaces)
+		} else {
+			texts[i], err = describeObjects(clusterInfo, resolver, names, selector, showEvents, chunkSize, max, clusterInfo.TargetNamespace(namespace), allNamespaces)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to describe %s in cluster %s: %v", resourceType, name, err)
+		}
+		if strings.TrimSpace(texts[i]) == "" {
+			return fmt.Errorf("no %s found in cluster %s", resourceType, name)
+		}
+	}
+
+	diff := util.UnifiedDiff(compare[0], compare[1], texts[0], texts[1], 3)
+	if diff == "" {
+		fmt.Printf("No differences between clusters %s and %s\n", compare[0], compare[1])
+		return nil
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		fmt.Println(util.ColorizeDiffLine(line))
+	}
+	return nil
+}
+
+// comparedObjects returns the described objects of a cluster as YAML documents,
+// without the metadata that differs between any two objects
+func comparedObjects(clusterInfo cluster.ClusterInfo, resolver *util.GVRResolver, names []string, selector string, chunkSize, max int, namespace string, allNamespaces bool) (string, error) {
+	gvr, namespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+	if err != nil {
+		return "", err
+	}
+	objects, _, err := describeTargets(clusterInfo, gvr, namespaced, names, selector, chunkSize, max, namespace, allNamespaces)
+	if err != nil {
+		return "", err
+	}
+	var documents []string
+	for i := range objects {
+		obj := &objects[i]
+		redactSecret(obj)
+		for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink"} {
+			unstructured.RemoveNestedField(obj.Object, "metadata", field)
+		}
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+		documents = append(documents, string(data))
+	}
+	return strings.Join(documents, "---\n"), nil
+}
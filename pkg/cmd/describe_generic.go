@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/describe"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// genericDescriber describes the objects of types kubectl has no describer for, such
+// as the KubeStellar and other custom resources: the metadata, the spec and the other
+// fields as a tree, the status with its conditions as a table, and the events
+type genericDescriber struct {
+	clusterInfo cluster.ClusterInfo
+	gvr         schema.GroupVersionResource
+}
+
+func (d genericDescriber) Describe(namespace, name string, settings describe.DescriberSettings) (string, error) {
+	ctx, cancel := d.clusterInfo.RequestContext()
+	obj, err := d.clusterInfo.DynamicClient().Resource(d.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	cancel()
+	if err != nil {
+		return "", err
+	}
+	var events *corev1.EventList
+	if settings.ShowEvents {
+		// Like kubectl, a description without events is better than none
+		if items, err := objectEvents(d.clusterInfo, obj); err == nil {
+			events = &corev1.EventList{Items: items}
+		}
+	}
+	return describeGeneric(obj, events), nil
+}
+
+// describeGeneric renders an object like the describers of kubectl; events are left
+// out when nil
+func describeGeneric(obj *unstructured.Unstructured, events *corev1.EventList) string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	w := describe.NewPrefixWriter(tw)
+
+	w.Write(describe.LEVEL_0, "Name:\t%s\n", obj.GetName())
+	if obj.GetNamespace() != "" {
+		w.Write(describe.LEVEL_0, "Namespace:\t%s\n", obj.GetNamespace())
+	}
+	printStringMap(w, "Labels", obj.GetLabels())
+	printStringMap(w, "Annotations", obj.GetAnnotations())
+	w.Write(describe.LEVEL_0, "API Version:\t%s\n", obj.GetAPIVersion())
+	w.Write(describe.LEVEL_0, "Kind:\t%s\n", obj.GetKind())
+	w.Write(describe.LEVEL_0, "Created:\t%s\n", obj.GetCreationTimestamp().Time.Format(time.RFC1123Z))
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		w.Write(describe.LEVEL_0, "Controlled By:\t%s/%s\n", owner.Kind, owner.Name)
+	}
+
+	// The spec first, then the other fields of types without a spec, like data
+	if spec, ok := obj.Object["spec"].(map[string]interface{}); ok {
+		w.Write(describe.LEVEL_0, "Spec:\n")
+		printTree(w, describe.LEVEL_1, spec, false)
+	}
+	var others []string
+	for key := range obj.Object {
+		switch key {
+		case "apiVersion", "kind", "metadata", "spec", "status":
+		default:
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+	for _, key := range others {
+		printTree(w, describe.LEVEL_0, map[string]interface{}{strings.ToUpper(key[:1]) + key[1:]: obj.Object[key]}, false)
+	}
+
+	if status, ok := obj.Object["status"].(map[string]interface{}); ok {
+		rest := make(map[string]interface{}, len(status))
+		for key, value := range status {
+			if key != "conditions" {
+				rest[key] = value
+			}
+		}
+		if len(rest) > 0 {
+			w.Write(describe.LEVEL_0, "Status:\n")
+			printTree(w, describe.LEVEL_1, rest, false)
+		}
+		if conditions, ok := status["conditions"].([]interface{}); ok && len(conditions) > 0 {
+			w.Write(describe.LEVEL_0, "Conditions:\n")
+			w.Write(describe.LEVEL_1, "Type\tStatus\tReason\tAge\tMessage\n")
+			w.Write(describe.LEVEL_1, "----\t------\t------\t---\t-------\n")
+			for _, c := range conditions {
+				condition, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				age := "<unknown>"
+				if transition, ok := condition["lastTransitionTime"].(string); ok {
+					if t, err := time.Parse(time.RFC3339, transition); err == nil {
+						age = util.FormatAge(t)
+					}
+				}
+				w.Write(describe.LEVEL_1, "%v\t%v\t%v\t%s\t%v\n",
+					condition["type"], condition["status"], valueOrNone(condition["reason"]), age, valueOrNone(condition["message"]))
+			}
+		}
+	}
+
+	if events != nil {
+		describe.DescribeEvents(events, w)
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// printStringMap prints labels or annotations one per line, like kubectl describe
+func printStringMap(w describe.PrefixWriter, title string, values map[string]string) {
+	if len(values) == 0 {
+		w.Write(describe.LEVEL_0, "%s:\t<none>\n", title)
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		label := ""
+		if i == 0 {
+			label = title + ":"
+		}
+		w.Write(describe.LEVEL_0, "%s\t%s=%s\n", label, key, values[key])
+	}
+}
+
+// printTree prints the fields of a map sorted by key, nesting maps and lists. The
+// fields of a list item are marked with "- " like in YAML.
+func printTree(w describe.PrefixWriter, level int, fields map[string]interface{}, item bool) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		marker := ""
+		if item {
+			marker = "  "
+			if i == 0 {
+				marker = "- "
+			}
+		}
+		switch value := fields[key].(type) {
+		case map[string]interface{}:
+			if len(value) == 0 {
+				w.Write(level, "%s%s:\t{}\n", marker, key)
+				continue
+			}
+			w.Write(level, "%s%s:\n", marker, key)
+			printTree(w, childLevel(level, item), value, false)
+		case []interface{}:
+			if len(value) == 0 {
+				w.Write(level, "%s%s:\t[]\n", marker, key)
+				continue
+			}
+			w.Write(level, "%s%s:\n", marker, key)
+			for _, element := range value {
+				if m, ok := element.(map[string]interface{}); ok && len(m) > 0 {
+					printTree(w, childLevel(level, item), m, true)
+				} else {
+					w.Write(childLevel(level, item), "- %v\n", valueOrNone(element))
+				}
+			}
+		default:
+			w.Write(level, "%s%s:\t%v\n", marker, key, valueOrNone(value))
+		}
+	}
+}
+
+// childLevel returns the level of the children of a field, which are indented past
+// the "- " marker of a list item
+func childLevel(level int, item bool) int {
+	if item {
+		return level + 2
+	}
+	return level + 1
+}
+
+func valueOrNone(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/kubectl/pkg/util/event"
+	"sigs.k8s.io/yaml"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// describeDocument is the machine-readable description of the objects of one
+// cluster, printed by describe -o json|yaml. Matched counts the objects before --max.
+type describeDocument struct {
+	Cluster string            `json:"cluster"`
+	Context string            `json:"context"`
+	Matched int               `json:"matched"`
+	Objects []describedObject `json:"objects"`
+	Error   string            `json:"error,omitempty"`
+}
+
+type describedObject struct {
+	Object  *unstructured.Unstructured `json:"object"`
+	Events  []corev1.Event             `json:"events,omitempty"`
+	Summary objectSummary              `json:"summary"`
+}
+
+// objectSummary is what describe computes from an object and its events
+type objectSummary struct {
+	Age           string             `json:"age"`
+	Conditions    []conditionSummary `json:"conditions,omitempty"`
+	Restarts      int64              `json:"restarts,omitempty"`
+	WarningEvents int                `json:"warningEvents"`
+	LastEvent     string             `json:"lastEvent,omitempty"`
+}
+
+type conditionSummary struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// printDescribeDocuments prints a describeDocument per cluster, as indented JSON
+// documents one after the other or as YAML documents separated by ---. A cluster that
+// fails gets a document with the error, so tooling sees every cluster.
+func printDescribeDocuments(clusters []cluster.ClusterInfo, resourceType string, names []string, selector string, showEvents bool, chunkSize, max int, outputFormat, namespace string, allNamespaces bool) error {
+	out := util.GetOutputStream()
+	resolver := util.NewGVRResolver(resourceType)
+	for i, clusterInfo := range clusters {
+		doc, err := describeDocumentFor(clusterInfo, resolver, names, selector, showEvents, chunkSize, max, clusterInfo.TargetNamespace(namespace), allNamespaces)
+		if err != nil {
+			doc.Error = err.Error()
+		}
+
+		var data []byte
+		if outputFormat == "json" {
+			data, err = json.MarshalIndent(doc, "", "  ")
+			data = append(data, '\n')
+		} else {
+			data, err = yaml.Marshal(doc)
+			if i > 0 {
+				data = append([]byte("---\n"), data...)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode the description of cluster %s: %v", clusterInfo.Name, err)
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeDocumentFor(clusterInfo cluster.ClusterInfo, resolver *util.GVRResolver, names []string, selector string, showEvents bool, chunkSize, max int, namespace string, allNamespaces bool) (describeDocument, error) {
+	doc := describeDocument{Cluster: clusterInfo.Name, Context: clusterInfo.Context, Objects: []describedObject{}}
+	if clusterInfo.Client() == nil {
+		return doc, fmt.Errorf("no client available")
+	}
+	gvr, namespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+	if err != nil {
+		return doc, err
+	}
+	objects, matched, err := describeTargets(clusterInfo, gvr, namespaced, names, selector, chunkSize, max, namespace, allNamespaces)
+	if err != nil {
+		return doc, err
+	}
+	doc.Matched = matched
+
+	for i := range objects {
+		obj := &objects[i]
+		redactSecret(obj)
+		var events []corev1.Event
+		if showEvents {
+			if events, err = objectEvents(clusterInfo, obj); err != nil {
+				return doc, err
+			}
+		}
+		doc.Objects = append(doc.Objects, describedObject{Object: obj, Events: events, Summary: summarizeObject(obj, events)})
+	}
+	return doc, nil
+}
+
+// objectEvents returns the events of an object, oldest first
+func objectEvents(clusterInfo cluster.ClusterInfo, obj *unstructured.Unstructured) ([]corev1.Event, error) {
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+	selector := fields.Set{"involvedObject.uid": string(obj.GetUID())}
+	events, err := clusterInfo.Client().CoreV1().Events(obj.GetNamespace()).List(ctx, metav1.ListOptions{FieldSelector: selector.AsSelector().String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the events of %s: %v", obj.GetName(), err)
+	}
+	sort.Sort(event.SortableEvents(events.Items))
+	return events.Items, nil
+}
+
+// summarizeObject computes the summary of an object from its status and its events,
+// which are sorted oldest first
+func summarizeObject(obj *unstructured.Unstructured, events []corev1.Event) objectSummary {
+	summary := objectSummary{Age: util.FormatAge(obj.GetCreationTimestamp().Time)}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		summary.Conditions = append(summary.Conditions, conditionSummary{Type: conditionType, Status: status, Reason: reason, Message: message})
+	}
+
+	statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+	for _, s := range statuses {
+		if status, ok := s.(map[string]interface{}); ok {
+			restarts, _, _ := unstructured.NestedInt64(status, "restartCount")
+			summary.Restarts += restarts
+		}
+	}
+
+	for _, e := range events {
+		if e.Type == corev1.EventTypeWarning {
+			summary.WarningEvents++
+		}
+	}
+	if len(events) > 0 {
+		last := events[len(events)-1]
+		summary.LastEvent = last.Reason + ": " + last.Message
+	}
+	return summary
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestParseDescribeArgs ensures describe accepts the argument forms of kubectl describe
+func TestParseDescribeArgs(t *testing.T) {
+	tests := []struct {
+		args         []string
+		resourceType string
+		names        []string
+		wantErr      bool
+	}{
+		{[]string{"pods"}, "pods", []string{}, false},
+		{[]string{"pod", "nginx", "api"}, "pod", []string{"nginx", "api"}, false},
+		{[]string{"service/my-service"}, "service", []string{"my-service"}, false},
+		{[]string{"pod/a", "pod/b"}, "pod", []string{"a", "b"}, false},
+		{[]string{"pod/a", "svc/b"}, "", nil, true},
+		{[]string{"pod/"}, "", nil, true},
+		{[]string{"pod/a", "b"}, "", nil, true},
+	}
+	for _, tt := range tests {
+		resourceType, names, err := parseDescribeArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDescribeArgs(%v) error = %v, want error %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if resourceType != tt.resourceType || !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("parseDescribeArgs(%v) = %q, %v, want %q, %v", tt.args, resourceType, names, tt.resourceType, tt.names)
+		}
+	}
+}
+
+// TestSummarizeObject ensures the summary of -o json|yaml is computed from the status
+// and the events of an object
+func TestSummarizeObject(t *testing.T) {
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Pod",
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "ContainersNotReady"},
+			},
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"name": "app", "restartCount": int64(3)},
+				map[string]interface{}{"name": "sidecar", "restartCount": int64(1)},
+			},
+		},
+	}}
+	events := []corev1.Event{
+		{Type: corev1.EventTypeNormal, Reason: "Pulled", Message: "image pulled"},
+		{Type: corev1.EventTypeWarning, Reason: "BackOff", Message: "back-off restarting failed container"},
+	}
+
+	summary := summarizeObject(pod, events)
+	want := []conditionSummary{{Type: "Ready", Status: "False", Reason: "ContainersNotReady"}}
+	if !reflect.DeepEqual(summary.Conditions, want) {
+		t.Errorf("conditions = %v, want %v", summary.Conditions, want)
+	}
+	if summary.Restarts != 4 {
+		t.Errorf("restarts = %d, want 4", summary.Restarts)
+	}
+	if summary.WarningEvents != 1 {
+		t.Errorf("warning events = %d, want 1", summary.WarningEvents)
+	}
+	if summary.LastEvent != "BackOff: back-off restarting failed container" {
+		t.Errorf("last event = %q", summary.LastEvent)
+	}
+}
+
+// TestDescribeGeneric ensures custom resources are described with their spec tree and
+// a table of their conditions
+func TestDescribeGeneric(t *testing.T) {
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "control.kubestellar.io/v1alpha1",
+		"kind":       "BindingPolicy",
+		"metadata": map[string]interface{}{
+			"name":   "nginx",
+			"labels": map[string]interface{}{"app": "nginx"},
+		},
+		"spec": map[string]interface{}{
+			"clusterSelectors": []interface{}{
+				map[string]interface{}{"matchLabels": map[string]interface{}{"location-group": "edge"}},
+			},
+			"wantSingletonReportedState": true,
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Synced", "status": "True", "reason": "Success"},
+			},
+		},
+	}}
+
+	// Columns are aligned with spaces, compared here as one space
+	output := regexp.MustCompile(`(\S) {2,}`).ReplaceAllString(describeGeneric(policy, nil), "$1 ")
+	for _, want := range []string{
+		"Name: nginx\n",
+		"Labels: app=nginx\n",
+		"Kind: BindingPolicy\n",
+		"Spec:\n  clusterSelectors:\n    - matchLabels:\n        location-group: edge\n  wantSingletonReportedState: true\n",
+		"Status:\n  observedGeneration: 2\n",
+		"Conditions:\n  Type Status Reason Age Message\n  ---- ------ ------ --- -------\n  Synced True Success <unknown> <none>\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("description lacks %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Events:") {
+		t.Errorf("description without events has an Events section:\n%s", output)
+	}
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// agentNamespaces are the namespaces of the OCM agent that clusteradm unjoin removes
+var agentNamespaces = []string{"open-cluster-management-agent", "open-cluster-management-agent-addon"}
+
+func newDetachCommand() *cobra.Command {
+	var wecContext string
+	var cleanWorkloads bool
+	var dryRun bool
+	var yes bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "detach CLUSTER",
+		Short: "Deregister a WEC from the ITS",
+		Long: `Deregister a workload execution cluster (WEC), the inverse of join. What will be
+removed from which cluster is previewed and confirmed first.
+
+The objects KubeStellar delivered to the cluster are kept unless --clean-workloads
+is given; then the ManagedCluster is deleted first, so its ManifestWorks go, and the
+agent removes the objects it applied before it is uninstalled. Objects that are left
+after --timeout are deleted directly. The OCM agent is removed from the cluster with
+clusteradm unjoin and the ManagedCluster is deleted on the ITS, which removes the
+namespace of the cluster there. When the cluster is unreachable only the
+ManagedCluster is deleted.`,
+		Example: `# Preview what detaching cluster3 removes
+kubectl multi detach cluster3 --dry-run
+
+# Detach cluster3 and delete the workloads delivered to it
+kubectl multi detach cluster3 --clean-workloads`,
+		Annotations: map[string]string{mutatingAnnotation: "true"},
+		Args:        cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleDetachCommand(cmd.Context(), args[0], wecContext, cleanWorkloads, dryRun, yes, timeout, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&wecContext, "context", "", "kubeconfig context of the cluster (defaults to the cluster name)")
+	cmd.Flags().BoolVar(&cleanWorkloads, "clean-workloads", false, "also delete the objects KubeStellar delivered to the cluster")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only preview what would be removed")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "with --clean-workloads, how long to wait for the agent to remove the delivered objects")
+	return cmd
+}
+
+func handleDetachCommand(ctx context.Context, name, wecContext string, cleanWorkloads, dryRun, yes bool, timeout time.Duration, kubeconfig, remoteCtx string) error {
+	if wecContext == "" {
+		wecContext = name
+	}
+	if err := checkMutationTargets([]cluster.ClusterInfo{{Name: name, Context: wecContext}}, remoteCtx); err != nil {
+		return err
+	}
+
+	its, err := cluster.ContextClient(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the ITS: %v", err)
+	}
+	managedClusters := its.DynamicClient().Resource(cluster.ManagedClusterGVR)
+	_, err = managedClusters.Get(ctx, name, metav1.GetOptions{})
+	registered := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get managed cluster %s: %v", name, err)
+	}
+
+	// The cluster itself is optional, a WEC that is gone can still be deregistered
+	wec, err := cluster.ContextClient(kubeconfig, wecContext)
+	reachable := err == nil
+	if reachable {
+		if _, err = wec.Client().Discovery().ServerVersion(); err != nil {
+			reachable = false
+		}
+	}
+	if !reachable {
+		printWarning("cluster %s is unreachable, its agent and workloads stay: %v", name, err)
+	}
+	if !registered && !reachable {
+		return fmt.Errorf("managed cluster %s is not registered with %s", name, remoteCtx)
+	}
+	unjoin := reachable
+	if unjoin {
+		if _, err := exec.LookPath("clusteradm"); err != nil {
+			return fmt.Errorf("clusteradm is required to remove the agent and not in PATH")
+		}
+	}
+
+	var delivered []orphan
+	if reachable {
+		if delivered, err = deliveredObjects(wec); err != nil {
+			return fmt.Errorf("failed to list the objects delivered to %s: %v", name, err)
+		}
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tACTION\tOBJECT\n")
+	if cleanWorkloads {
+		for _, o := range delivered {
+			fmt.Fprintf(tw, "%s\tdelete\t%s\n", name, deliveredName(o))
+		}
+	} else if len(delivered) > 0 {
+		fmt.Fprintf(tw, "%s\tkeep\t%d delivered objects\n", name, len(delivered))
+	}
+	if unjoin {
+		fmt.Fprintf(tw, "%s\tremove\tklusterlet/klusterlet\n", name)
+		for _, ns := range agentNamespaces {
+			fmt.Fprintf(tw, "%s\tremove\tnamespace/%s\n", name, ns)
+		}
+	}
+	if registered {
+		fmt.Fprintf(tw, "%s\tdelete\tmanagedcluster/%s\n", remoteCtx, name)
+		fmt.Fprintf(tw, "%s\tdelete\tnamespace/%s\n", remoteCtx, name)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+	if !yes {
+		confirmed, err := confirmYes()
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Detach cancelled")
+			return nil
+		}
+	}
+
+	deleteManagedCluster := func() error {
+		err := managedClusters.Delete(ctx, name, metav1.DeleteOptions{})
+		writeAudit("", remoteCtx, []string{"managedcluster/" + name}, false, err)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete managed cluster %s: %v", name, err)
+		}
+		fmt.Printf("managedcluster/%s deleted\n", name)
+		return nil
+	}
+
+	// Deleting the ManagedCluster deletes its ManifestWorks, and the work agent then
+	// removes the objects it applied, so with --clean-workloads it goes while the agent
+	// still runs. Otherwise the agent goes first and leaves the objects in the cluster.
+	if cleanWorkloads && registered {
+		if err := deleteManagedCluster(); err != nil {
+			return err
+		}
+		registered = false
+		if len(delivered) > 0 {
+			fmt.Printf("Waiting for the agent of %s to remove %d delivered objects...\n", name, len(delivered))
+			if delivered, err = waitForDeliveredRemoval(ctx, wec, timeout); err != nil {
+				return err
+			}
+			if len(delivered) > 0 {
+				printWarning("the agent of cluster %s left %d delivered objects after %s, deleting them directly", name, len(delivered), timeout)
+			}
+		}
+	}
+	// Objects left by the agent, or delivered while the cluster was not registered, have
+	// no ManifestWork that would restore them
+	if cleanWorkloads {
+		for _, o := range delivered {
+			ctx, cancel := wec.RequestContext()
+			resource := wec.DynamicClient().Resource(o.gvr)
+			if o.namespace != "" {
+				err = resource.Namespace(o.namespace).Delete(ctx, o.name, metav1.DeleteOptions{})
+			} else {
+				err = resource.Delete(ctx, o.name, metav1.DeleteOptions{})
+			}
+			cancel()
+			writeAudit(name, wecContext, []string{deliveredName(o)}, false, err)
+			if err != nil && !apierrors.IsNotFound(err) {
+				warnClusterFailure(name, err, "delete %s", deliveredName(o))
+				continue
+			}
+			fmt.Printf("%s deleted in cluster %s\n", deliveredName(o), name)
+		}
+	}
+	if unjoin {
+		args := []string{"unjoin", "--cluster-name", name, "--context", wecContext}
+		if kubeconfig != "" {
+			args = append(args, "--kubeconfig", kubeconfig)
+		}
+		fmt.Printf("Executing: clusteradm %s\n", strings.Join(args, " "))
+		clusteradm := exec.CommandContext(ctx, "clusteradm", args...)
+		clusteradm.Stdout, clusteradm.Stderr = os.Stdout, os.Stderr
+		err := clusteradm.Run()
+		writeAudit(name, wecContext, []string{"klusterlet/klusterlet"}, false, err)
+		if err != nil {
+			return fmt.Errorf("clusteradm unjoin failed: %v", err)
+		}
+	}
+	if registered {
+		return deleteManagedCluster()
+	}
+	return nil
+}
+
+// waitForDeliveredRemoval polls a cluster until the agent removed the objects
+// KubeStellar delivered to it, and returns those that are left after timeout
+func waitForDeliveredRemoval(parent context.Context, wec cluster.ClusterInfo, timeout time.Duration) ([]orphan, error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	for {
+		delivered, err := deliveredObjects(wec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the objects delivered to %s: %v", wec.Name, err)
+		}
+		if len(delivered) == 0 {
+			return nil, nil
+		}
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			if err := parent.Err(); err != nil {
+				return nil, err
+			}
+			return delivered, nil
+		}
+	}
+}
+
+// deliveredObjects returns the objects KubeStellar delivered to a cluster, recognized
+// like orphans by their delivery label
+func deliveredObjects(wec cluster.ClusterInfo) ([]orphan, error) {
+	gvrs, err := orphanResources(wec, nil)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := wec.RequestContext()
+	defer cancel()
+	var delivered []orphan
+	for _, gvr := range gvrs {
+		list, err := wec.DynamicClient().Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: bindingLabel})
+		if err != nil {
+			// Types the agent cannot list cannot hold delivered objects it manages
+			if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, obj := range list.Items {
+			delivered = append(delivered, orphan{
+				cluster:   wec,
+				gvr:       gvr,
+				kind:      obj.GetKind(),
+				namespace: obj.GetNamespace(),
+				name:      obj.GetName(),
+				binding:   obj.GetLabels()[bindingLabel],
+			})
+		}
+	}
+	return delivered, nil
+}
+
+func deliveredName(o orphan) string {
+	name := strings.ToLower(o.kind) + "/" + o.name
+	if o.namespace != "" {
+		name += " -n " + o.namespace
+	}
+	return name
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// doctorStatus is the traffic-light result of a single health check
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+func (s doctorStatus) String() string {
+	switch s {
+	case doctorOK:
+		return "🟢 OK"
+	case doctorWarn:
+		return "🟡 WARN"
+	default:
+		return "🔴 FAIL"
+	}
+}
+
+// doctorCheck is the result of one health check against one cluster
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	// Fix tells how to resolve the problem, when that is known
+	Fix string
+}
+
+// certExpiryWarning is how close to expiry a client certificate must be before it is reported
+const certExpiryWarning = 30 * 24 * time.Hour
+
+var managedClusterAddOnGVR = schema.GroupVersionResource{
+	Group:    "addon.open-cluster-management.io",
+	Version:  "v1alpha1",
+	Resource: "managedclusteraddons",
+}
+
+func newDoctorCommand() *cobra.Command {
+	var addonName string
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Run health checks against all managed clusters",
+		Long: `Run a set of health checks against every managed cluster and print a
+red/yellow/green report.
+
+The following is checked for each cluster:
+  - API server reachability
+  - node readiness
+  - node pressure conditions (memory, disk, PID)
+  - failing pods in kube-system
+  - expiry of the kubeconfig client certificate
+  - health of the KubeStellar status add-on registered on the ITS
+
+The command exits non-zero when a check fails in any cluster, so it can gate scripts.`,
+		Example: `# Check the health of all managed clusters
+kubectl multi doctor
+
+# Check a differently named KubeStellar status add-on
+kubectl multi doctor --addon-name my-status-addon`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleDoctorCommand(addonName, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&addonName, "addon-name", "addon-status", "name of the KubeStellar status ManagedClusterAddOn to check")
+	cmd.AddCommand(newDoctorKubeconfigCommand())
+
+	return cmd
+}
+
+func handleDoctorCommand(addonName, kubeconfig, remoteCtx string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	// The status add-on is registered on the ITS, so it is checked through the remote context
+	var itsClient dynamic.Interface
+	if remoteCtx != "" {
+		itsClient, err = cluster.GetRemoteDynamicClient(kubeconfig, remoteCtx)
+		if err != nil {
+			printWarning("cannot check KubeStellar add-on health: %v", err)
+		}
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tCHECK\tSTATUS\tDETAILS\n")
+
+	unhealthy := 0
+	for _, clusterInfo := range clusters {
+		checks := runDoctorChecks(clusterInfo, itsClient, addonName, remoteCtx)
+		worst := doctorOK
+		for _, check := range checks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", clusterInfo.Name, check.Name, check.Status, check.Detail)
+			if check.Status == doctorFail {
+				cluster.RecordFailure(clusterInfo.Name, "check "+check.Name, errors.New(check.Detail))
+			}
+			if check.Status > worst {
+				worst = check.Status
+			}
+		}
+		if worst == doctorFail {
+			unhealthy++
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(util.GetOutputStream(), "\n%d of %d clusters healthy\n", len(clusters)-unhealthy, len(clusters))
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d clusters failed health checks", unhealthy, len(clusters))
+	}
+	return nil
+}
+
+// runDoctorChecks runs every health check against a single cluster
+func runDoctorChecks(clusterInfo cluster.ClusterInfo, itsClient dynamic.Interface, addonName, remoteCtx string) []doctorCheck {
+	if clusterInfo.Client() == nil {
+		return []doctorCheck{{Name: "api", Status: doctorFail, Detail: "no client available"}}
+	}
+
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	// Fetched within the request context, so a hanging API server is bounded by the
+	// cluster timeout like the other checks
+	var serverVersion version.Info
+	body, err := clusterInfo.Client().Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err == nil {
+		err = json.Unmarshal(body, &serverVersion)
+	}
+	if err != nil {
+		// Every other check needs the API server, so there is no point in continuing
+		return []doctorCheck{{Name: "api", Status: doctorFail, Detail: fmt.Sprintf("unreachable: %v", err)}}
+	}
+
+	checks := []doctorCheck{{Name: "api", Status: doctorOK, Detail: fmt.Sprintf("reachable (%s)", serverVersion.GitVersion)}}
+	checks = append(checks, checkNodes(ctx, clusterInfo)...)
+	checks = append(checks, checkSystemPods(ctx, clusterInfo))
+	checks = append(checks, checkClientCertificate(clusterInfo.RestConfig))
+	if clusterInfo.Context != remoteCtx {
+		checks = append(checks, checkStatusAddOn(ctx, itsClient, clusterInfo.Name, addonName))
+	}
+	return checks
+}
+
+// checkNodes reports node readiness and node pressure conditions
+func checkNodes(ctx context.Context, clusterInfo cluster.ClusterInfo) []doctorCheck {
+	nodes, err := clusterInfo.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []doctorCheck{{Name: "nodes", Status: doctorFail, Detail: fmt.Sprintf("failed to list nodes: %v", err)}}
+	}
+
+	var notReady, pressured []string
+	for _, node := range nodes.Items {
+		if util.GetNodeStatus(node) != "Ready" {
+			notReady = append(notReady, node.Name)
+		}
+		for _, condition := range node.Status.Conditions {
+			switch condition.Type {
+			case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+				if condition.Status == corev1.ConditionTrue {
+					pressured = append(pressured, fmt.Sprintf("%s(%s)", node.Name, condition.Type))
+				}
+			}
+		}
+	}
+
+	readiness := doctorCheck{Name: "nodes", Status: doctorOK, Detail: fmt.Sprintf("%d/%d ready", len(nodes.Items)-len(notReady), len(nodes.Items))}
+	if len(nodes.Items) == 0 {
+		readiness.Status = doctorWarn
+		readiness.Detail = "no nodes found"
+	} else if len(notReady) == len(nodes.Items) {
+		readiness.Status = doctorFail
+		readiness.Detail += ", not ready: " + strings.Join(notReady, ",")
+	} else if len(notReady) > 0 {
+		readiness.Status = doctorWarn
+		readiness.Detail += ", not ready: " + strings.Join(notReady, ",")
+	}
+
+	pressure := doctorCheck{Name: "node-pressure", Status: doctorOK, Detail: "none"}
+	if len(pressured) > 0 {
+		pressure.Status = doctorWarn
+		pressure.Detail = strings.Join(pressured, ",")
+	}
+
+	return []doctorCheck{readiness, pressure}
+}
+
+// checkSystemPods reports pods in kube-system that are not running or are crash looping
+func checkSystemPods(ctx context.Context, clusterInfo cluster.ClusterInfo) doctorCheck {
+	pods, err := clusterInfo.Client().CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return doctorCheck{Name: "system-pods", Status: doctorFail, Detail: fmt.Sprintf("failed to list pods: %v", err)}
+	}
+
+	var failing []string
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			failing = append(failing, fmt.Sprintf("%s(%s)", pod.Name, pod.Status.Phase))
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+				failing = append(failing, fmt.Sprintf("%s(%s)", pod.Name, status.State.Waiting.Reason))
+				break
+			}
+		}
+	}
+
+	if len(failing) > 0 {
+		return doctorCheck{Name: "system-pods", Status: doctorFail, Detail: strings.Join(failing, ",")}
+	}
+	return doctorCheck{Name: "system-pods", Status: doctorOK, Detail: fmt.Sprintf("%d healthy", len(pods.Items))}
+}
+
+// checkClientCertificate reports the expiry of the client certificate used to reach the cluster
+func checkClientCertificate(restCfg *rest.Config) doctorCheck {
+	if restCfg == nil {
+		return doctorCheck{Name: "credentials", Status: doctorWarn, Detail: "no rest config available"}
+	}
+
+	certData := restCfg.TLSClientConfig.CertData
+	if len(certData) == 0 && restCfg.TLSClientConfig.CertFile != "" {
+		data, err := os.ReadFile(restCfg.TLSClientConfig.CertFile)
+		if err != nil {
+			return doctorCheck{Name: "credentials", Status: doctorFail, Detail: fmt.Sprintf("cannot read client certificate: %v", err)}
+		}
+		certData = data
+	}
+	if len(certData) == 0 {
+		return doctorCheck{Name: "credentials", Status: doctorOK, Detail: "no client certificate (token or exec auth)"}
+	}
+
+	block, _ := pem.Decode(certData)
+	if block == nil {
+		return doctorCheck{Name: "credentials", Status: doctorFail, Detail: "client certificate is not valid PEM"}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return doctorCheck{Name: "credentials", Status: doctorFail, Detail: fmt.Sprintf("cannot parse client certificate: %v", err)}
+	}
+
+	remaining := time.Until(cert.NotAfter)
+	expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+	switch {
+	case remaining <= 0:
+		return doctorCheck{Name: "credentials", Status: doctorFail, Detail: "client certificate expired at " + expiry}
+	case remaining < certExpiryWarning:
+		return doctorCheck{Name: "credentials", Status: doctorWarn, Detail: "client certificate expires at " + expiry}
+	default:
+		return doctorCheck{Name: "credentials", Status: doctorOK, Detail: "client certificate valid until " + expiry}
+	}
+}
+
+// checkStatusAddOn reports the Available condition of the KubeStellar status add-on for a cluster
+func checkStatusAddOn(ctx context.Context, itsClient dynamic.Interface, clusterName, addonName string) doctorCheck {
+	if itsClient == nil {
+		return doctorCheck{Name: "kubestellar-agent", Status: doctorWarn, Detail: "ITS not reachable"}
+	}
+
+	addon, err := itsClient.Resource(managedClusterAddOnGVR).Namespace(clusterName).Get(ctx, addonName, metav1.GetOptions{})
+	if err != nil {
+		return doctorCheck{Name: "kubestellar-agent", Status: doctorFail, Detail: fmt.Sprintf("add-on %s not found: %v", addonName, err)}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(addon.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Available" {
+			continue
+		}
+		if condition["status"] == "True" {
+			return doctorCheck{Name: "kubestellar-agent", Status: doctorOK, Detail: addonName + " available"}
+		}
+		message, _ := condition["message"].(string)
+		return doctorCheck{Name: "kubestellar-agent", Status: doctorFail, Detail: fmt.Sprintf("%s unavailable: %s", addonName, message)}
+	}
+
+	return doctorCheck{Name: "kubestellar-agent", Status: doctorWarn, Detail: addonName + " has not reported availability"}
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// contextTimeout bounds the requests checking one context
+const contextTimeout = 5 * time.Second
+
+func newDoctorKubeconfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "kubeconfig",
+		Short: "Validate every kubeconfig context the plugin may use",
+		Long: `Validate every context of the kubeconfig and explain how cluster discovery treats it.
+
+For each context this checks:
+  - the client certificate and its expiry
+  - that the exec credential plugin is installed
+  - that the API server is reachable
+  - whether the context is the ITS, a WDS, a WEC registered with the ITS, or none
+
+ManagedClusters of the ITS without a context of the same name are listed too, since
+discovery skips them.`,
+		Example: `# Find out why a cluster is missing from the output of get
+kubectl multi doctor kubeconfig`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleDoctorKubeconfigCommand(kubeconfig, remoteCtx)
+		},
+	}
+}
+
+// contextReport is the role and the checks of one kubeconfig context
+type contextReport struct {
+	role   string
+	checks []doctorCheck
+}
+
+func handleDoctorKubeconfigCommand(kubeconfig, remoteCtx string) error {
+	loading := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loading.ExplicitPath = kubeconfig
+	}
+	rawCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loading, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	if len(rawCfg.Contexts) == 0 {
+		return fmt.Errorf("the kubeconfig has no contexts")
+	}
+
+	// The ManagedClusters tell which contexts discovery uses as WECs
+	managed := map[string]bool{}
+	itsErr := fmt.Errorf("no --remote-context")
+	if _, ok := rawCfg.Contexts[remoteCtx]; ok {
+		var names []string
+		if names, itsErr = managedClusterNames(kubeconfig, remoteCtx); itsErr == nil {
+			for _, name := range names {
+				managed[name] = true
+			}
+		}
+	} else if remoteCtx != "" {
+		itsErr = fmt.Errorf("context %q does not exist", remoteCtx)
+	}
+
+	names := make([]string, 0, len(rawCfg.Contexts))
+	for name := range rawCfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	reports := make([]contextReport, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			reports[i] = checkContext(rawCfg, name, remoteCtx, managed)
+		}(i, name)
+	}
+	wg.Wait()
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CONTEXT\tROLE\tCHECK\tSTATUS\tDETAILS\n")
+	failed := 0
+	var fixes []string
+	for i, report := range reports {
+		for _, check := range report.checks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", names[i], report.role, check.Name, check.Status, check.Detail)
+			if check.Status == doctorFail {
+				failed++
+			}
+			if check.Status != doctorOK && check.Fix != "" {
+				fixes = append(fixes, fmt.Sprintf("  %s %s: %s", names[i], check.Name, check.Fix))
+			}
+		}
+	}
+	// Registered clusters without a context are skipped by discovery
+	var missing []string
+	for name := range managed {
+		if _, ok := rawCfg.Contexts[name]; !ok && cluster.SkipReason(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", "-", "wec", "discovery", doctorFail, "ManagedCluster "+name+" has no context of that name, it is skipped")
+		fixes = append(fixes, fmt.Sprintf("  %s: add a context named %s, e.g. kubectl config rename-context CONTEXT %s", name, name, name))
+		failed++
+	}
+	tw.Flush()
+
+	if itsErr != nil {
+		printWarning("cannot list the ManagedClusters of the ITS, WECs are not recognized: %v", itsErr)
+	}
+	if len(fixes) > 0 {
+		fmt.Printf("\nTo fix:\n%s\n", strings.Join(fixes, "\n"))
+	}
+	fmt.Printf("\n%d contexts checked, %d problems\n", len(names), failed)
+	return nil
+}
+
+// managedClusterNames lists the names of all ManagedClusters of the ITS, also those
+// that discovery skips
+func managedClusterNames(kubeconfig, remoteCtx string) ([]string, error) {
+	dyn, err := cluster.GetRemoteDynamicClient(kubeconfig, remoteCtx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+	list, err := dyn.Resource(cluster.ManagedClusterGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, mc := range list.Items {
+		names = append(names, mc.GetName())
+	}
+	return names, nil
+}
+
+// checkContext checks the credentials and reachability of a context and finds its role
+func checkContext(rawCfg clientcmdapi.Config, name, remoteCtx string, managed map[string]bool) contextReport {
+	report := contextReport{role: "-"}
+	kubeContext := rawCfg.Contexts[name]
+	if name == remoteCtx {
+		report.role = "its"
+	} else if managed[name] {
+		report.role = "wec"
+	}
+
+	restCfg, err := clientcmd.NewNonInteractiveClientConfig(rawCfg, name, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		report.checks = append(report.checks, doctorCheck{Name: "config", Status: doctorFail, Detail: err.Error(),
+			Fix: "fix the cluster and user of the context, see kubectl config view"})
+		return report
+	}
+
+	if authInfo := rawCfg.AuthInfos[kubeContext.AuthInfo]; authInfo != nil && authInfo.Exec != nil {
+		check := doctorCheck{Name: "exec", Status: doctorOK, Detail: authInfo.Exec.Command + " found"}
+		if _, err := exec.LookPath(authInfo.Exec.Command); err != nil {
+			check.Status, check.Detail = doctorFail, authInfo.Exec.Command+" is not in PATH"
+			check.Fix = "install the credential plugin " + authInfo.Exec.Command
+			if authInfo.Exec.InstallHint != "" {
+				check.Fix = strings.Join(strings.Fields(authInfo.Exec.InstallHint), " ")
+			}
+			report.checks = append(report.checks, check)
+			return report
+		}
+		report.checks = append(report.checks, check)
+	}
+	credentials := checkClientCertificate(restCfg)
+	if credentials.Status != doctorOK {
+		credentials.Fix = "renew the client certificate of the user " + kubeContext.AuthInfo
+	}
+	report.checks = append(report.checks, credentials)
+
+	restCfg.Timeout = contextTimeout
+	disc, err := discovery.NewDiscoveryClientForConfig(restCfg)
+	if err != nil {
+		report.checks = append(report.checks, doctorCheck{Name: "api", Status: doctorFail, Detail: err.Error()})
+		return report
+	}
+	version, err := disc.ServerVersion()
+	if err != nil {
+		report.checks = append(report.checks, doctorCheck{Name: "api", Status: doctorFail, Detail: fmt.Sprintf("unreachable: %v", err),
+			Fix: "check that " + restCfg.Host + " is reachable from here, e.g. the VPN or the kind cluster is up"})
+		return report
+	}
+	report.checks = append(report.checks, doctorCheck{Name: "api", Status: doctorOK, Detail: "reachable (" + version.GitVersion + ")"})
+
+	if report.role == "-" {
+		if resources, err := disc.ServerResourcesForGroupVersion("control.kubestellar.io/v1alpha1"); err == nil {
+			for _, resource := range resources.APIResources {
+				if resource.Name == "bindingpolicies" {
+					report.role = "wds"
+				}
+			}
+		}
+		if report.role == "-" {
+			if _, err := disc.ServerResourcesForGroupVersion(cluster.ManagedClusterGVR.GroupVersion().String()); err == nil {
+				report.role = "its"
+			}
+		}
+	}
+	report.checks = append(report.checks, discoveryCheck(name, kubeContext.Cluster, rawCfg.CurrentContext, remoteCtx, report.role))
+	return report
+}
+
+// discoveryCheck explains whether cluster discovery uses a context, mirroring
+// DiscoverClusters: WECs by context name, and the current context by cluster name
+func discoveryCheck(name, clusterName, currentContext, remoteCtx, role string) doctorCheck {
+	check := doctorCheck{Name: "discovery", Status: doctorOK}
+	switch {
+	case role == "wec":
+		if reason := cluster.SkipReason(name); reason != "" {
+			check.Status, check.Detail = doctorWarn, "skipped: "+reason
+			check.Fix = "rename the ManagedCluster and its context, or select it with --clusters"
+		} else {
+			check.Detail = "used as a managed cluster"
+		}
+	case name == currentContext:
+		if reason := cluster.SkipReason(clusterName); reason != "" {
+			check.Detail = "current context, skipped: " + reason
+		} else {
+			check.Detail = "used as the local cluster"
+		}
+	case name == remoteCtx:
+		check.Detail = "lists the managed clusters"
+	case role == "its":
+		check.Detail = "not queried, select it with --remote-context " + name
+	case role == "wds":
+		check.Detail = "not queried, workloads are described here"
+	default:
+		check.Detail = "not used, not registered with the ITS"
+	}
+	return check
+}
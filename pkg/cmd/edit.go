@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"kubectl-multi/pkg/cluster"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultWDSContext is the kubeconfig context of the WDS edited by default
+const defaultWDSContext = "wds1"
+
+func newEditCommand() *cobra.Command {
+	var wdsContext string
+	var clusterName string
+	var forceDirect bool
+
+	cmd := &cobra.Command{
+		Use:   "edit [TYPE[.VERSION][.GROUP]/]NAME",
+		Short: "Edit a resource in the WDS, or directly on one managed cluster",
+		Long: `Edit a resource in $EDITOR and apply the change.
+
+By default the object is edited in the WDS, the source of truth that KubeStellar
+propagates to the managed clusters. Edits made directly on a managed cluster are
+reverted by the transport, so they need both --cluster and --force-direct.`,
+		Example: `  # Edit a deployment in the WDS
+  kubectl multi edit deployment/nginx -n default
+
+  # Edit it in another WDS
+  kubectl multi edit deployment/nginx --wds-context wds2
+
+  # Edit it directly on one managed cluster, knowing the transport may revert it
+  kubectl multi edit deployment/nginx --cluster cluster1 --force-direct`,
+		Args:        cobra.RangeArgs(1, 2),
+		Annotations: map[string]string{mutatingAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			return handleEditCommand(args, wdsContext, clusterName, forceDirect, kubeconfig, remoteCtx, namespace)
+		},
+	}
+
+	cmd.Flags().StringVar(&wdsContext, "wds-context", defaultWDSContext, "kubeconfig context of the WDS to edit the resource in")
+	cmd.Flags().StringVar(&clusterName, "cluster", "", "managed cluster to edit the resource on directly (requires --force-direct)")
+	cmd.Flags().BoolVar(&forceDirect, "force-direct", false, "edit on the managed cluster given by --cluster although the transport may revert the change")
+
+	return cmd
+}
+
+func handleEditCommand(args []string, wdsContext, clusterName string, forceDirect bool, kubeconfig, remoteCtx, namespace string) error {
+	if clusterName == "" {
+		if forceDirect {
+			return fmt.Errorf("--force-direct requires --cluster")
+		}
+		return runKubectlEdit(args, wdsContext, namespace, kubeconfig)
+	}
+	if !forceDirect {
+		return fmt.Errorf("refusing to edit on cluster %s directly: KubeStellar reverts changes to objects it propagates from the WDS; edit in the WDS (--wds-context) or pass --force-direct", clusterName)
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+	var target *cluster.ClusterInfo
+	for i := range clusters {
+		if clusters[i].Name == clusterName || clusters[i].Context == clusterName {
+			target = &clusters[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("cluster %s not found", clusterName)
+	}
+	if target.Context == remoteCtx {
+		return fmt.Errorf("cannot perform this operation on ITS (control) cluster: %s", target.Context)
+	}
+
+	targets := []cluster.ClusterInfo{*target}
+	if err := checkMutationTargets(targets, remoteCtx); err != nil {
+		return err
+	}
+	confirmed, err := confirmCommand("edit", targets, remoteCtx)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Edit cancelled...")
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: editing on %s directly; KubeStellar may revert the change from the WDS\n", target.Name)
+	return runKubectlEdit(args, target.Context, target.TargetNamespace(namespace), kubeconfig)
+}
+
+// runKubectlEdit runs kubectl edit against one context with the terminal attached, so
+// that kubectl can open $EDITOR
+func runKubectlEdit(args []string, context, namespace, kubeconfig string) error {
+	editArgs := append(append([]string{"edit"}, args...), "--context", context)
+	if namespace != "" {
+		editArgs = append(editArgs, "-n", namespace)
+	}
+
+	kubectlArgs, cleanup, err := withClusterArgs(editArgs, kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to edit in %s: %v", context, err)
+	}
+	defer cleanup()
+	cmd := exec.Command("kubectl", kubectlArgs...)
+	if kubeconfig != "" {
+		cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfig)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	auditKubectl(editArgs, "", err)
+	if err != nil {
+		return fmt.Errorf("failed to edit in %s: %v", context, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+var (
+	eventsOut   *os.File
+	eventsMu    sync.Mutex
+	eventsWarn  sync.Once
+	unsubscribe func()
+)
+
+// openEventsFile writes the progress events of the command to path as one JSON
+// object per line, so wrappers can follow the command while it runs. "-" writes
+// them to stderr.
+func openEventsFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	eventsOut = os.Stderr
+	if path != "-" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open events file: %v", err)
+		}
+		eventsOut = f
+	}
+
+	encoder := json.NewEncoder(eventsOut)
+	unsubscribe = cluster.Subscribe(func(event cluster.Event) {
+		eventsMu.Lock()
+		defer eventsMu.Unlock()
+		if err := encoder.Encode(event); err != nil {
+			eventsWarn.Do(func() { printWarning("failed to write events: %v", err) })
+		}
+	})
+	return nil
+}
+
+// closeEventsFile stops writing events and closes the file opened by openEventsFile
+func closeEventsFile() {
+	if unsubscribe != nil {
+		unsubscribe()
+		unsubscribe = nil
+	}
+	if eventsOut != nil && eventsOut != os.Stderr {
+		eventsOut.Close()
+	}
+	eventsOut = nil
+}
+
+// rowEventWriter emits a RowEmitted event for every table row written through it.
+// Rows start with the cluster column; the header and the empty result line are not
+// rows.
+type rowEventWriter struct {
+	out  io.Writer
+	line []byte
+}
+
+func newRowEventWriter(out io.Writer) *rowEventWriter {
+	return &rowEventWriter{out: out}
+}
+
+func (w *rowEventWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		emitTableRow(string(w.line[:i]))
+		w.line = w.line[i+1:]
+	}
+	return w.out.Write(p)
+}
+
+func emitTableRow(line string) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] == "CLUSTER" || line == "No resource found." {
+		return
+	}
+	cluster.EmitRow(fields[0], map[string]string{"line": strings.TrimRight(line, " ")})
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// errorPolicy decides when failed clusters make the command exit non-zero
+type errorPolicy struct {
+	spec      string
+	name      string
+	threshold float64 // percentage of failed clusters, only used by the threshold policy
+}
+
+// parseErrorPolicy parses any, all or threshold=N%
+func parseErrorPolicy(value string) (errorPolicy, error) {
+	switch value {
+	case "any", "all":
+		return errorPolicy{spec: value, name: value}, nil
+	}
+
+	if percent, ok := strings.CutPrefix(value, "threshold="); ok {
+		threshold, err := strconv.ParseFloat(strings.TrimSuffix(percent, "%"), 64)
+		if err != nil || threshold <= 0 || threshold > 100 {
+			return errorPolicy{}, fmt.Errorf("invalid error policy threshold %q, expected a percentage between 1%% and 100%%", percent)
+		}
+		return errorPolicy{spec: value, name: "threshold", threshold: threshold}, nil
+	}
+	return errorPolicy{}, fmt.Errorf("invalid error policy %q, expected any, all or threshold=N%%", value)
+}
+
+// violated reports whether failed out of total clusters breaks the policy
+func (p errorPolicy) violated(failed, total int) bool {
+	if failed == 0 || total == 0 {
+		return false
+	}
+	switch p.name {
+	case "any":
+		return true
+	case "threshold":
+		return float64(failed)*100/float64(total) >= p.threshold
+	}
+	return failed == total
+}
+
+// warnClusterFailure prints a per-cluster warning and records the failure for the
+// summary printed when the command finishes
+func warnClusterFailure(clusterName string, err error, operation string, args ...interface{}) {
+	operation = fmt.Sprintf(operation, args...)
+	// Errors after --fail-fast aborted the command are only the cancellation
+	aborted := cluster.Aborted()
+	cluster.RecordFailure(clusterName, operation, err)
+	if aborted || quiet {
+		return
+	}
+
+	cluster.ClearProgress()
+	out := os.Stdout
+	if ignoreErrors {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "Warning: failed to %s in cluster %s: %v\n", operation, clusterName, err)
+}
+
+// reportAPIWarnings prints the distinct warnings each cluster's API server returned,
+// such as deprecation notices, once per cluster
+func reportAPIWarnings() {
+	if quiet {
+		return
+	}
+	warnings, names := cluster.APIWarnings()
+	for _, name := range names {
+		for _, text := range warnings[name] {
+			fmt.Fprintf(os.Stderr, "Warning from cluster %s: %s\n", name, text)
+		}
+	}
+}
+
+// clusterError is one entry of the --error-output json report
+type clusterError struct {
+	Cluster   string `json:"cluster"`
+	Operation string `json:"operation,omitempty"`
+	Class     string `json:"class"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+}
+
+// reportClusterResults reports which clusters failed and why, and returns an error when
+// the failures break the error policy so the command exits non-zero. With
+// --ignore-errors only a command where every cluster failed exits non-zero.
+func reportClusterResults(policy errorPolicy) error {
+	succeeded, failed, skipped := cluster.Results()
+	total := len(succeeded) + len(failed) + len(skipped)
+
+	if errorOutput == "json" {
+		if err := writeErrorReport(failed, skipped); err != nil {
+			return err
+		}
+	} else if len(failed) > 0 && !quiet {
+		printFailureSummary(succeeded, failed, skipped)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	if ignoreErrors {
+		if len(succeeded) == 0 {
+			return fmt.Errorf("all %d clusters failed", total)
+		}
+		return nil
+	}
+	if cluster.Aborted() {
+		return fmt.Errorf("aborted after the first cluster failure (--fail-fast)")
+	}
+	if policy.violated(len(failed), total) {
+		return fmt.Errorf("%d of %d clusters failed (error policy %s)", len(failed), total, policy.spec)
+	}
+	return nil
+}
+
+// errorClassLabels describe the error classes in the failure summary
+var errorClassLabels = map[string]string{
+	cluster.ErrorClassAuth:         "Authentication failed",
+	cluster.ErrorClassForbidden:    "RBAC forbidden",
+	cluster.ErrorClassNotFound:     "Not found",
+	cluster.ErrorClassThrottled:    "Throttled",
+	cluster.ErrorClassTimeout:      "Timed out",
+	cluster.ErrorClassConnectivity: "Unreachable",
+	cluster.ErrorClassServer:       "Server error",
+	cluster.ErrorClassOther:        "Failed",
+}
+
+// failureGroup is a set of clusters that failed with the same class of error
+type failureGroup struct {
+	Class    string
+	Clusters []string
+	Example  cluster.ClusterFailure
+}
+
+// groupFailures groups failures by error class, largest group first, so many clusters
+// failing the same way produce one line instead of one message each
+func groupFailures(failed []cluster.ClusterFailure) []failureGroup {
+	var groups []failureGroup
+	index := make(map[string]int)
+	for _, failure := range failed {
+		class, _ := cluster.ClassifyError(failure.Err)
+		i, ok := index[class]
+		if !ok {
+			i = len(groups)
+			index[class] = i
+			groups = append(groups, failureGroup{Class: class, Example: failure})
+		}
+		groups[i].Clusters = append(groups[i].Clusters, failure.Cluster)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i].Clusters) > len(groups[j].Clusters) })
+	return groups
+}
+
+// printFailureSummary prints the failed clusters grouped by error class and the
+// skipped clusters on stderr
+func printFailureSummary(succeeded []string, failed []cluster.ClusterFailure, skipped []string) {
+	total := len(succeeded) + len(failed) + len(skipped)
+	fmt.Fprintf(os.Stderr, "\n%d of %d clusters succeeded, %d failed", len(succeeded), total, len(failed))
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, ", %d skipped", len(skipped))
+	}
+	fmt.Fprintf(os.Stderr, ":\n")
+	for _, group := range groupFailures(failed) {
+		fmt.Fprintf(os.Stderr, "  %s on %d cluster(s): %s\n", errorClassLabels[group.Class], len(group.Clusters), strings.Join(group.Clusters, ", "))
+		fmt.Fprintf(os.Stderr, "    e.g. %s: failed to %s: %v\n", group.Example.Cluster, group.Example.Operation, group.Example.Err)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "  skipped after the first failure (--fail-fast): %s\n", strings.Join(skipped, ", "))
+	}
+}
+
+// writeErrorReport writes the failed and skipped clusters as a JSON array to
+// --error-file, or to stderr when no file is set. The array is written even when
+// empty, so automation can always parse it.
+func writeErrorReport(failed []cluster.ClusterFailure, skipped []string) error {
+	report := []clusterError{}
+	for _, failure := range failed {
+		class, retryable := cluster.ClassifyError(failure.Err)
+		report = append(report, clusterError{
+			Cluster:   failure.Cluster,
+			Operation: failure.Operation,
+			Class:     class,
+			Message:   failure.Err.Error(),
+			Retryable: retryable,
+		})
+	}
+	for _, name := range skipped {
+		report = append(report, clusterError{
+			Cluster:   name,
+			Class:     "skipped",
+			Message:   "skipped after the first failure (--fail-fast)",
+			Retryable: true,
+		})
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode error report: %v", err)
+	}
+	data = append(data, '\n')
+
+	if errorFile == "" {
+		_, err = os.Stderr.Write(data)
+		return err
+	}
+	if err := os.WriteFile(errorFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write error report: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// TestErrorPolicy checks when each policy turns cluster failures into a failed command
+func TestErrorPolicy(t *testing.T) {
+	tests := []struct {
+		spec          string
+		failed, total int
+		want          bool
+	}{
+		{"any", 0, 4, false},
+		{"any", 1, 4, true},
+		{"all", 3, 4, false},
+		{"all", 4, 4, true},
+		{"threshold=50%", 1, 4, false},
+		{"threshold=50%", 2, 4, true},
+		{"threshold=25", 1, 4, true},
+	}
+
+	for _, tt := range tests {
+		policy, err := parseErrorPolicy(tt.spec)
+		if err != nil {
+			t.Fatalf("parseErrorPolicy(%q) failed: %v", tt.spec, err)
+		}
+		if got := policy.violated(tt.failed, tt.total); got != tt.want {
+			t.Errorf("%s with %d of %d failed: violated = %v, want %v", tt.spec, tt.failed, tt.total, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"some", "threshold=", "threshold=0%", "threshold=150%"} {
+		if _, err := parseErrorPolicy(spec); err == nil {
+			t.Errorf("parseErrorPolicy(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+// TestGroupFailures checks that failures are grouped by class, largest group first
+func TestGroupFailures(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("no access"))
+	failed := []cluster.ClusterFailure{
+		{Cluster: "c1", Operation: "list pods", Err: errors.New("boom")},
+		{Cluster: "c2", Operation: "list pods", Err: forbidden},
+		{Cluster: "c3", Operation: "list pods", Err: forbidden},
+	}
+
+	groups := groupFailures(failed)
+	if len(groups) != 2 {
+		t.Fatalf("groupFailures() returned %d groups, want 2", len(groups))
+	}
+	if groups[0].Class != cluster.ErrorClassForbidden || !reflect.DeepEqual(groups[0].Clusters, []string{"c2", "c3"}) {
+		t.Errorf("first group = %s %v, want forbidden [c2 c3]", groups[0].Class, groups[0].Clusters)
+	}
+	if groups[1].Class != cluster.ErrorClassOther || !reflect.DeepEqual(groups[1].Clusters, []string{"c1"}) {
+		t.Errorf("second group = %s %v, want other [c1]", groups[1].Class, groups[1].Clusters)
+	}
+}
+
+// TestFailingApplyFailsCommand ensures kubectl failures of write commands are recorded,
+// so an apply that fails on every cluster exits non-zero
+func TestFailingApplyFailsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake kubectl is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'error: the server has asked for the client to provide credentials' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for _, name := range []string{"apply-c1", "apply-c2"} {
+		clusterInfo := cluster.NewClusterInfo(name, name, nil, nil, nil, nil)
+		runClusterKubectl(clusterInfo, []string{"apply", "-f", "app.yaml", "--context", name}, "", "apply")
+	}
+
+	policy, err := parseErrorPolicy("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reportClusterResults(policy); err == nil {
+		t.Errorf("reportClusterResults() = nil after apply failed on every cluster, want an error")
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// defaultFindTypes are the resource types searched by find when --types is not given
+var defaultFindTypes = []string{
+	"pods", "deployments", "statefulsets", "daemonsets", "replicasets",
+	"jobs", "cronjobs", "services", "ingresses", "configmaps", "secrets",
+	"persistentvolumeclaims", "serviceaccounts",
+}
+
+func newFindCommand() *cobra.Command {
+	var types []string
+
+	cmd := &cobra.Command{
+		Use:   "find NAME",
+		Short: "Find resources by name across all managed clusters",
+		Long: `Find resources by name across all managed clusters and namespaces and print
+where each match lives.
+
+NAME may be an exact name or a shell glob such as 'frontend-*'. A set of common
+resource types is searched by default; use --types to search other types.`,
+		Example: `# Find everything named frontend in the fleet
+kubectl multi find frontend
+
+# Find all objects whose name starts with redis
+kubectl multi find 'redis*'
+
+# Only search deployments and services in the shop namespace
+kubectl multi find 'cart-*' --types deployments,services -n shop`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			return handleFindCommand(args[0], types, kubeconfig, remoteCtx, namespace)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&types, "types", defaultFindTypes, "comma separated list of resource types to search")
+
+	return cmd
+}
+
+func handleFindCommand(pattern string, types []string, kubeconfig, remoteCtx, namespace string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q: %v", pattern, err)
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tKIND\tNAME\tAGE\n")
+
+	// An exact name can be matched server side; globs have to be filtered locally
+	listOptions := metav1.ListOptions{}
+	if !strings.ContainsAny(pattern, "*?[") {
+		listOptions.FieldSelector = "metadata.name=" + pattern
+	}
+
+	resolvers := make([]*util.GVRResolver, len(types))
+	for i, resourceType := range types {
+		resolvers[i] = util.NewGVRResolver(resourceType)
+	}
+
+	found := false
+	for _, clusterInfo := range clusters {
+		if clusterInfo.DynamicClient() == nil {
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+
+		for i, resourceType := range types {
+			gvr, isNamespaced, err := resolvers[i].Resolve(clusterInfo.DiscoveryClient())
+			if err != nil {
+				warnClusterFailure(clusterInfo.Name, err, "discover resource type %s", resourceType)
+				continue
+			}
+
+			var resourceClient dynamic.ResourceInterface = clusterInfo.DynamicClient().Resource(gvr)
+			if isNamespaced && namespace != "" {
+				resourceClient = clusterInfo.DynamicClient().Resource(gvr).Namespace(namespace)
+			}
+			list, err := resourceClient.List(ctx, listOptions)
+			if err != nil {
+				warnClusterFailure(clusterInfo.Name, err, "list %s", resourceType)
+				continue
+			}
+
+			for _, item := range list.Items {
+				if matched, _ := path.Match(pattern, item.GetName()); !matched {
+					continue
+				}
+				found = true
+				ns := item.GetNamespace()
+				if ns == "" {
+					ns = "<none>"
+				}
+				age := util.FormatAge(item.GetCreationTimestamp().Time)
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", clusterInfo.Name, ns, item.GetKind(), item.GetName(), age)
+			}
+		}
+		cancel()
+	}
+
+	if !found {
+		fmt.Fprintf(tw, "No resource found.\n")
+	}
+
+	return nil
+}
@@ -1,25 +1,64 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/multicluster"
 	"kubectl-multi/pkg/util"
 )
 
+// streamRows controls whether the rows of each cluster are written as soon as that
+// cluster has answered; --no-stream buffers all clusters so columns line up
+var streamRows = true
+
+// showSecretValues disables the redaction of secret values in the -o output
+var showSecretValues bool
+
+// messageWidth truncates the MESSAGE column of events; 0 prints whole messages
+var messageWidth int
+
+// shownColumns and hiddenColumns select the columns of the get tables by header
+var shownColumns, hiddenColumns []string
+
+// diffWatch prints only the changed fields of modified objects in watches
+var diffWatch bool
+
+// serverAddress reads get from a running serve daemon instead of the clusters
+var serverAddress string
+
+// serverTokenFile holds the bearer token of the serve daemon of --server
+var serverTokenFile string
+
+// refreshEvery reprints the get output at this interval when set
+var refreshEvery time.Duration
+
+// notifyOn and notifyExec configure the notifications of watches about objects that
+// start matching field conditions
+var notifyOn, notifyExec string
+
+// flushClusterRows writes the rows collected for one cluster right away when streaming
+func flushClusterRows(tw tableWriter) {
+	if streamRows {
+		cluster.ClearProgress()
+		tw.Flush()
+	}
+}
+
 // Custom help function for get command
 func getHelpFunc(cmd *cobra.Command, args []string) {
 	// Get original kubectl help using the new implementation
@@ -77,6 +116,17 @@ func newGetCommand() *cobra.Command {
 	var showLabels bool
 	var watch bool
 	var watchOnly bool
+	var diffOnly bool
+	var refresh time.Duration
+	var notifyConditions string
+	var notifyCommand string
+	var noStream bool
+	var showSecrets bool
+	var maxMessage int
+	var columns []string
+	var hideColumns []string
+	var server string
+	var serverToken string
 
 	cmd := &cobra.Command{
 		Use:   "get [TYPE[.VERSION][.GROUP] [NAME | -l label] | TYPE[.VERSION][.GROUP]/NAME ...]",
@@ -112,22 +162,48 @@ kubectl multi get pods -o json
 
 # Get deployments in YAML format
 kubectl multi get deployments -o yaml
+
+# Read pods from a running kubectl multi serve instead of the clusters
+kubectl multi get pods -A --server localhost:8765
 `,
+		ValidArgsFunction: completeResourceTypes,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("resource type must be specified")
 			}
 
+			streamRows = !noStream
+			showSecretValues = showSecrets
+			messageWidth = maxMessage
+			shownColumns = columns
+			hiddenColumns = hideColumns
+			diffWatch = diffOnly
+			refreshEvery = refresh
+			notifyOn = notifyConditions
+			notifyExec = notifyCommand
+			serverAddress = server
+			serverTokenFile = serverToken
 			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
-			return handleGetCommand(args, outputFormat, selector, showLabels, watch, watchOnly, kubeconfig, remoteCtx, namespace, allNamespaces)
+			return handleGetCommand(args, outputFormat, selector, showLabels, watch || diffOnly, watchOnly, kubeconfig, remoteCtx, namespace, allNamespaces)
 		},
 	}
 
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "output format (json|yaml|wide|name|custom-columns=...|custom-columns-file=...|go-template=...|go-template-file=...|jsonpath=...|jsonpath-file=...)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "output format (json|yaml|wide|name|csv|custom-columns=...|custom-columns-file=...|go-template=...|go-template-file=...|jsonpath=...|jsonpath-file=...)")
 	cmd.Flags().StringVarP(&selector, "selector", "l", "", "selector (label query) to filter on")
 	cmd.Flags().BoolVar(&showLabels, "show-labels", false, "show all labels as the last column")
 	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "watch for changes to the requested object(s)")
 	cmd.Flags().BoolVar(&watchOnly, "watch-only", false, "watch for changes to the requested object(s), without listing/getting first")
+	cmd.Flags().BoolVar(&diffOnly, "diff-watch", false, "watch and print only the fields that changed in modified objects")
+	cmd.Flags().DurationVar(&refresh, "refresh", 0, "reprint the output every interval, e.g. 10s, reading from a cache instead of the clusters")
+	cmd.Flags().StringVar(&notifyConditions, "notify-on", "", "with --watch, notify when an object starts matching these field conditions, e.g. status.phase=Failed")
+	cmd.Flags().StringVar(&notifyCommand, "notify-exec", "", "shell command run for --notify-on matches, with the object in KUBECTL_MULTI_NOTIFY_* variables; --notify posts them as well")
+	cmd.Flags().BoolVar(&noStream, "no-stream", false, "wait for all clusters before printing, so no column widens for a later cluster")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "print the values of secrets with -o instead of their sizes")
+	cmd.Flags().StringSliceVar(&columns, "columns", nil, "only print these table columns, e.g. NAME,STATUS,AGE; CLUSTER is always printed")
+	cmd.Flags().StringSliceVar(&hideColumns, "hide-columns", nil, "do not print these table columns, e.g. IP,NODE")
+	cmd.Flags().StringVar(&server, "server", "", "read from a running kubectl multi serve at this address, e.g. localhost:8765, instead of the clusters")
+	cmd.Flags().StringVar(&serverToken, "server-token-file", "", "file with the bearer token of --server (defaults to the token serve generated, ~/.kubectl-multi/serve-token)")
+	cmd.Flags().IntVar(&maxMessage, "max-message-width", 0, "truncate the MESSAGE column of events to this many characters, 0 for no limit")
 
 	// Set custom help function
 	cmd.SetHelpFunc(getHelpFunc)
@@ -142,9 +218,23 @@ func handleGetCommand(args []string, outputFormat, selector string, showLabels,
 		resourceName = args[1]
 	}
 
-	// For watch operations, we don't support multi-cluster watch yet
+	if notifyOn != "" && !watch && !watchOnly {
+		return fmt.Errorf("--notify-on requires --watch")
+	}
+	if serverAddress != "" && diffWatch {
+		return fmt.Errorf("--diff-watch cannot be combined with --server, the server does not send the previous version of objects")
+	}
+	if refreshEvery > 0 {
+		if watch || watchOnly {
+			return fmt.Errorf("--refresh cannot be combined with --watch")
+		}
+		return handleGetRefresh(resourceType, resourceName, outputFormat, selector, showLabels, refreshEvery, kubeconfig, remoteCtx, namespace, allNamespaces)
+	}
 	if watch || watchOnly {
-		return fmt.Errorf("watch operations are not supported in multi-cluster mode")
+		return handleGetWatch(resourceType, resourceName, outputFormat, selector, showLabels, watchOnly, kubeconfig, remoteCtx, namespace, allNamespaces)
+	}
+	if serverAddress != "" {
+		return handleGetServer(resourceType, resourceName, outputFormat, selector, showLabels, namespace, allNamespaces)
 	}
 
 	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
@@ -152,14 +242,39 @@ func handleGetCommand(args []string, outputFormat, selector string, showLabels,
 		return fmt.Errorf("failed to discover clusters: %v", err)
 	}
 
+	// Formats kubectl has no printer for, like csv, are printed by a multicluster Printer
+	if outputFormat != "json" && outputFormat != "yaml" && multicluster.HasPrinter(outputFormat) {
+		return handleGetWithPrinter(clusters, resourceName, resourceType, outputFormat, selector, showLabels, namespace, allNamespaces)
+	}
+
+	// Columns of a registered handler replace the server-side table of -o wide, so
+	// their wide columns are printed too
+	if handler, ok := multicluster.LookupResourceHandler(resourceType); ok && handler.Get == nil && len(handler.Columns) > 0 && outputFormat == "wide" {
+		return handleGetWithPrinter(clusters, resourceName, resourceType, outputFormat, selector, showLabels, namespace, allNamespaces)
+	}
+
 	// If output format is provided use custom output format handler instead of default table format
 	if outputFormat != "" {
 		return handleGetWithOutputFormat(clusters, resourceName, resourceType, outputFormat, selector, namespace, allNamespaces)
 	}
 
-	tw := tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
+	tw := newTable(newRowEventWriter(util.GetOutputStream()), shownColumns, hiddenColumns)
 	defer tw.Flush()
 
+	// Handlers registered through the multicluster package replace the built-in ones
+	if handler, ok := multicluster.LookupResourceHandler(resourceType); ok && handler.Get != nil {
+		return handler.Get(tw, clusters, multicluster.GetOptions{
+			ResourceType:  resourceType,
+			ResourceName:  resourceName,
+			Selector:      selector,
+			ShowLabels:    showLabels,
+			Namespace:     namespace,
+			AllNamespaces: allNamespaces,
+		})
+	} else if ok && len(handler.Columns) > 0 {
+		return handleGetWithPrinter(clusters, resourceName, resourceType, "table", selector, showLabels, namespace, allNamespaces)
+	}
+
 	// Handle different resource types
 	switch strings.ToLower(resourceType) {
 
@@ -173,6 +288,8 @@ func handleGetCommand(args []string, outputFormat, selector string, showLabels,
 		return handleServiceAccountsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 	case "endpoints", "endpoint", "ep":
 		return handleEndpointsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
+	case "endpointslices", "endpointslice":
+		return handleEndpointSlicesGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 	case "resourcequotas", "resourcequota", "quota":
 		return handleResourceQuotasGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 	case "limitranges", "limitrange", "limits":
@@ -216,24 +333,27 @@ func handleGetCommand(args []string, outputFormat, selector string, showLabels,
 	}
 }
 
-func handleServiceAccountsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleServiceAccountsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		serviceAccounts, err := clusterInfo.Client.CoreV1().ServiceAccounts(targetNS).List(context.TODO(), metav1.ListOptions{
+		serviceAccounts, err := clusterInfo.Client().CoreV1().ServiceAccounts(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list serviceaccounts in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list serviceaccounts")
 			continue
 		}
 
@@ -261,7 +381,7 @@ func handleServiceAccountsGet(tw *tabwriter.Writer, clusters []cluster.ClusterIn
 			}
 
 			secrets := len(sa.Secrets)
-			age := duration.HumanDuration(time.Since(sa.CreationTimestamp.Time))
+			age := util.FormatAge(sa.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -283,6 +403,8 @@ func handleServiceAccountsGet(tw *tabwriter.Writer, clusters []cluster.ClusterIn
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -300,24 +422,27 @@ func handleServiceAccountsGet(tw *tabwriter.Writer, clusters []cluster.ClusterIn
 	return nil
 }
 
-func handleEndpointsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleEndpointsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		endpoints, err := clusterInfo.Client.CoreV1().Endpoints(targetNS).List(context.TODO(), metav1.ListOptions{
+		endpoints, err := clusterInfo.Client().CoreV1().Endpoints(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list endpoints in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list endpoints")
 			continue
 		}
 
@@ -344,22 +469,8 @@ func handleEndpointsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, re
 				continue
 			}
 
-			// Format endpoints
-			var endpointsList []string
-			for _, subset := range ep.Subsets {
-				for _, addr := range subset.Addresses {
-					for _, port := range subset.Ports {
-						endpointsList = append(endpointsList, fmt.Sprintf("%s:%d", addr.IP, port.Port))
-					}
-				}
-			}
-
-			endpointsStr := "<none>"
-			if len(endpointsList) > 0 {
-				endpointsStr = strings.Join(endpointsList, ",")
-			}
-
-			age := duration.HumanDuration(time.Since(ep.CreationTimestamp.Time))
+			endpointsStr := util.FormatEndpoints(&ep)
+			age := util.FormatAge(ep.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -381,6 +492,8 @@ func handleEndpointsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, re
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -398,24 +511,117 @@ func handleEndpointsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, re
 	return nil
 }
 
-func handleResourceQuotasGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleEndpointSlicesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		resourceQuotas, err := clusterInfo.Client.CoreV1().ResourceQuotas(targetNS).List(context.TODO(), metav1.ListOptions{
+		slices, err := clusterInfo.Client().DiscoveryV1().EndpointSlices(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list resourcequotas in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list endpointslices")
+			continue
+		}
+
+		if len(slices.Items) > 0 && !isHeaderPrint {
+			// Print header only once at top when any items is greater than 0.
+			if allNamespaces {
+				if showLabels {
+					fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tADDRESSTYPE\tPORTS\tENDPOINTS\tAGE\tLABELS\n")
+				} else {
+					fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tADDRESSTYPE\tPORTS\tENDPOINTS\tAGE\n")
+				}
+			} else {
+				if showLabels {
+					fmt.Fprintf(tw, "CLUSTER\tNAME\tADDRESSTYPE\tPORTS\tENDPOINTS\tAGE\tLABELS\n")
+				} else {
+					fmt.Fprintf(tw, "CLUSTER\tNAME\tADDRESSTYPE\tPORTS\tENDPOINTS\tAGE\n")
+				}
+			}
+			isHeaderPrint = true
+		}
+
+		for _, slice := range slices.Items {
+			if resourceName != "" && slice.Name != resourceName {
+				continue
+			}
+
+			ports := util.FormatEndpointSlicePorts(&slice)
+			endpointsStr := util.FormatEndpointSliceAddresses(&slice)
+			age := util.FormatAge(slice.CreationTimestamp.Time)
+
+			if allNamespaces {
+				if showLabels {
+					labels := util.FormatLabels(slice.Labels)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, slice.Namespace, slice.Name, slice.AddressType, ports, endpointsStr, age, labels)
+				} else {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, slice.Namespace, slice.Name, slice.AddressType, ports, endpointsStr, age)
+				}
+			} else {
+				if showLabels {
+					labels := util.FormatLabels(slice.Labels)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, slice.Name, slice.AddressType, ports, endpointsStr, age, labels)
+				} else {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, slice.Name, slice.AddressType, ports, endpointsStr, age)
+				}
+			}
+		}
+
+		flushClusterRows(tw)
+	}
+
+	if !isHeaderPrint {
+		// print no resource found if isHeaderPrint is still false at this point
+		if allNamespaces {
+			fmt.Fprintf(tw, "No resource found.\n")
+		} else {
+			if namespace == "" {
+				namespace = "default"
+			}
+			fmt.Fprintf(tw, "No resource found in %s namespace.\n", namespace)
+		}
+	}
+
+	return nil
+}
+
+func handleResourceQuotasGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+	isHeaderPrint := false
+
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
+		if allNamespaces {
+			targetNS = ""
+		}
+
+		resourceQuotas, err := clusterInfo.Client().CoreV1().ResourceQuotas(targetNS).List(ctx, metav1.ListOptions{
+			LabelSelector: selector,
+		})
+		cancel()
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "list resourcequotas")
 			continue
 		}
 
@@ -442,7 +648,7 @@ func handleResourceQuotasGet(tw *tabwriter.Writer, clusters []cluster.ClusterInf
 				continue
 			}
 
-			age := duration.HumanDuration(time.Since(rq.CreationTimestamp.Time))
+			age := util.FormatAge(rq.CreationTimestamp.Time)
 
 			// Format key quota metrics in a structured way
 			hardLimits := rq.Status.Hard
@@ -507,6 +713,8 @@ func handleResourceQuotasGet(tw *tabwriter.Writer, clusters []cluster.ClusterInf
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -524,24 +732,27 @@ func handleResourceQuotasGet(tw *tabwriter.Writer, clusters []cluster.ClusterInf
 	return nil
 }
 
-func handleLimitRangesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleLimitRangesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		limitRanges, err := clusterInfo.Client.CoreV1().LimitRanges(targetNS).List(context.TODO(), metav1.ListOptions{
+		limitRanges, err := clusterInfo.Client().CoreV1().LimitRanges(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list limitranges in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list limitranges")
 			continue
 		}
 
@@ -568,7 +779,7 @@ func handleLimitRangesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 				continue
 			}
 
-			age := duration.HumanDuration(time.Since(lr.CreationTimestamp.Time))
+			age := util.FormatAge(lr.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -590,6 +801,8 @@ func handleLimitRangesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -607,24 +820,27 @@ func handleLimitRangesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 	return nil
 }
 
-func handleIngressesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleIngressesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		ingresses, err := clusterInfo.Client.NetworkingV1().Ingresses(targetNS).List(context.TODO(), metav1.ListOptions{
+		ingresses, err := clusterInfo.Client().NetworkingV1().Ingresses(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list ingresses in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list ingresses")
 			continue
 		}
 
@@ -632,15 +848,15 @@ func handleIngressesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, re
 			// Print header only once at top when any items is greater than 0.
 			if allNamespaces {
 				if showLabels {
-					fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tHOSTS\tADDRESS\tPORTS\tAGE\tLABELS\n")
+					fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tCLASS\tHOSTS\tADDRESS\tPORTS\tAGE\tLABELS\n")
 				} else {
-					fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tHOSTS\tADDRESS\tPORTS\tAGE\n")
+					fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tCLASS\tHOSTS\tADDRESS\tPORTS\tAGE\n")
 				}
 			} else {
 				if showLabels {
-					fmt.Fprintf(tw, "CLUSTER\tNAME\tHOSTS\tADDRESS\tPORTS\tAGE\tLABELS\n")
+					fmt.Fprintf(tw, "CLUSTER\tNAME\tCLASS\tHOSTS\tADDRESS\tPORTS\tAGE\tLABELS\n")
 				} else {
-					fmt.Fprintf(tw, "CLUSTER\tNAME\tHOSTS\tADDRESS\tPORTS\tAGE\n")
+					fmt.Fprintf(tw, "CLUSTER\tNAME\tCLASS\tHOSTS\tADDRESS\tPORTS\tAGE\n")
 				}
 			}
 			isHeaderPrint = true
@@ -679,56 +895,33 @@ func handleIngressesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, re
 				}
 			}
 
-			// Gather ports
-			var ports []string
-			for _, rule := range ing.Spec.Rules {
-				if rule.HTTP != nil {
-					for range rule.HTTP.Paths {
-						// Ingress does not specify port directly; default is 80
-						ports = append(ports, "80")
-					}
-				}
-			}
-			// If TLS is specified, port 443 is implied
-			if len(ing.Spec.TLS) > 0 {
-				ports = append(ports, "443")
-			}
-			// Deduplicate ports
-			portMap := make(map[string]struct{})
-			for _, p := range ports {
-				portMap[p] = struct{}{}
-			}
-			var portList []string
-			for p := range portMap {
-				portList = append(portList, p)
-			}
-			portsStr := strings.Join(portList, ",")
-			if portsStr == "" {
-				portsStr = "<none>"
-			}
+			class := util.GetIngressClass(&ing)
+			portsStr := util.GetIngressPorts(&ing)
 
-			age := duration.HumanDuration(time.Since(ing.CreationTimestamp.Time))
+			age := util.FormatAge(ing.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
 					labels := util.FormatLabels(ing.Labels)
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, ing.Namespace, ing.Name, hostsStr, address, portsStr, age, labels)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, ing.Namespace, ing.Name, class, hostsStr, address, portsStr, age, labels)
 				} else {
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, ing.Namespace, ing.Name, hostsStr, address, portsStr, age)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, ing.Namespace, ing.Name, class, hostsStr, address, portsStr, age)
 				}
 			} else {
 				if showLabels {
 					labels := util.FormatLabels(ing.Labels)
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, ing.Name, hostsStr, address, portsStr, age, labels)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, ing.Name, class, hostsStr, address, portsStr, age, labels)
 				} else {
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, ing.Name, hostsStr, address, portsStr, age)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						clusterInfo.Name, ing.Name, class, hostsStr, address, portsStr, age)
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -746,24 +939,27 @@ func handleIngressesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, re
 	return nil
 }
 
-func handleJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleJobsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		jobs, err := clusterInfo.Client.BatchV1().Jobs(targetNS).List(context.TODO(), metav1.ListOptions{
+		jobs, err := clusterInfo.Client().BatchV1().Jobs(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list jobs in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list jobs")
 			continue
 		}
 
@@ -804,14 +1000,14 @@ func handleJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourc
 				if job.Status.CompletionTime != nil {
 					jobDuration = duration.HumanDuration(job.Status.CompletionTime.Sub(job.Status.StartTime.Time))
 				} else {
-					jobDuration = duration.HumanDuration(time.Since(job.Status.StartTime.Time))
+					jobDuration = util.FormatAge(job.Status.StartTime.Time)
 				}
 			} else {
 				jobDuration = "<unknown>"
 			}
 
 			// Calculate age
-			age := duration.HumanDuration(time.Since(job.CreationTimestamp.Time))
+			age := util.FormatAge(job.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -833,6 +1029,8 @@ func handleJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourc
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -850,7 +1048,7 @@ func handleJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourc
 	return nil
 }
 
-func handleAllGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleAllGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	fmt.Println("==> Pods")
 	if err := handlePodsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
@@ -858,98 +1056,84 @@ func handleAllGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resource
 	tw.Flush()
 
 	fmt.Println("\n==> Services")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleServicesGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> Deployments")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleDeploymentsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> Jobs")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleJobsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> CronJobs")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleCronJobsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> Nodes")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleNodesGet(tw, clusters, resourceName, selector, showLabels, outputFormat); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> ReplicaSets")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleReplicaSetsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> DaemonSets")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleDaemonSetsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> Namespaces")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleNamespacesGet(tw, clusters, resourceName, selector, showLabels, outputFormat); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> ConfigMaps")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleConfigMapsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> StatefulSets")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleStatefulSetsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> Secrets")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleSecretsGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> PersistentVolumes")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handlePVGet(tw, clusters, resourceName, selector, showLabels, outputFormat); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> PersistentVolumeClaims")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handlePVCGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
 	tw.Flush()
 
 	fmt.Println("\n==> Roles")
-	tw = tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
 	if err := handleRolesGet(tw, clusters, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces); err != nil {
 		return err
 	}
@@ -957,7 +1141,7 @@ func handleAllGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resource
 
 	return nil
 }
-func handleNodesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
+func handleNodesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
 	// Print header only once at the top
 	if showLabels {
 		fmt.Fprintf(tw, "CLUSTER\tNAME\tSTATUS\tROLES\tAGE\tVERSION\tLABELS\n")
@@ -966,15 +1150,18 @@ func handleNodesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resour
 	}
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		nodes, err := clusterInfo.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+		ctx, cancel := clusterInfo.RequestContext()
+
+		nodes, err := clusterInfo.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list nodes in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list nodes")
 			continue
 		}
 
@@ -985,7 +1172,7 @@ func handleNodesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resour
 
 			status := util.GetNodeStatus(node)
 			role := util.GetNodeRole(node)
-			age := duration.HumanDuration(time.Since(node.CreationTimestamp.Time))
+			age := util.FormatAge(node.CreationTimestamp.Time)
 			version := node.Status.NodeInfo.KubeletVersion
 
 			if showLabels {
@@ -997,28 +1184,33 @@ func handleNodesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resour
 					clusterInfo.Name, node.Name, status, role, age, version)
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 	return nil
 }
 
-func handlePodsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handlePodsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		pods, err := clusterInfo.Client.CoreV1().Pods(targetNS).List(context.TODO(), metav1.ListOptions{
+		pods, err := clusterInfo.Client().CoreV1().Pods(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list pods in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list pods")
 			continue
 		}
 
@@ -1046,9 +1238,9 @@ func handlePodsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourc
 			}
 
 			ready := fmt.Sprintf("%d/%d", util.GetPodReadyContainers(&pod), len(pod.Spec.Containers))
-			status := string(pod.Status.Phase)
+			status := util.GetPodStatus(&pod)
 			restarts := util.GetPodRestarts(&pod)
-			age := duration.HumanDuration(time.Since(pod.CreationTimestamp.Time))
+			age := util.FormatAge(pod.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1070,6 +1262,8 @@ func handlePodsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourc
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1086,24 +1280,27 @@ func handlePodsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourc
 	return nil
 }
 
-func handleServicesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleServicesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		services, err := clusterInfo.Client.CoreV1().Services(targetNS).List(context.TODO(), metav1.ListOptions{
+		services, err := clusterInfo.Client().CoreV1().Services(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list services in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list services")
 			continue
 		}
 
@@ -1135,7 +1332,7 @@ func handleServicesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, res
 			clusterIP := svc.Spec.ClusterIP
 			externalIP := util.GetServiceExternalIP(&svc)
 			ports := util.GetServicePorts(&svc)
-			age := duration.HumanDuration(time.Since(svc.CreationTimestamp.Time))
+			age := util.FormatAge(svc.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1157,6 +1354,8 @@ func handleServicesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, res
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1174,24 +1373,27 @@ func handleServicesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, res
 	return nil
 }
 
-func handleDeploymentsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleDeploymentsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		deployments, err := clusterInfo.Client.AppsV1().Deployments(targetNS).List(context.TODO(), metav1.ListOptions{
+		deployments, err := clusterInfo.Client().AppsV1().Deployments(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list deployments in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list deployments")
 			continue
 		}
 
@@ -1218,14 +1420,10 @@ func handleDeploymentsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 				continue
 			}
 
-			var replicas int32 = 0
-			if deploy.Spec.Replicas != nil {
-				replicas = *deploy.Spec.Replicas
-			}
-			ready := fmt.Sprintf("%d/%d", deploy.Status.ReadyReplicas, replicas)
+			ready := util.FormatReady(deploy.Status.ReadyReplicas, deploy.Spec.Replicas)
 			upToDate := fmt.Sprintf("%d", deploy.Status.UpdatedReplicas)
 			available := fmt.Sprintf("%d", deploy.Status.AvailableReplicas)
-			age := duration.HumanDuration(time.Since(deploy.CreationTimestamp.Time))
+			age := util.FormatAge(deploy.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1247,6 +1445,8 @@ func handleDeploymentsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1264,7 +1464,7 @@ func handleDeploymentsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 	return nil
 }
 
-func handleNamespacesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
+func handleNamespacesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
 	// Print header only once at the top
 	if showLabels {
 		fmt.Fprintf(tw, "CLUSTER\tNAME\tSTATUS\tAGE\tLABELS\n")
@@ -1273,15 +1473,18 @@ func handleNamespacesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 	}
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		namespaces, err := clusterInfo.Client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+		ctx, cancel := clusterInfo.RequestContext()
+
+		namespaces, err := clusterInfo.Client().CoreV1().Namespaces().List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list namespaces in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list namespaces")
 			continue
 		}
 
@@ -1291,7 +1494,7 @@ func handleNamespacesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 			}
 
 			status := string(ns.Status.Phase)
-			age := duration.HumanDuration(time.Since(ns.CreationTimestamp.Time))
+			age := util.FormatAge(ns.CreationTimestamp.Time)
 
 			if showLabels {
 				labels := util.FormatLabels(ns.Labels)
@@ -1302,28 +1505,33 @@ func handleNamespacesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 					clusterInfo.Name, ns.Name, status, age)
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 	return nil
 }
 
-func handleConfigMapsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleConfigMapsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		configMaps, err := clusterInfo.Client.CoreV1().ConfigMaps(targetNS).List(context.TODO(), metav1.ListOptions{
+		configMaps, err := clusterInfo.Client().CoreV1().ConfigMaps(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list configmaps in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list configmaps")
 			continue
 		}
 
@@ -1351,7 +1559,7 @@ func handleConfigMapsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 			}
 
 			dataCount := len(cm.Data) + len(cm.BinaryData)
-			age := duration.HumanDuration(time.Since(cm.CreationTimestamp.Time))
+			age := util.FormatAge(cm.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1373,6 +1581,8 @@ func handleConfigMapsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1389,24 +1599,27 @@ func handleConfigMapsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 	return nil
 }
 
-func handleSecretsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleSecretsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		secrets, err := clusterInfo.Client.CoreV1().Secrets(targetNS).List(context.TODO(), metav1.ListOptions{
+		secrets, err := clusterInfo.Client().CoreV1().Secrets(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list secrets in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list secrets")
 			continue
 		}
 
@@ -1435,7 +1648,7 @@ func handleSecretsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, reso
 
 			secretType := string(secret.Type)
 			dataCount := len(secret.Data)
-			age := duration.HumanDuration(time.Since(secret.CreationTimestamp.Time))
+			age := util.FormatAge(secret.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1457,6 +1670,8 @@ func handleSecretsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, reso
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1474,19 +1689,22 @@ func handleSecretsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, reso
 	return nil
 }
 
-func handlePVGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
+func handlePVGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		pvs, err := clusterInfo.Client.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{
+		ctx, cancel := clusterInfo.RequestContext()
+
+		pvs, err := clusterInfo.Client().CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list persistent volumes in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list persistent volumes")
 			continue
 		}
 
@@ -1511,7 +1729,7 @@ func handlePVGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceN
 			claim := util.GetPVClaim(&pv)
 			storageClass := util.GetPVStorageClass(&pv)
 			reason := pv.Status.Reason
-			age := duration.HumanDuration(time.Since(pv.CreationTimestamp.Time))
+			age := util.FormatAge(pv.CreationTimestamp.Time)
 
 			if showLabels {
 				labels := util.FormatLabels(pv.Labels)
@@ -1522,6 +1740,8 @@ func handlePVGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceN
 					clusterInfo.Name, pv.Name, capacity, accessModes, reclaimPolicy, status, claim, storageClass, reason, age)
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1531,24 +1751,27 @@ func handlePVGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceN
 	return nil
 }
 
-func handlePVCGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handlePVCGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		pvcs, err := clusterInfo.Client.CoreV1().PersistentVolumeClaims(targetNS).List(context.TODO(), metav1.ListOptions{
+		pvcs, err := clusterInfo.Client().CoreV1().PersistentVolumeClaims(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list persistent volume claims in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list persistent volume claims")
 			continue
 		}
 
@@ -1580,7 +1803,7 @@ func handlePVCGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resource
 			capacity := util.GetPVCCapacity(&pvc)
 			accessModes := util.GetPVCAccessModes(&pvc)
 			storageClass := util.GetPVCStorageClass(&pvc)
-			age := duration.HumanDuration(time.Since(pvc.CreationTimestamp.Time))
+			age := util.FormatAge(pvc.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1602,6 +1825,8 @@ func handlePVCGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resource
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1618,36 +1843,38 @@ func handlePVCGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resource
 	return nil
 }
 
-func handleGenericGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceType, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleGenericGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceType, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
+	resolver := util.NewGVRResolver(resourceType)
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.DynamicClient == nil {
+		if clusterInfo.DynamicClient() == nil {
 			continue
 		}
 
 		// Try to discover the resource
-		gvr, isNamespaced, err := util.DiscoverGVR(clusterInfo.DiscoveryClient, resourceType)
+		gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
 		if err != nil {
-			fmt.Printf("Warning: failed to discover resource %s in cluster %s: %v\n", resourceType, clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "discover resource %s", resourceType)
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		var list *unstructured.UnstructuredList
 
+		ctx, cancel := clusterInfo.RequestContext()
 		if isNamespaced && !allNamespaces && targetNS != "" {
-			list, err = clusterInfo.DynamicClient.Resource(gvr).Namespace(targetNS).List(context.TODO(), metav1.ListOptions{
+			list, err = clusterInfo.DynamicClient().Resource(gvr).Namespace(targetNS).List(ctx, metav1.ListOptions{
 				LabelSelector: selector,
 			})
 		} else {
-			list, err = clusterInfo.DynamicClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{
+			list, err = clusterInfo.DynamicClient().Resource(gvr).List(ctx, metav1.ListOptions{
 				LabelSelector: selector,
 			})
 		}
-
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list %s in cluster %s: %v\n", resourceType, clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list %s", resourceType)
 			continue
 		}
 
@@ -1674,7 +1901,7 @@ func handleGenericGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, reso
 				continue
 			}
 
-			age := duration.HumanDuration(time.Since(item.GetCreationTimestamp().Time))
+			age := util.FormatAge(item.GetCreationTimestamp().Time)
 
 			if isNamespaced && allNamespaces {
 				if showLabels {
@@ -1696,6 +1923,8 @@ func handleGenericGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, reso
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1714,24 +1943,91 @@ func handleGenericGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, reso
 	return nil
 }
 
-func handleReplicaSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+// handleGetWithPrinter prints a resource type with a multicluster Printer, using the
+// columns of a registered handler when there is one
+func handleGetWithPrinter(clusters []cluster.ClusterInfo, resourceName, resourceType, outputFormat, selector string, showLabels bool, namespace string, allNamespaces bool) error {
+	opts := multicluster.PrinterOptions{
+		WithNamespace: allNamespaces,
+		ShowLabels:    showLabels,
+		ShowColumns:   shownColumns,
+		HideColumns:   hiddenColumns,
+		MaxWidth:      tableWidth(),
+	}
+	if handler, ok := multicluster.LookupResourceHandler(resourceType); ok {
+		opts.Columns = handler.Columns
+	}
+	if outputFormat == "wide" {
+		outputFormat = "table"
+		opts.Wide = true
+	}
+	printer, err := multicluster.NewPrinter(outputFormat, util.GetOutputStream(), opts)
+	if err != nil {
+		return err
+	}
+	resolver := util.NewGVRResolver(resourceType)
+
+	if err := printer.WriteHeader(); err != nil {
+		return err
+	}
+	for _, clusterInfo := range clusters {
+		if clusterInfo.DynamicClient() == nil {
+			continue
+		}
+
+		gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "discover resource %s", resourceType)
+			continue
+		}
+
+		var resourceClient dynamic.ResourceInterface = clusterInfo.DynamicClient().Resource(gvr)
+		if isNamespaced && !allNamespaces {
+			resourceClient = clusterInfo.DynamicClient().Resource(gvr).Namespace(clusterInfo.TargetNamespace(namespace))
+		}
+		ctx, cancel := clusterInfo.RequestContext()
+		list, err := resourceClient.List(ctx, metav1.ListOptions{LabelSelector: selector})
+		cancel()
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "list %s", resourceType)
+			continue
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			if resourceName != "" && item.GetName() != resourceName {
+				continue
+			}
+			redactSecret(item)
+			if err := printer.WriteRow(clusterInfo.Name, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return printer.Flush()
+}
+
+func handleReplicaSetsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		replicaSets, err := clusterInfo.Client.AppsV1().ReplicaSets(targetNS).List(context.TODO(), metav1.ListOptions{
+		replicaSets, err := clusterInfo.Client().AppsV1().ReplicaSets(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list replicasets in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list replicasets")
 			continue
 		}
 
@@ -1764,7 +2060,7 @@ func handleReplicaSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 			}
 			current := rs.Status.Replicas
 			ready := rs.Status.ReadyReplicas
-			age := duration.HumanDuration(time.Since(rs.CreationTimestamp.Time))
+			age := util.FormatAge(rs.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1786,6 +2082,8 @@ func handleReplicaSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1802,24 +2100,27 @@ func handleReplicaSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 	return nil
 }
 
-func handleStatefulSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleStatefulSetsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		statefulSets, err := clusterInfo.Client.AppsV1().StatefulSets(targetNS).List(context.TODO(), metav1.ListOptions{
+		statefulSets, err := clusterInfo.Client().AppsV1().StatefulSets(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list statefulsets in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list statefulsets")
 			continue
 		}
 
@@ -1846,12 +2147,8 @@ func handleStatefulSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 				continue
 			}
 
-			var replicas int32 = 0
-			if sts.Spec.Replicas != nil {
-				replicas = *sts.Spec.Replicas
-			}
-			ready := fmt.Sprintf("%d/%d", sts.Status.ReadyReplicas, replicas)
-			age := duration.HumanDuration(time.Since(sts.CreationTimestamp.Time))
+			ready := util.FormatReady(sts.Status.ReadyReplicas, sts.Spec.Replicas)
+			age := util.FormatAge(sts.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1873,6 +2170,8 @@ func handleStatefulSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1889,24 +2188,27 @@ func handleStatefulSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo,
 	return nil
 }
 
-func handleDaemonSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleDaemonSetsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		daemonSets, err := clusterInfo.Client.AppsV1().DaemonSets(targetNS).List(context.TODO(), metav1.ListOptions{
+		daemonSets, err := clusterInfo.Client().AppsV1().DaemonSets(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list daemonsets in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list daemonsets")
 			continue
 		}
 
@@ -1948,7 +2250,7 @@ func handleDaemonSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 				nodeSelector = strings.Join(selectors, ",")
 			}
 
-			age := duration.HumanDuration(time.Since(ds.CreationTimestamp.Time))
+			age := util.FormatAge(ds.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -1970,6 +2272,8 @@ func handleDaemonSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -1986,24 +2290,27 @@ func handleDaemonSetsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, r
 	return nil
 }
 
-func handleCronJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleCronJobsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		cronJobs, err := clusterInfo.Client.BatchV1().CronJobs(targetNS).List(context.TODO(), metav1.ListOptions{
+		cronJobs, err := clusterInfo.Client().BatchV1().CronJobs(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list cronjobs in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list cronjobs")
 			continue
 		}
 
@@ -2040,10 +2347,10 @@ func handleCronJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, res
 
 			lastSchedule := "<none>"
 			if cj.Status.LastScheduleTime != nil {
-				lastSchedule = duration.HumanDuration(time.Since(cj.Status.LastScheduleTime.Time))
+				lastSchedule = util.FormatAge(cj.Status.LastScheduleTime.Time)
 			}
 
-			age := duration.HumanDuration(time.Since(cj.CreationTimestamp.Time))
+			age := util.FormatAge(cj.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -2065,6 +2372,8 @@ func handleCronJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, res
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -2081,36 +2390,39 @@ func handleCronJobsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, res
 	return nil
 }
 
-func handleEventsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleEventsGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	if allNamespaces {
 		if showLabels {
-			fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tLAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\tLABELS\n")
+			fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tLAST SEEN\tTYPE\tREASON\tOBJECT\tSOURCE\tCOUNT\tMESSAGE\tLABELS\n")
 		} else {
-			fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tLAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+			fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tLAST SEEN\tTYPE\tREASON\tOBJECT\tSOURCE\tCOUNT\tMESSAGE\n")
 		}
 	} else {
 		if showLabels {
-			fmt.Fprintf(tw, "CLUSTER\tLAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\tLABELS\n")
+			fmt.Fprintf(tw, "CLUSTER\tLAST SEEN\tTYPE\tREASON\tOBJECT\tSOURCE\tCOUNT\tMESSAGE\tLABELS\n")
 		} else {
-			fmt.Fprintf(tw, "CLUSTER\tLAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+			fmt.Fprintf(tw, "CLUSTER\tLAST SEEN\tTYPE\tREASON\tOBJECT\tSOURCE\tCOUNT\tMESSAGE\n")
 		}
 	}
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		events, err := clusterInfo.Client.CoreV1().Events(targetNS).List(context.TODO(), metav1.ListOptions{
+		events, err := clusterInfo.Client().CoreV1().Events(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list events in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list events")
 			continue
 		}
 
@@ -2120,59 +2432,64 @@ func handleEventsGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resou
 			}
 
 			lastSeen := "<unknown>"
-			if !event.LastTimestamp.IsZero() {
-				lastSeen = duration.HumanDuration(time.Since(event.LastTimestamp.Time)) + " ago"
-			} else if !event.FirstTimestamp.IsZero() {
-				lastSeen = duration.HumanDuration(time.Since(event.FirstTimestamp.Time)) + " ago"
+			if lastTime := util.GetEventLastSeen(&event); !lastTime.IsZero() {
+				lastSeen = util.FormatAge(lastTime) + " ago"
 			}
 
 			eventType := event.Type
 			reason := event.Reason
-			object := fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name)
-			message := event.Message
+			object := fmt.Sprintf("%s/%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name)
+			source := util.GetEventSource(&event)
+			count := util.GetEventCount(&event)
+			message := util.Truncate(strings.Join(strings.Fields(event.Message), " "), messageWidth)
 
 			if allNamespaces {
 				if showLabels {
 					labels := util.FormatLabels(event.Labels)
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, event.Namespace, lastSeen, eventType, reason, object, message, labels)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+						clusterInfo.Name, event.Namespace, lastSeen, eventType, reason, object, source, count, message, labels)
 				} else {
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, event.Namespace, lastSeen, eventType, reason, object, message)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+						clusterInfo.Name, event.Namespace, lastSeen, eventType, reason, object, source, count, message)
 				}
 			} else {
 				if showLabels {
 					labels := util.FormatLabels(event.Labels)
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, lastSeen, eventType, reason, object, message, labels)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+						clusterInfo.Name, lastSeen, eventType, reason, object, source, count, message, labels)
 				} else {
-					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
-						clusterInfo.Name, lastSeen, eventType, reason, object, message)
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+						clusterInfo.Name, lastSeen, eventType, reason, object, source, count, message)
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 	return nil
 }
 
-func handleNetworkPoliciesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleNetworkPoliciesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		networkPolicies, err := clusterInfo.Client.NetworkingV1().NetworkPolicies(targetNS).List(context.TODO(), metav1.ListOptions{
+		networkPolicies, err := clusterInfo.Client().NetworkingV1().NetworkPolicies(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list networkpolicies in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list networkpolicies")
 			continue
 		}
 
@@ -2215,7 +2532,7 @@ func handleNetworkPoliciesGet(tw *tabwriter.Writer, clusters []cluster.ClusterIn
 				policyTypes = strings.Join(types, ",")
 			}
 
-			age := duration.HumanDuration(time.Since(np.CreationTimestamp.Time))
+			age := util.FormatAge(np.CreationTimestamp.Time)
 
 			if allNamespaces {
 				if showLabels {
@@ -2237,6 +2554,8 @@ func handleNetworkPoliciesGet(tw *tabwriter.Writer, clusters []cluster.ClusterIn
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -2254,24 +2573,27 @@ func handleNetworkPoliciesGet(tw *tabwriter.Writer, clusters []cluster.ClusterIn
 	return nil
 }
 
-func handleRolesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleRolesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		targetNS := cluster.GetTargetNamespace(namespace)
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
 		if allNamespaces {
 			targetNS = ""
 		}
 
-		roles, err := clusterInfo.Client.RbacV1().Roles(targetNS).List(context.TODO(), metav1.ListOptions{
+		roles, err := clusterInfo.Client().RbacV1().Roles(targetNS).List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list roles in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list roles")
 			continue
 		}
 
@@ -2318,6 +2640,8 @@ func handleRolesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resour
 				}
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -2335,19 +2659,22 @@ func handleRolesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resour
 	return nil
 }
 
-func handleStorageClassesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
+func handleStorageClassesGet(tw tableWriter, clusters []cluster.ClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
 	isHeaderPrint := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
+		if clusterInfo.Client() == nil {
 			continue
 		}
 
-		storageClasses, err := clusterInfo.Client.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{
+		ctx, cancel := clusterInfo.RequestContext()
+
+		storageClasses, err := clusterInfo.Client().StorageV1().StorageClasses().List(ctx, metav1.ListOptions{
 			LabelSelector: selector,
 		})
+		cancel()
 		if err != nil {
-			fmt.Printf("Warning: failed to list storageclasses in cluster %s: %v\n", clusterInfo.Name, err)
+			warnClusterFailure(clusterInfo.Name, err, "list storageclasses")
 			continue
 		}
 
@@ -2385,7 +2712,7 @@ func handleStorageClassesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInf
 			}
 
 			// Calculate age
-			age := duration.HumanDuration(time.Since(sc.CreationTimestamp.Time))
+			age := util.FormatAge(sc.CreationTimestamp.Time)
 
 			if showLabels {
 				labels := util.FormatLabels(sc.Labels)
@@ -2396,6 +2723,8 @@ func handleStorageClassesGet(tw *tabwriter.Writer, clusters []cluster.ClusterInf
 					clusterInfo.Name, sc.Name, sc.Provisioner, reclaimPolicy, volumeBindingMode, allowVolumeExpansion, age)
 			}
 		}
+
+		flushClusterRows(tw)
 	}
 
 	if !isHeaderPrint {
@@ -2423,9 +2752,19 @@ func handleGetWithOutputFormat(clusters []cluster.ClusterInfo, resourceName, res
 		}
 	}
 
+	printer, err := multicluster.NewResourcePrinter(outputFormat)
+	if err != nil {
+		return err
+	}
+	resolver := util.NewGVRResolver(resourceType)
+
 	// Identify ITS (control) cluster context
 	itsContext := remoteCtx
 
+	if mergedLayout() {
+		return printMergedObjects(clusters, itsContext, printer, resolver, resourceType, outputFormat, resourceName, selector, namespace, allNamespaces)
+	}
+
 	// Build maps for quick lookup
 	contextToCluster := make(map[string]cluster.ClusterInfo)
 	for _, c := range clusters {
@@ -2434,13 +2773,9 @@ func handleGetWithOutputFormat(clusters []cluster.ClusterInfo, resourceName, res
 
 	// 1. Run for current context (if present)
 	if cinfo, ok := contextToCluster[currentContext]; ok {
-		kubectlArgs := buildKubectlGetArgs(resourceType, resourceName, outputFormat, selector, namespace, allNamespaces, cinfo.Context)
-		output, err := runKubectlGet(kubectlArgs, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
+		printClusterHeader(cinfo.Context + namespaceNote(cinfo, namespace, allNamespaces))
+		if err := printClusterObjects(cinfo, printer, resolver, outputFormat, resourceName, selector, namespace, allNamespaces); err != nil {
+			warnClusterFailure(cinfo.Name, err, "get %s", resourceType)
 		}
 		fmt.Println()
 	}
@@ -2450,20 +2785,16 @@ func handleGetWithOutputFormat(clusters []cluster.ClusterInfo, resourceName, res
 		if c.Context == currentContext || c.Context == itsContext {
 			continue
 		}
-		kubectlArgs := buildKubectlGetArgs(resourceType, resourceName, outputFormat, selector, namespace, allNamespaces, c.Context)
-		output, err := runKubectlGet(kubectlArgs, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", c.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
+		printClusterHeader(c.Context + namespaceNote(c, namespace, allNamespaces))
+		if err := printClusterObjects(c, printer, resolver, outputFormat, resourceName, selector, namespace, allNamespaces); err != nil {
+			warnClusterFailure(c.Name, err, "get %s", resourceType)
 		}
 		fmt.Println()
 	}
 
 	// 3. Print warning for ITS (control) cluster
 	if cinfo, ok := contextToCluster[itsContext]; ok {
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
+		printClusterHeader(cinfo.Context)
 		fmt.Printf("Cannot perform this operation on ITS (control) cluster: %s\n", cinfo.Context)
 		fmt.Println()
 	}
@@ -2471,44 +2802,212 @@ func handleGetWithOutputFormat(clusters []cluster.ClusterInfo, resourceName, res
 	return nil
 }
 
-// buildKubectlGetArgs builds kubectl get command arguments
-func buildKubectlGetArgs(resourceType, resourceName, outputFormat, selector, namespace string, allNamespaces bool, context string) []string {
-	args := []string{"get", resourceType}
+// printMergedObjects prints the objects of all clusters as one table for -o wide and as
+// one list for the other formats, so the output can be sorted and parsed as a whole
+func printMergedObjects(clusters []cluster.ClusterInfo, itsContext string, printer printers.ResourcePrinter, resolver *util.GVRResolver, resourceType, outputFormat, resourceName, selector, namespace string, allNamespaces bool) error {
+	merged := &metav1.Table{}
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+	withNamespace := false
 
-	if resourceName != "" {
-		args = append(args, resourceName)
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Context == itsContext {
+			continue
+		}
+		if clusterInfo.DynamicClient() == nil {
+			printWarning("skipping cluster %s (no client available)", clusterInfo.Name)
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+		gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+		if err != nil {
+			cancel()
+			warnClusterFailure(clusterInfo.Name, err, "discover resource type %s", resourceType)
+			continue
+		}
+		targetNS := ""
+		if isNamespaced && !allNamespaces {
+			targetNS = clusterInfo.TargetNamespace(namespace)
+		}
+
+		if outputFormat == "wide" {
+			table, err := getServerTable(ctx, clusterInfo, gvr, targetNS, resourceName, selector)
+			cancel()
+			if err != nil {
+				warnClusterFailure(clusterInfo.Name, err, "list %s", gvr.Resource)
+				continue
+			}
+			if merged.ColumnDefinitions == nil {
+				withNamespace = isNamespaced && allNamespaces
+				merged.ColumnDefinitions = []metav1.TableColumnDefinition{{Name: "Cluster", Type: "string"}}
+				if withNamespace {
+					merged.ColumnDefinitions = append(merged.ColumnDefinitions, metav1.TableColumnDefinition{Name: "Namespace", Type: "string"})
+				}
+				merged.ColumnDefinitions = append(merged.ColumnDefinitions, table.ColumnDefinitions...)
+			}
+			for _, row := range table.Rows {
+				cells := []interface{}{clusterInfo.Name}
+				if withNamespace {
+					cells = append(cells, tableRowNamespace(row))
+				}
+				row.Cells = append(cells, row.Cells...)
+				merged.Rows = append(merged.Rows, row)
+			}
+			continue
+		}
+
+		var resourceClient dynamic.ResourceInterface = clusterInfo.DynamicClient().Resource(gvr)
+		if targetNS != "" {
+			resourceClient = clusterInfo.DynamicClient().Resource(gvr).Namespace(targetNS)
+		}
+		var items []unstructured.Unstructured
+		if resourceName != "" {
+			// The object usually exists in some clusters only
+			obj, err := resourceClient.Get(ctx, resourceName, metav1.GetOptions{})
+			if err == nil {
+				items = append(items, *obj)
+			} else if !apierrors.IsNotFound(err) {
+				cancel()
+				warnClusterFailure(clusterInfo.Name, err, "get %s %s", gvr.Resource, resourceName)
+				continue
+			}
+		} else {
+			objs, err := resourceClient.List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				cancel()
+				warnClusterFailure(clusterInfo.Name, err, "list %s", gvr.Resource)
+				continue
+			}
+			items = objs.Items
+		}
+		cancel()
+
+		for _, item := range items {
+			annotations := item.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[multicluster.ClusterAnnotation] = clusterInfo.Name
+			item.SetAnnotations(annotations)
+			redactSecret(&item)
+			multicluster.EmitObjectRow(clusterInfo.Name, &item)
+			list.Items = append(list.Items, item)
+		}
 	}
 
-	if outputFormat != "" {
-		args = append(args, "-o", outputFormat)
+	if outputFormat == "wide" {
+		if len(merged.Rows) == 0 {
+			fmt.Println("No resource found.")
+			return nil
+		}
+		return printers.NewTablePrinter(printers.PrintOptions{Wide: true}).PrintObj(merged, newRowEventWriter(util.GetOutputStream()))
 	}
+	return printer.PrintObj(list, util.GetOutputStream())
+}
 
-	if selector != "" {
-		args = append(args, "-l", selector)
+// redactSecret replaces the values of a secret with their sizes unless --show-secrets
+// is set, so output shared on screen or in logs does not leak credentials
+func redactSecret(obj *unstructured.Unstructured) {
+	if !showSecretValues {
+		multicluster.RedactSecret(obj)
 	}
+}
 
-	if allNamespaces {
-		args = append(args, "-A")
-	} else if namespace != "" {
-		args = append(args, "-n", namespace)
+// tableRowNamespace returns the namespace of a server table row from the object
+// metadata the server includes with each row
+func tableRowNamespace(row metav1.TableRow) string {
+	var meta metav1.PartialObjectMetadata
+	if err := json.Unmarshal(row.Object.Raw, &meta); err != nil {
+		return ""
+	}
+	return meta.Namespace
+}
+
+// printClusterObjects fetches the requested objects from one cluster with its
+// dynamic client and prints them with the given printer
+func printClusterObjects(clusterInfo cluster.ClusterInfo, printer printers.ResourcePrinter, resolver *util.GVRResolver, outputFormat, resourceName, selector, namespace string, allNamespaces bool) error {
+	if clusterInfo.DynamicClient() == nil {
+		return fmt.Errorf("no client available")
+	}
+
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+	if err != nil {
+		return err
+	}
+
+	targetNS := ""
+	if isNamespaced && !allNamespaces {
+		targetNS = clusterInfo.TargetNamespace(namespace)
+	}
+
+	if outputFormat == "wide" {
+		table, err := getServerTable(ctx, clusterInfo, gvr, targetNS, resourceName, selector)
+		if err != nil {
+			return err
+		}
+		printer = printers.NewTablePrinter(printers.PrintOptions{Wide: true, WithNamespace: isNamespaced && allNamespaces})
+		return printer.PrintObj(table, util.GetOutputStream())
+	}
+
+	var resourceClient dynamic.ResourceInterface = clusterInfo.DynamicClient().Resource(gvr)
+	if targetNS != "" {
+		resourceClient = clusterInfo.DynamicClient().Resource(gvr).Namespace(targetNS)
 	}
 
-	args = append(args, "--context", context)
+	if resourceName != "" {
+		obj, err := resourceClient.Get(ctx, resourceName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		redactSecret(obj)
+		multicluster.EmitObjectRow(clusterInfo.Name, obj)
+		return printer.PrintObj(obj, util.GetOutputStream())
+	}
 
-	return args
+	list, err := resourceClient.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		redactSecret(&list.Items[i])
+		multicluster.EmitObjectRow(clusterInfo.Name, &list.Items[i])
+	}
+	return printer.PrintObj(list, util.GetOutputStream())
 }
 
-// runKubectlGet runs a kubectl command with the given args and kubeconfig, returns output and error
-func runKubectlGet(args []string, kubeconfig string) (string, error) {
-	cmd := exec.Command("kubectl", args...)
-	if kubeconfig != "" {
-		cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfig)
+// getServerTable asks the API server to render the requested objects as a table,
+// which carries the same columns kubectl prints for -o wide
+func getServerTable(ctx context.Context, clusterInfo cluster.ClusterInfo, gvr schema.GroupVersionResource, namespace, resourceName, selector string) (*metav1.Table, error) {
+	segments := []string{"/api", gvr.Version}
+	if gvr.Group != "" {
+		segments = []string{"/apis", gvr.Group, gvr.Version}
+	}
+	if namespace != "" {
+		segments = append(segments, "namespaces", namespace)
 	}
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return stdout.String() + stderr.String(), err
+	segments = append(segments, gvr.Resource)
+	if resourceName != "" {
+		segments = append(segments, resourceName)
+	}
+
+	req := clusterInfo.Client().Discovery().RESTClient().Get().
+		AbsPath(segments...).
+		SetHeader("Accept", "application/json;as=Table;v=v1;g=meta.k8s.io")
+	if selector != "" && resourceName == "" {
+		req = req.Param("labelSelector", selector)
+	}
+
+	raw, err := req.Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	table := &metav1.Table{}
+	if err := json.Unmarshal(raw, table); err != nil {
+		return nil, fmt.Errorf("failed to decode server table: %v", err)
 	}
-	return stdout.String(), nil
+	return table, nil
 }
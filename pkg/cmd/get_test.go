@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestRedactSecret checks that secret values are replaced by their sizes
+func TestRedactSecret(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name": "db",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"aHVudGVyMg=="}}`,
+			},
+		},
+		"data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+		"stringData": map[string]interface{}{"user": "admin"},
+	}}
+
+	redactSecret(secret)
+
+	data, _, _ := unstructured.NestedStringMap(secret.Object, "data")
+	if data["password"] != "<redacted: 7 bytes>" {
+		t.Errorf("data.password = %q, want %q", data["password"], "<redacted: 7 bytes>")
+	}
+	stringData, _, _ := unstructured.NestedStringMap(secret.Object, "stringData")
+	if stringData["user"] != "<redacted: 5 bytes>" {
+		t.Errorf("stringData.user = %q, want %q", stringData["user"], "<redacted: 5 bytes>")
+	}
+	if got := secret.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]; got != "<redacted>" {
+		t.Errorf("last-applied annotation = %q, want it redacted", got)
+	}
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "ConfigMap",
+		"data": map[string]interface{}{"key": "value"},
+	}}
+	redactSecret(configMap)
+	if got, _, _ := unstructured.NestedString(configMap.Object, "data", "key"); got != "value" {
+		t.Errorf("configmap data = %q, want it unchanged", got)
+	}
+}
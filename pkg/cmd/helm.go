@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newHelmCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "helm",
+		Short: "Inspect Helm releases across managed clusters",
+	}
+	cmd.AddCommand(newHelmListCommand())
+	return cmd
+}
+
+func newHelmListCommand() *cobra.Command {
+	var chart string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the Helm releases of all managed clusters",
+		Long: `List the Helm releases of all managed clusters with their chart, chart version and
+status. The releases are read from the release secrets Helm stores in each cluster, so
+helm does not need to be installed.`,
+		Example: `# List the releases of all namespaces
+kubectl multi helm list -A
+
+# Show which clusters run which version of the nginx chart
+kubectl multi helm list -A --chart nginx`,
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
+			return handleHelmListCommand(chart, kubeconfig, remoteCtx, namespace, allNamespaces)
+		},
+	}
+
+	cmd.Flags().StringVar(&chart, "chart", "", "only list the releases of this chart")
+
+	return cmd
+}
+
+func handleHelmListCommand(chart, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	isHeaderPrint := false
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+
+		targetNS := clusterInfo.TargetNamespace(namespace)
+		if allNamespaces {
+			targetNS = ""
+		}
+
+		secrets, err := clusterInfo.Client().CoreV1().Secrets(targetNS).List(ctx, metav1.ListOptions{
+			LabelSelector: "owner=helm",
+			FieldSelector: "type=" + util.HelmReleaseSecretType,
+		})
+		cancel()
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "list helm release secrets")
+			continue
+		}
+
+		// Every revision has its own secret; only the latest one is listed
+		latest := make(map[string]*util.HelmRelease)
+		for _, secret := range secrets.Items {
+			release, err := util.DecodeHelmRelease(secret.Data["release"])
+			if err != nil {
+				printWarning("skipping secret %s/%s in cluster %s: %v", secret.Namespace, secret.Name, clusterInfo.Name, err)
+				continue
+			}
+			if chart != "" && release.Chart.Metadata.Name != chart {
+				continue
+			}
+			key := release.Namespace + "/" + release.Name
+			if current, ok := latest[key]; !ok || release.Revision > current.Revision {
+				latest[key] = release
+			}
+		}
+
+		keys := make([]string, 0, len(latest))
+		for key := range latest {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			release := latest[key]
+			if !isHeaderPrint {
+				if allNamespaces {
+					fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tREVISION\tCHART\tVERSION\tAPP VERSION\tSTATUS\tUPDATED\n")
+				} else {
+					fmt.Fprintf(tw, "CLUSTER\tNAME\tREVISION\tCHART\tVERSION\tAPP VERSION\tSTATUS\tUPDATED\n")
+				}
+				isHeaderPrint = true
+			}
+			metadata := release.Chart.Metadata
+			updated := util.FormatAge(release.Info.LastDeployed)
+			if allNamespaces {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", clusterInfo.Name, release.Namespace, release.Name,
+					release.Revision, metadata.Name, metadata.Version, metadata.AppVersion, release.Info.Status, updated)
+			} else {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", clusterInfo.Name, release.Name,
+					release.Revision, metadata.Name, metadata.Version, metadata.AppVersion, release.Info.Status, updated)
+			}
+		}
+	}
+
+	if !isHeaderPrint {
+		fmt.Fprintf(tw, "No resource found.\n")
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/config"
+	"kubectl-multi/pkg/multicluster"
+)
+
+// hookEnvPrefix is the prefix of the environment variables passed to the hooks of the
+// config file. It differs from envPrefix, so a hook running kubectl multi does not
+// change that command's flags.
+const hookEnvPrefix = "KUBECTL_MULTI_HOOK_"
+
+var (
+	hookCommand string
+	hookArgs    []string
+	activeHooks []multicluster.Hooks
+)
+
+// commandName returns the path of cmd below kubectl multi, e.g. "rollout restart"
+func commandName(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// startHooks runs the pre-command hooks registered through the multicluster package
+// and in the config file, and installs their pre-cluster hooks as cluster veto
+func startHooks(cmd *cobra.Command, args []string) error {
+	hookCommand = commandName(cmd)
+	hookArgs = args
+	activeHooks = multicluster.RegisteredHooks()
+	for _, hook := range userConfig.Hooks {
+		if hookApplies(hook, hookCommand) {
+			activeHooks = append(activeHooks, shellHooks(hook))
+		}
+	}
+	if len(activeHooks) == 0 {
+		return nil
+	}
+
+	for _, hooks := range activeHooks {
+		if hooks.PreCommand == nil {
+			continue
+		}
+		if err := hooks.PreCommand(hookCommand, args); err != nil {
+			return fmt.Errorf("pre-command hook stopped %q: %v", hookCommand, err)
+		}
+	}
+	cluster.SetClusterVeto(func(clusterInfo cluster.ClusterInfo) error {
+		for _, hooks := range activeHooks {
+			if hooks.PreCluster == nil {
+				continue
+			}
+			if err := hooks.PreCluster(hookCommand, clusterInfo); err != nil {
+				return fmt.Errorf("refused by pre-cluster hook: %v", err)
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+// finishHooks runs the post-cluster hooks for every cluster the command used and then
+// the post-command hooks. It runs after the command returned, including on errors.
+func finishHooks(err error) {
+	if len(activeHooks) == 0 {
+		return
+	}
+
+	succeeded, failed, skipped := cluster.Results()
+	for _, hooks := range activeHooks {
+		if hooks.PostCluster != nil {
+			for _, name := range succeeded {
+				hooks.PostCluster(hookCommand, name, nil)
+			}
+			for _, failure := range failed {
+				hooks.PostCluster(hookCommand, failure.Cluster, failure.Err)
+			}
+			for _, name := range skipped {
+				hooks.PostCluster(hookCommand, name, fmt.Errorf("skipped after an earlier failure"))
+			}
+		}
+		if hooks.PostCommand != nil {
+			hooks.PostCommand(hookCommand, hookArgs, err)
+		}
+	}
+	activeHooks = nil
+}
+
+// hookApplies reports whether a hook of the config file covers the command
+func hookApplies(hook config.Hook, command string) bool {
+	if len(hook.Commands) == 0 {
+		return true
+	}
+	for _, name := range hook.Commands {
+		if name == command {
+			return true
+		}
+	}
+	return false
+}
+
+// shellHooks turns a hook of the config file into hooks running its shell commands
+func shellHooks(hook config.Hook) multicluster.Hooks {
+	var hooks multicluster.Hooks
+	if hook.PreCommand != "" {
+		hooks.PreCommand = func(command string, args []string) error {
+			return runHook(hook.PreCommand, hookEnv(command, args, ""))
+		}
+	}
+	if hook.PostCommand != "" {
+		hooks.PostCommand = func(command string, args []string, err error) {
+			if hookErr := runHook(hook.PostCommand, append(hookEnv(command, args, ""), resultEnv(err)...)); hookErr != nil {
+				printWarning("post-command hook failed: %v", hookErr)
+			}
+		}
+	}
+	if hook.PreCluster != "" {
+		hooks.PreCluster = func(command string, clusterInfo cluster.ClusterInfo) error {
+			return runHook(hook.PreCluster, hookEnv(command, nil, clusterInfo.Name))
+		}
+	}
+	if hook.PostCluster != "" {
+		hooks.PostCluster = func(command, clusterName string, err error) {
+			if hookErr := runHook(hook.PostCluster, append(hookEnv(command, nil, clusterName), resultEnv(err)...)); hookErr != nil {
+				printWarning("post-cluster hook failed for cluster %s: %v", clusterName, hookErr)
+			}
+		}
+	}
+	return hooks
+}
+
+// hookEnv returns the environment variables describing the command to a hook
+func hookEnv(command string, args []string, clusterName string) []string {
+	env := []string{hookEnvPrefix + "COMMAND=" + command}
+	if len(args) > 0 {
+		env = append(env, hookEnvPrefix+"ARGS="+strings.Join(args, " "))
+	}
+	if clusterName != "" {
+		env = append(env, hookEnvPrefix+"CLUSTER="+clusterName)
+	}
+	return env
+}
+
+// resultEnv returns the environment variables describing the outcome to a post hook
+func resultEnv(err error) []string {
+	if err != nil {
+		return []string{hookEnvPrefix + "RESULT=error", hookEnvPrefix + "ERROR=" + err.Error()}
+	}
+	return []string{hookEnvPrefix + "RESULT=success"}
+}
+
+// runHook runs a shell command of the config file. Its output goes to stderr, so it
+// does not mix with the data printed by the command.
+func runHook(script string, env []string) error {
+	c := exec.Command("sh", "-c", script)
+	c.Env = append(os.Environ(), env...)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	cluster.ClearProgress()
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %v", script, err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// minKubectlMinor is the oldest kubectl minor version KubeStellar is documented with
+const minKubectlMinor = 27
+
+var bindingPolicyGVR = schema.GroupVersionResource{Group: "control.kubestellar.io", Version: "v1alpha1", Resource: "bindingpolicies"}
+
+// RunChecks validates the prerequisites of the installation and of using the plugin
+// afterwards, and prints how to fix each problem. ITS and WDS contexts that do not
+// exist yet are only warnings, since the installation creates them.
+func (o *InstallOptions) RunChecks(kubeconfig, remoteCtx string) error {
+	checks := []doctorCheck{checkKubectl(), checkHelm()}
+
+	hosting, kubeconfigCheck := checkKubeconfig(kubeconfig)
+	checks = append(checks, kubeconfigCheck)
+	if hosting != "" {
+		checks = append(checks, checkControlPlane("hosting", kubeconfig, hosting, schema.GroupVersionResource{}, []installPermission{
+			{Verb: "create", GVR: schema.GroupVersionResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}},
+			{Verb: "create", GVR: schema.GroupVersionResource{Resource: "namespaces"}},
+			{Verb: "create", GVR: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}},
+		})...)
+	}
+
+	itses := o.ITSes
+	if len(itses) == 0 && remoteCtx != "" {
+		itses = []string{remoteCtx}
+	}
+	for _, its := range itses {
+		checks = append(checks, checkControlPlane("its "+its, kubeconfig, its, cluster.ManagedClusterGVR, []installPermission{
+			{Verb: "list", GVR: cluster.ManagedClusterGVR},
+			{Verb: "patch", GVR: cluster.ManagedClusterGVR},
+		})...)
+	}
+	wdses := o.WDSes
+	if len(wdses) == 0 {
+		wdses = []string{defaultWDSContext}
+	}
+	for _, wds := range wdses {
+		checks = append(checks, checkControlPlane("wds "+wds, kubeconfig, wds, bindingPolicyGVR, []installPermission{
+			{Verb: "create", GVR: bindingPolicyGVR},
+		})...)
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CHECK\tSTATUS\tDETAILS\n")
+	failed := 0
+	var fixes []string
+	for _, check := range checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", check.Name, check.Status, check.Detail)
+		if check.Status == doctorFail {
+			failed++
+		}
+		if check.Status != doctorOK && check.Fix != "" {
+			fixes = append(fixes, fmt.Sprintf("  %s: %s", check.Name, check.Fix))
+		}
+	}
+	tw.Flush()
+
+	if len(fixes) > 0 {
+		fmt.Fprintf(o.Out, "\nTo fix:\n%s\n", strings.Join(fixes, "\n"))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	fmt.Fprintf(o.Out, "\nAll prerequisites are met.\n")
+	return nil
+}
+
+// installPermission is a permission checked with a SelfSubjectAccessReview
+type installPermission struct {
+	Verb string
+	GVR  schema.GroupVersionResource
+}
+
+func checkKubectl() doctorCheck {
+	check := doctorCheck{Name: "kubectl", Fix: "install kubectl 1." + strconv.Itoa(minKubectlMinor) + " or newer, see https://kubernetes.io/docs/tasks/tools/"}
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		check.Status, check.Detail = doctorFail, "not found in PATH"
+		return check
+	}
+	output, err := exec.Command("kubectl", "version", "--client", "-o", "json").Output()
+	if err != nil {
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("kubectl version failed: %v", err)
+		return check
+	}
+	var version struct {
+		ClientVersion struct {
+			GitVersion string `json:"gitVersion"`
+			Minor      string `json:"minor"`
+		} `json:"clientVersion"`
+	}
+	if err := json.Unmarshal(output, &version); err != nil {
+		check.Status, check.Detail = doctorWarn, fmt.Sprintf("cannot read the version: %v", err)
+		return check
+	}
+	check.Detail = version.ClientVersion.GitVersion
+	// Minor versions of some distributions carry a suffix, like 29+
+	minor, err := strconv.Atoi(strings.TrimSuffix(version.ClientVersion.Minor, "+"))
+	if err == nil && minor < minKubectlMinor {
+		check.Status, check.Detail = doctorWarn, check.Detail+" is older than 1."+strconv.Itoa(minKubectlMinor)
+	}
+	return check
+}
+
+func checkHelm() doctorCheck {
+	check := doctorCheck{Name: "helm", Fix: "install Helm 3, see https://helm.sh/docs/intro/install/"}
+	if _, err := exec.LookPath("helm"); err != nil {
+		check.Status, check.Detail = doctorFail, "not found in PATH"
+		return check
+	}
+	output, err := exec.Command("helm", "version", "--short").Output()
+	if err != nil {
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("helm version failed: %v", err)
+		return check
+	}
+	check.Detail = strings.TrimSpace(string(output))
+	if !strings.HasPrefix(check.Detail, "v3.") {
+		check.Status, check.Detail = doctorFail, check.Detail+" is not Helm 3"
+	}
+	return check
+}
+
+// checkKubeconfig returns the current context, the hosting cluster the chart is
+// installed into
+func checkKubeconfig(kubeconfig string) (string, doctorCheck) {
+	check := doctorCheck{Name: "kubeconfig"}
+	loading := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loading.ExplicitPath = kubeconfig
+	}
+	rawCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loading, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("cannot load: %v", err)
+		check.Fix = "point --kubeconfig or KUBECONFIG at a valid kubeconfig file"
+		return "", check
+	}
+	if len(rawCfg.Contexts) == 0 {
+		check.Status, check.Detail = doctorFail, "no contexts"
+		check.Fix = "create a hosting cluster first, e.g. kind create cluster --name kubeflex"
+		return "", check
+	}
+	if _, ok := rawCfg.Contexts[rawCfg.CurrentContext]; !ok {
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("current context %q does not exist", rawCfg.CurrentContext)
+		check.Fix = "select the hosting cluster: kubectl config use-context CONTEXT"
+		return "", check
+	}
+	check.Detail = fmt.Sprintf("%d contexts, hosting cluster is %s", len(rawCfg.Contexts), rawCfg.CurrentContext)
+	return rawCfg.CurrentContext, check
+}
+
+// checkControlPlane checks that a context is reachable, serves the CRD of crd when one
+// is given and allows the permissions
+func checkControlPlane(name, kubeconfig, context string, crd schema.GroupVersionResource, permissions []installPermission) []doctorCheck {
+	// The hosting cluster is the current context and must exist; control planes
+	// are created by the installation
+	optional := !crd.Empty()
+	clusterInfo, err := cluster.ContextClient(kubeconfig, context)
+	if err != nil {
+		if optional {
+			return []doctorCheck{{Name: name, Status: doctorWarn, Detail: "no context " + context,
+				Fix: "create it with install --its/--wds, then run: kflex ctx --overwrite-existing-context " + context}}
+		}
+		return []doctorCheck{{Name: name, Status: doctorFail, Detail: err.Error(), Fix: "fix the context in the kubeconfig"}}
+	}
+
+	version, err := clusterInfo.Client().Discovery().ServerVersion()
+	if err != nil {
+		return []doctorCheck{{Name: name, Status: doctorFail, Detail: fmt.Sprintf("unreachable: %v", err),
+			Fix: "check that the cluster of context " + context + " is running and its address is reachable"}}
+	}
+	checks := []doctorCheck{{Name: name, Status: doctorOK, Detail: fmt.Sprintf("reachable (%s)", version.GitVersion)}}
+
+	if optional {
+		check := doctorCheck{Name: name + " crd", Status: doctorOK, Detail: crd.Resource + "." + crd.Group + " served"}
+		if _, _, err := util.NewGVRResolver(crd.Resource + "." + crd.Group).Resolve(clusterInfo.DiscoveryClient()); err != nil {
+			check.Status, check.Detail = doctorFail, crd.Resource+"."+crd.Group+" is not served"
+			check.Fix = "context " + context + " is not a KubeStellar control plane of this kind; check the name or re-run install"
+		}
+		checks = append(checks, check)
+	}
+
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+	var denied []string
+	for _, permission := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:     permission.Verb,
+					Group:    permission.GVR.Group,
+					Resource: permission.GVR.Resource,
+				},
+			},
+		}
+		result, err := clusterInfo.Client().AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			checks = append(checks, doctorCheck{Name: name + " rbac", Status: doctorWarn, Detail: fmt.Sprintf("cannot check permissions: %v", err)})
+			return checks
+		}
+		if !result.Status.Allowed {
+			denied = append(denied, describeAccess(permission.Verb, permission.GVR, "", ""))
+		}
+	}
+	if len(denied) > 0 {
+		checks = append(checks, doctorCheck{Name: name + " rbac", Status: doctorFail, Detail: strings.Join(denied, "; "),
+			Fix: "use a context with cluster-admin rights on " + context})
+	} else {
+		checks = append(checks, doctorCheck{Name: name + " rbac", Status: doctorOK, Detail: "permitted"})
+	}
+	return checks
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/homedir"
+)
+
+// demoWECs are the kind clusters install --demo registers as workload execution clusters
+var demoWECs = []string{"cluster1", "cluster2"}
+
+// ingressNginxManifest is the ingress controller the hosting cluster exposes the
+// control planes with, as in the KubeStellar getting started guide
+const ingressNginxManifest = "https://raw.githubusercontent.com/kubernetes/ingress-nginx/controller-v1.12.1/deploy/static/provider/kind/deploy.yaml"
+
+// demoKindConfig maps the ingress of the hosting cluster to the external port of the
+// control planes
+const demoKindConfig = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+  kubeadmConfigPatches:
+  - |
+    kind: InitConfiguration
+    nodeRegistration:
+      kubeletExtraArgs:
+        node-labels: "ingress-ready=true"
+  extraPortMappings:
+  - containerPort: 443
+    hostPort: %d
+    protocol: TCP
+`
+
+// demoStatePath returns the file listing the kind clusters and contexts created by
+// install --demo, which uninstall --include-demo deletes. Reused clusters are not
+// listed.
+func demoStatePath() string {
+	return filepath.Join(homedir.HomeDir(), ".kubectl-multi", "demo-clusters")
+}
+
+// RunDemo creates a local KubeStellar environment: a kind hosting cluster with one ITS
+// and one WDS installed by the core chart, and two kind clusters registered with the
+// ITS as WECs. Clusters that already exist are reused, so a failed demo can be run
+// again. The contexts are created in the --kubeconfig of the plugin, if given.
+func (o *InstallOptions) RunDemo(ctx context.Context) error {
+	for _, tool := range []string{"kind", "kubectl", "helm", "kflex", "clusteradm"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("%s is required by --demo and not in PATH, see https://docs.kubestellar.io/ for the prerequisites", tool)
+		}
+	}
+	if len(o.ITSes) == 0 {
+		o.ITSes = []string{"its1"}
+	}
+	if len(o.WDSes) == 0 {
+		o.WDSes = []string{defaultWDSContext}
+	}
+	its := o.ITSes[0]
+	createdHosting := false
+	hostingCtx := "kind-" + o.ClusterName
+	o.HostContainer = o.ClusterName + "-control-plane"
+
+	existing, err := o.kindClusters(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Creating the hosting cluster %s...\n", o.ClusterName)
+	if !existing[o.ClusterName] {
+		if err := o.createHostingCluster(ctx); err != nil {
+			return err
+		}
+		if err := o.recordDemo("cluster", o.ClusterName); err != nil {
+			return err
+		}
+		createdHosting = true
+	} else {
+		fmt.Fprintf(o.Out, "Reusing the existing kind cluster %s\n", o.ClusterName)
+	}
+	if err := o.runDemoCommand(ctx, "kubectl", "config", "use-context", hostingCtx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "\nInstalling KubeStellar core...\n")
+	if err := o.runDemoCommand(ctx, "helm", o.buildHelmArgs()...); err != nil {
+		return err
+	}
+	if err := o.runDemoCommand(ctx, "kflex", "ctx", "--set-current-for-hosting"); err != nil {
+		return err
+	}
+	for _, cp := range append(append([]string{}, o.ITSes...), o.WDSes...) {
+		if err := o.runDemoCommand(ctx, "kubectl", "--context", hostingCtx, "wait", "controlplane/"+cp, "--for=condition=Ready", "--timeout="+o.Timeout); err != nil {
+			return err
+		}
+		if err := o.runDemoCommand(ctx, "kflex", "ctx", "--overwrite-existing-context", cp); err != nil {
+			return err
+		}
+		if createdHosting {
+			if err := o.recordDemo("context", cp); err != nil {
+				return err
+			}
+		}
+	}
+	if err := o.runDemoCommand(ctx, "kflex", "ctx", "--set-current-for-hosting"); err != nil {
+		return err
+	}
+	// The hub of the ITS is ready to register clusters once its init job completed
+	if err := o.runDemoCommand(ctx, "kubectl", "--context", hostingCtx, "wait", "-n", its+"-system", "job.batch/its-hub-init", "--for=condition=Complete", "--timeout="+o.Timeout); err != nil {
+		return err
+	}
+
+	for _, wec := range demoWECs {
+		fmt.Fprintf(o.Out, "\nCreating the WEC %s...\n", wec)
+		if !existing[wec] {
+			if err := o.runDemoCommand(ctx, "kind", "create", "cluster", "--name", wec); err != nil {
+				return err
+			}
+			if err := o.recordDemo("cluster", wec); err != nil {
+				return err
+			}
+			if err := o.runDemoCommand(ctx, "kubectl", "config", "rename-context", "kind-"+wec, wec); err != nil {
+				return err
+			}
+			if err := o.recordDemo("context", wec); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintf(o.Out, "Reusing the existing kind cluster %s\n", wec)
+		}
+	}
+	if err := o.runDemoCommand(ctx, "kubectl", "config", "use-context", hostingCtx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "\nRegistering the WECs with %s...\n", its)
+	if err := o.registerDemoWECs(ctx, its); err != nil {
+		return err
+	}
+
+	if o.DryRun {
+		return nil
+	}
+	fmt.Fprintf(o.Out, "\n✅ The KubeStellar demo environment is ready!\n")
+	fmt.Fprintf(o.Out, "\n📋 Contexts:\n")
+	fmt.Fprintf(o.Out, "   %-12s hosting cluster\n", hostingCtx)
+	fmt.Fprintf(o.Out, "   %-12s inventory and transport space\n", its)
+	for _, wds := range o.WDSes {
+		fmt.Fprintf(o.Out, "   %-12s workload description space\n", wds)
+	}
+	for _, wec := range demoWECs {
+		fmt.Fprintf(o.Out, "   %-12s workload execution cluster\n", wec)
+	}
+	fmt.Fprintf(o.Out, "\nTry: kubectl multi --remote-context %s get nodes\n", its)
+	fmt.Fprintf(o.Out, "Remove it with: kubectl multi uninstall --include-demo\n")
+	return nil
+}
+
+// kindClusters returns the names of the existing kind clusters
+func (o *InstallOptions) kindClusters(ctx context.Context) (map[string]bool, error) {
+	output, err := exec.CommandContext(ctx, "kind", "get", "clusters").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kind clusters: %w", err)
+	}
+	clusters := map[string]bool{}
+	for _, name := range strings.Fields(string(output)) {
+		clusters[name] = true
+	}
+	return clusters, nil
+}
+
+// createHostingCluster creates the kind hosting cluster with an ingress controller
+// passing TLS through to the control planes
+func (o *InstallOptions) createHostingCluster(ctx context.Context) error {
+	configFile, err := os.CreateTemp("", "kubectl-multi-kind-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(configFile.Name())
+	if _, err := fmt.Fprintf(configFile, demoKindConfig, o.ExternalPort); err != nil {
+		configFile.Close()
+		return err
+	}
+	if err := configFile.Close(); err != nil {
+		return err
+	}
+
+	if err := o.runDemoCommand(ctx, "kind", "create", "cluster", "--name", o.ClusterName, "--config", configFile.Name()); err != nil {
+		return err
+	}
+	hostingCtx := "kind-" + o.ClusterName
+	steps := [][]string{
+		{"--context", hostingCtx, "apply", "-f", ingressNginxManifest},
+		{"--context", hostingCtx, "patch", "deployment", "ingress-nginx-controller", "-n", "ingress-nginx", "--type=json",
+			"-p", `[{"op":"add","path":"/spec/template/spec/containers/0/args/-","value":"--enable-ssl-passthrough"}]`},
+		{"--context", hostingCtx, "rollout", "status", "deployment/ingress-nginx-controller", "-n", "ingress-nginx", "--timeout=" + o.Timeout},
+	}
+	for _, step := range steps {
+		if err := o.runDemoCommand(ctx, "kubectl", step...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerDemoWECs joins the WECs to the hub of the ITS, accepts their certificate
+// signing requests and labels their ManagedClusters like the getting started guide,
+// so the BindingPolicy examples select them
+func (o *InstallOptions) registerDemoWECs(ctx context.Context, its string) error {
+	join := "clusteradm join --hub-token TOKEN --hub-apiserver URL --cluster-name <cluster_name>"
+	if !o.DryRun {
+		var err error
+		if join, err = hubJoinCommand(ctx, o.Kubeconfig, its); err != nil {
+			return err
+		}
+	}
+
+	for _, wec := range demoWECs {
+		args := append(joinArgs(join, wec, wec, o.Kubeconfig), "--force-internal-endpoint-lookup")
+		if err := o.runDemoCommand(ctx, "clusteradm", args...); err != nil {
+			return err
+		}
+	}
+
+	// The certificate signing requests of the klusterlets appear a while after joining
+	accept := []string{"--context", its, "accept", "--clusters", strings.Join(demoWECs, ",")}
+	timeout, err := time.ParseDuration(o.Timeout)
+	if err != nil {
+		timeout = 10 * time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := o.runDemoCommand(ctx, "clusteradm", accept...)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		fmt.Fprintf(o.Out, "Waiting for the certificate signing requests of the WECs...\n")
+		select {
+		case <-time.After(10 * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for _, wec := range demoWECs {
+		if err := o.runDemoCommand(ctx, "kubectl", "--context", its, "label", "managedcluster", wec,
+			"location-group=edge", "name="+wec, "--overwrite"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runDemoCommand runs one step of the demo with its output shown, or only prints it
+// with --dry-run. kind, kubectl, helm, kflex and clusteradm all read the kubeconfig
+// from KUBECONFIG.
+func (o *InstallOptions) runDemoCommand(ctx context.Context, name string, args ...string) error {
+	if o.DryRun {
+		fmt.Fprintf(o.Out, "Dry run - would execute: %s %s\n", name, strings.Join(args, " "))
+		return nil
+	}
+	fmt.Fprintf(o.Out, "Executing: %s %s\n", name, strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, name, args...)
+	if o.Kubeconfig != "" {
+		cmd.Env = append(os.Environ(), "KUBECONFIG="+o.Kubeconfig)
+	}
+	cmd.Stdout = o.Out
+	cmd.Stderr = o.ErrOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w", name, args[0], err)
+	}
+	return nil
+}
+
+// demoObject is a kind cluster or a kubeconfig context created by install --demo, with
+// the kubeconfig it was created in, empty for the default one
+type demoObject struct {
+	name       string
+	kubeconfig string
+}
+
+// recordDemo adds a kind cluster or a kubeconfig context to the demo state file
+// unless it is listed. Each line of the file is the kind, cluster or context, and
+// the name; a kubeconfig line sets the kubeconfig of the lines after it.
+func (o *InstallOptions) recordDemo(kind, name string) error {
+	if o.DryRun {
+		return nil
+	}
+	kubeconfig := o.Kubeconfig
+	if kubeconfig != "" {
+		var err error
+		if kubeconfig, err = filepath.Abs(kubeconfig); err != nil {
+			return err
+		}
+	}
+	clusters, contexts, last, err := readDemoState()
+	if err != nil {
+		return err
+	}
+	recorded := clusters
+	if kind == "context" {
+		recorded = contexts
+	}
+	for _, listed := range recorded {
+		if listed == (demoObject{name: name, kubeconfig: kubeconfig}) {
+			return nil
+		}
+	}
+	lines := fmt.Sprintf("%s %s\n", kind, name)
+	if kubeconfig != last {
+		lines = strings.TrimSpace("kubeconfig "+kubeconfig) + "\n" + lines
+	}
+	path := demoStatePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(lines); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readDemoState returns the kind clusters and the kubeconfig contexts created by
+// install --demo, and the kubeconfig of the last line
+func readDemoState() (clusters, contexts []demoObject, kubeconfig string, err error) {
+	data, err := os.ReadFile(demoStatePath())
+	if os.IsNotExist(err) {
+		return nil, nil, "", nil
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		kind, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		switch {
+		case kind == "kubeconfig":
+			kubeconfig = name
+		case !ok:
+		case kind == "cluster":
+			clusters = append(clusters, demoObject{name: name, kubeconfig: kubeconfig})
+		case kind == "context":
+			contexts = append(contexts, demoObject{name: name, kubeconfig: kubeconfig})
+		}
+	}
+	return clusters, contexts, kubeconfig, nil
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// clusterNameLabel labels the certificate signing requests of a klusterlet with the
+// name of its cluster
+const clusterNameLabel = "open-cluster-management.io/cluster-name"
+
+// bootstrapServiceAccount is the identity the bootstrap kubeconfig of clusteradm join
+// gives the klusterlet before it has a certificate
+const bootstrapServiceAccount = "system:serviceaccount:open-cluster-management:cluster-bootstrap"
+
+func newJoinCommand() *cobra.Command {
+	var name string
+	var labels []string
+	var internalEndpoint bool
+	var dryRun bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "join CONTEXT",
+		Short: "Register a cluster with the ITS as a WEC",
+		Long: `Register the cluster of a kubeconfig context with the ITS of --remote-context as a
+workload execution cluster (WEC), in one step:
+
+  1. the OCM klusterlet agent is installed in the cluster with clusteradm join,
+     using a join token of the ITS
+  2. the certificate signing requests of the agent are approved on the ITS and the
+     ManagedCluster is accepted
+  3. the ManagedCluster is labeled, so BindingPolicies can select it
+
+The cluster is named after its context unless --name is given. Joining a cluster
+that is already registered only updates its labels.`,
+		Example: `# Register a cluster and label it for the BindingPolicies of the edge clusters
+kubectl multi join cluster3 -l location-group=edge
+
+# Register a kind cluster, whose API server is only reachable inside docker
+kubectl multi join kind-lab --name lab --internal-endpoint`,
+		Annotations: map[string]string{mutatingAnnotation: "true"},
+		Args:        cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleJoinCommand(cmd.Context(), args[0], name, labels, internalEndpoint, dryRun, timeout, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "name of the ManagedCluster (defaults to the context)")
+	cmd.Flags().StringSliceVarP(&labels, "label", "l", nil, "KEY=VALUE labels of the ManagedCluster, e.g. location-group=edge")
+	cmd.Flags().BoolVar(&internalEndpoint, "internal-endpoint", false, "let the agent reach the ITS through its in-cluster endpoint, for kind clusters")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the steps without running them")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "how long to wait for the agent to request registration")
+	return cmd
+}
+
+func handleJoinCommand(ctx context.Context, wecContext, name string, labelArgs []string, internalEndpoint, dryRun bool, timeout time.Duration, kubeconfig, remoteCtx string) error {
+	if name == "" {
+		name = wecContext
+	}
+	labels := map[string]interface{}{}
+	for _, arg := range labelArgs {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid label %q, expected KEY=VALUE", arg)
+		}
+		labels[key] = value
+	}
+	if _, err := exec.LookPath("clusteradm"); err != nil {
+		return fmt.Errorf("clusteradm is required to install the klusterlet and not in PATH, see https://open-cluster-management.io/getting-started/installation/start-the-control-plane/")
+	}
+	if err := checkMutationTargets([]cluster.ClusterInfo{{Name: name, Context: wecContext}}, remoteCtx); err != nil {
+		return err
+	}
+
+	its, err := cluster.ContextClient(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the ITS: %v", err)
+	}
+	if _, err := cluster.ContextClient(kubeconfig, wecContext); err != nil {
+		return err
+	}
+
+	managedClusters := its.DynamicClient().Resource(cluster.ManagedClusterGVR)
+	_, err = managedClusters.Get(ctx, name, metav1.GetOptions{})
+	registered := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get managed cluster %s: %v", name, err)
+	}
+
+	if registered {
+		fmt.Printf("managedcluster/%s is already registered\n", name)
+	} else {
+		join := "clusteradm join --hub-token TOKEN --hub-apiserver URL --cluster-name <cluster_name>"
+		if !dryRun {
+			if join, err = hubJoinCommand(ctx, kubeconfig, remoteCtx); err != nil {
+				return err
+			}
+		}
+		args := joinArgs(join, name, wecContext, kubeconfig)
+		if internalEndpoint {
+			args = append(args, "--force-internal-endpoint-lookup")
+		}
+		if dryRun {
+			fmt.Printf("Dry run - would execute: clusteradm %s\n", strings.Join(args, " "))
+			fmt.Printf("Dry run - would approve the certificate signing requests of %s and accept managedcluster/%s on %s\n", name, name, remoteCtx)
+		} else {
+			fmt.Printf("Installing the klusterlet in context %s...\n", wecContext)
+			clusteradm := exec.CommandContext(ctx, "clusteradm", args...)
+			clusteradm.Stdout, clusteradm.Stderr = os.Stdout, os.Stderr
+			err := clusteradm.Run()
+			writeAudit(name, wecContext, []string{"klusterlet/klusterlet"}, false, err)
+			if err != nil {
+				return fmt.Errorf("clusteradm join failed: %v", err)
+			}
+
+			fmt.Printf("Waiting for %s to request registration...\n", name)
+			err = acceptManagedCluster(ctx, its, name, timeout)
+			writeAudit("", remoteCtx, []string{"managedcluster/" + name}, false, err)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("managedcluster/%s accepted\n", name)
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("Dry run - would label managedcluster/%s with %s\n", name, strings.Join(labelArgs, ","))
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": labels},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %v", err)
+	}
+	_, err = managedClusters.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	writeAudit("", remoteCtx, []string{"managedcluster/" + name}, false, err)
+	if err != nil {
+		return fmt.Errorf("failed to label managed cluster %s: %v", name, err)
+	}
+	fmt.Printf("managedcluster/%s labeled\n", name)
+	return nil
+}
+
+// hubJoinCommand returns the clusteradm join command printed by the hub of the ITS,
+// with the placeholder <cluster_name> for the name of the joining cluster
+func hubJoinCommand(ctx context.Context, kubeconfig, its string) (string, error) {
+	args := []string{"--context", its, "get", "token"}
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+	output, err := exec.CommandContext(ctx, "clusteradm", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the join token of %s: %w", its, err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "clusteradm join") {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("clusteradm get token printed no join command for %s", its)
+}
+
+// joinArgs returns the clusteradm arguments joining the cluster of a context as name.
+// The agent runs in singleton mode, which KubeStellar expects.
+func joinArgs(join, name, wecContext, kubeconfig string) []string {
+	args := strings.Fields(strings.ReplaceAll(join, "<cluster_name>", name))[1:]
+	args = append(args, "--context", wecContext, "--singleton")
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+	return args
+}
+
+// acceptManagedCluster approves the pending certificate signing requests of a joining
+// cluster and sets hubAcceptsClient on its ManagedCluster, like clusteradm accept. The
+// agent creates both a while after joining, so they are polled for until timeout.
+func acceptManagedCluster(ctx context.Context, its cluster.ClusterInfo, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	csrs := its.Client().CertificatesV1().CertificateSigningRequests()
+	approved := false
+	skipped := make(map[string]bool)
+	for {
+		list, err := csrs.List(ctx, metav1.ListOptions{LabelSelector: clusterNameLabel + "=" + name})
+		if err != nil {
+			if ctx.Err() == nil {
+				return fmt.Errorf("failed to list certificate signing requests: %v", err)
+			}
+			list = &certificatesv1.CertificateSigningRequestList{}
+		}
+		for i := range list.Items {
+			csr := &list.Items[i]
+			if !isKlusterletCSR(csr, name) {
+				if !skipped[csr.Name] {
+					printWarning("not approving certificate signing request %s: it is labeled for %s but was not requested by its klusterlet (signer %s, user %s)", csr.Name, name, csr.Spec.SignerName, csr.Spec.Username)
+					skipped[csr.Name] = true
+				}
+				continue
+			}
+			if csrHasCondition(csr, certificatesv1.CertificateDenied) {
+				return fmt.Errorf("certificate signing request %s of %s was denied; delete it so the klusterlet requests a new one", csr.Name, name)
+			}
+			if csrHasCondition(csr, certificatesv1.CertificateApproved) {
+				approved = true
+				continue
+			}
+			csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+				Type:    certificatesv1.CertificateApproved,
+				Status:  corev1.ConditionTrue,
+				Reason:  "KubectlMultiJoin",
+				Message: "approved by kubectl multi join",
+			})
+			if _, err := csrs.UpdateApproval(ctx, csr.Name, csr, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to approve certificate signing request %s: %v", csr.Name, err)
+			}
+			fmt.Printf("certificatesigningrequest/%s approved\n", csr.Name)
+			approved = true
+		}
+
+		if approved {
+			patch := []byte(`{"spec":{"hubAcceptsClient":true}}`)
+			_, err := its.DynamicClient().Resource(cluster.ManagedClusterGVR).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+			if err == nil {
+				return nil
+			}
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to accept managed cluster %s: %v", name, err)
+			}
+		}
+
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return fmt.Errorf("%s did not request registration within %s; check the klusterlet in its open-cluster-management-agent namespace", name, timeout)
+		}
+	}
+}
+
+// isKlusterletCSR reports whether a certificate signing request asks for an API
+// server client certificate and was created by the klusterlet of the cluster, with
+// its bootstrap identity or its agent identity when it renews, like clusteradm accept
+// checks. The cluster name label alone can be set by anyone who may create requests.
+func isKlusterletCSR(csr *certificatesv1.CertificateSigningRequest, name string) bool {
+	if csr.Spec.SignerName != certificatesv1.KubeAPIServerClientSignerName {
+		return false
+	}
+	return csr.Spec.Username == bootstrapServiceAccount ||
+		strings.HasPrefix(csr.Spec.Username, "system:bootstrap:") ||
+		strings.HasPrefix(csr.Spec.Username, "system:open-cluster-management:"+name+":")
+}
+
+// csrHasCondition reports whether a certificate signing request has a condition, such
+// as approved or denied
+func csrHasCondition(csr *certificatesv1.CertificateSigningRequest, conditionType certificatesv1.RequestConditionType) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+)
+
+// TestIsKlusterletCSR ensures only the client certificate requests of the klusterlet
+// of a cluster are approved, whatever their labels
+func TestIsKlusterletCSR(t *testing.T) {
+	tests := []struct {
+		name     string
+		signer   string
+		username string
+		want     bool
+	}{
+		{"bootstrap service account", certificatesv1.KubeAPIServerClientSignerName, bootstrapServiceAccount, true},
+		{"bootstrap token", certificatesv1.KubeAPIServerClientSignerName, "system:bootstrap:abcdef", true},
+		{"agent renewal", certificatesv1.KubeAPIServerClientSignerName, "system:open-cluster-management:cluster1:agent", true},
+		{"agent of another cluster", certificatesv1.KubeAPIServerClientSignerName, "system:open-cluster-management:cluster2:agent", false},
+		{"other user", certificatesv1.KubeAPIServerClientSignerName, "alice", false},
+		{"other signer", certificatesv1.KubeletServingSignerName, bootstrapServiceAccount, false},
+	}
+
+	for _, tt := range tests {
+		csr := &certificatesv1.CertificateSigningRequest{
+			Spec: certificatesv1.CertificateSigningRequestSpec{SignerName: tt.signer, Username: tt.username},
+		}
+		if got := isKlusterletCSR(csr, "cluster1"); got != tt.want {
+			t.Errorf("%s: isKlusterletCSR() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+
+	"github.com/spf13/cobra"
+)
+
+func newKustomizeCommand() *cobra.Command {
+	var preview bool
+
+	cmd := &cobra.Command{
+		Use:   "kustomize DIR",
+		Short: "Render a kustomization and preview applying it across managed clusters",
+		Long: `Render a kustomization directory like kubectl kustomize.
+
+With --preview the rendered objects are applied to every managed cluster as a server-side
+dry run, and the number of objects that would be created, configured or left unchanged
+is shown per cluster. Nothing is changed.`,
+		Example: `# Print the rendered manifests
+kubectl multi kustomize overlays/prod
+
+# Show what applying them would do on each cluster
+kubectl multi kustomize overlays/prod --preview`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			return handleKustomizeCommand(args[0], preview, kubeconfig, remoteCtx, namespace)
+		},
+	}
+
+	cmd.Flags().BoolVar(&preview, "preview", false, "show the server-side dry-run result of applying the rendered objects on every cluster")
+
+	return cmd
+}
+
+func handleKustomizeCommand(dir string, preview bool, kubeconfig, remoteCtx, namespace string) error {
+	rendered, err := runKubectl([]string{"kustomize", dir}, kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %v: %s", dir, err, strings.TrimSpace(rendered))
+	}
+	if !preview {
+		fmt.Print(rendered)
+		return nil
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	// kubectl apply reads the rendered objects from a file
+	file, err := os.CreateTemp("", "kubectl-multi-kustomize-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(rendered); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write rendered objects: %v", err)
+	}
+	file.Close()
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tCREATED\tCONFIGURED\tUNCHANGED\n")
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Context == remoteCtx {
+			continue
+		}
+		args := []string{"apply", "-f", file.Name(), "--dry-run=server", "--context", clusterInfo.Context}
+		if namespace != "" {
+			args = append(args, "-n", namespace)
+		}
+		output, err := runKubectl(args, kubeconfig)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t?\t?\t?\n", clusterInfo.Name)
+			warnClusterFailure(clusterInfo.Name, fmt.Errorf("%v: %s", err, strings.TrimSpace(output)), "preview %s", dir)
+			continue
+		}
+		counts := countApplyResults(output)
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", clusterInfo.Name, counts["created"], counts["configured"], counts["unchanged"])
+	}
+	return tw.Flush()
+}
+
+// countApplyResults counts kubectl apply's "kind/name action" output lines by action
+func countApplyResults(output string) map[string]int {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && strings.Contains(fields[0], "/") {
+			counts[fields[1]]++
+		}
+	}
+	return counts
+}
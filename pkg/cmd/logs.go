@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"bytes"
-	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -123,24 +122,28 @@ func handleLogsCommand(podPattern string, follow, previous bool, container, sinc
 		return fmt.Errorf("no clusters discovered")
 	}
 
-	if follow {
+	if follow && !quiet {
 		fmt.Println("Warning: Follow mode (-f) across multiple clusters can be overwhelming.")
 		fmt.Println("Consider using this command on a specific cluster for follow mode.")
 		fmt.Println("Example: kubectl logs pod-name -f --context=specific-cluster")
 		fmt.Println()
 	}
 
-	fmt.Printf("Getting logs for pod pattern '%s' across %d clusters...\n\n", podPattern, len(clusters))
+	if !quiet {
+		fmt.Printf("Getting logs for pod pattern '%s' across %d clusters...\n\n", podPattern, len(clusters))
+	}
 
 	foundAnyPod := false
 
 	for _, clusterInfo := range clusters {
-		if clusterInfo.Client == nil {
-			fmt.Printf("Warning: skipping cluster %s (no client available)\n", clusterInfo.Name)
+		if clusterInfo.Client() == nil {
+			printWarning("skipping cluster %s (no client available)", clusterInfo.Name)
 			continue
 		}
 
-		fmt.Printf("=== Cluster: %s (Context: %s) ===\n", clusterInfo.Name, clusterInfo.Context)
+		if !mergedLayout() {
+			printClusterHeader(fmt.Sprintf("%s (Context: %s)%s", clusterInfo.Name, clusterInfo.Context, namespaceNote(clusterInfo, namespace, allNamespaces)))
+		}
 
 		// Get matching pods from this cluster
 		matchingPods, err := getMatchingPods(clusterInfo, podPattern, namespace, allNamespaces)
@@ -157,20 +160,28 @@ func handleLogsCommand(podPattern string, follow, previous bool, container, sinc
 		}
 
 		for _, podName := range matchingPods {
-			fmt.Printf("--- Pod: %s ---\n", podName)
+			if !mergedLayout() {
+				fmt.Printf("--- Pod: %s ---\n", podName)
+			}
 
-			kubectlArgs := buildLogsArgs(podName, follow, previous, container, since, sinceTime, timestamps, tail, limitBytes, namespace, allNamespaces, clusterInfo.Context)
+			kubectlArgs := buildLogsArgs(podName, follow, previous, container, since, sinceTime, timestamps, tail, limitBytes, clusterNamespace(clusterInfo, namespace), allNamespaces, clusterInfo.Context)
 
 			output, err := executeKubectlLogs(kubectlArgs, kubeconfig, clusterInfo.Name)
 			if err != nil {
 				fmt.Printf("Error getting logs for pod '%s' in cluster %s: %v\n", podName, clusterInfo.Name, err)
 			} else if strings.TrimSpace(output) != "" {
-				fmt.Print(output)
+				if mergedLayout() {
+					printPrefixed(clusterInfo.Name+"/"+podName, output)
+				} else {
+					fmt.Print(output)
+				}
 				foundAnyPod = true
 			} else {
 				fmt.Printf("No logs available for pod '%s'\n", podName)
 			}
-			fmt.Printf("\n")
+			if !mergedLayout() {
+				fmt.Printf("\n")
+			}
 		}
 	}
 
@@ -228,8 +239,13 @@ func buildLogsArgs(podName string, follow, previous bool, container, since, sinc
 }
 
 func executeKubectlLogs(args []string, kubeconfig, clusterName string) (string, error) {
+	kubectlArgs, cleanup, err := withClusterArgs(args, kubeconfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs from cluster %s: %v", clusterName, err)
+	}
+	defer cleanup()
 
-	cmd := exec.Command("kubectl", args...)
+	cmd := exec.Command("kubectl", kubectlArgs...)
 
 	cmd.Env = os.Environ()
 	if kubeconfig != "" {
@@ -240,7 +256,7 @@ func executeKubectlLogs(args []string, kubeconfig, clusterName string) (string,
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 
 	output := stdout.String()
 	stderrOutput := stderr.String()
@@ -269,10 +285,13 @@ func getMatchingPods(clusterInfo cluster.ClusterInfo, pattern, namespace string,
 	} else if namespace != "" {
 		targetNS = namespace
 	} else {
-		targetNS = "default"
+		targetNS = clusterInfo.TargetNamespace("")
 	}
 
-	pods, err := clusterInfo.Client.CoreV1().Pods(targetNS).List(context.TODO(), metav1.ListOptions{})
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	pods, err := clusterInfo.Client().CoreV1().Pods(targetNS).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
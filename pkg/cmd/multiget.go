@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,13 +29,8 @@ type MultiGetClusterInfo struct {
 }
 
 func toClusterInfo(m MultiGetClusterInfo) cluster.ClusterInfo {
-	return cluster.ClusterInfo{
-		Name:          m.Name,
-		Context:       m.Name, // Use ITS name as context
-		Client:        m.Client,
-		DynamicClient: m.DynamicClient,
-		RestConfig:    m.RestConfig,
-	}
+	// Use ITS name as context
+	return cluster.NewClusterInfo(m.Name, m.Name, m.Client, m.DynamicClient, nil, m.RestConfig)
 }
 
 func newMultiGetCommand() *cobra.Command {
@@ -359,7 +353,7 @@ func handleMultiGetCommand(args []string, outputFormat, selector string, showLab
 		return fmt.Errorf("watch operations are not supported in multi-cluster mode")
 	}
 
-	tw := tabwriter.NewWriter(util.GetOutputStream(), 0, 0, 2, ' ', 0)
+	tw := newTable(util.GetOutputStream(), nil, nil)
 	defer tw.Flush()
 
 	switch strings.ToLower(resourceType) {
@@ -388,7 +382,7 @@ func handleMultiGetCommand(args []string, outputFormat, selector string, showLab
 	}
 }
 
-func handleNodesGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
+func handleNodesGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -396,7 +390,7 @@ func handleNodesGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, r
 	return handleNodesGet(tw, infos, resourceName, selector, showLabels, outputFormat)
 }
 
-func handlePodsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handlePodsGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -404,7 +398,7 @@ func handlePodsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, re
 	return handlePodsGet(tw, infos, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 }
 
-func handleServicesGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleServicesGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -412,7 +406,7 @@ func handleServicesGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo
 	return handleServicesGet(tw, infos, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 }
 
-func handleDeploymentsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleDeploymentsGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -420,7 +414,7 @@ func handleDeploymentsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterI
 	return handleDeploymentsGet(tw, infos, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 }
 
-func handleNamespacesGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
+func handleNamespacesGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -428,7 +422,7 @@ func handleNamespacesGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterIn
 	return handleNamespacesGet(tw, infos, resourceName, selector, showLabels, outputFormat)
 }
 
-func handleConfigMapsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleConfigMapsGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -436,7 +430,7 @@ func handleConfigMapsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterIn
 	return handleConfigMapsGet(tw, infos, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 }
 
-func handleSecretsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleSecretsGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -444,7 +438,7 @@ func handleSecretsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo,
 	return handleSecretsGet(tw, infos, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 }
 
-func handleServiceAccountsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleServiceAccountsGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -452,7 +446,7 @@ func handleServiceAccountsGetMulti(tw *tabwriter.Writer, clusters []MultiGetClus
 	return handleServiceAccountsGet(tw, infos, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 }
 
-func handlePVGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
+func handlePVGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat string) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -460,7 +454,7 @@ func handlePVGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, reso
 	return handlePVGet(tw, infos, resourceName, selector, showLabels, outputFormat)
 }
 
-func handlePVCGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handlePVCGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
@@ -468,7 +462,7 @@ func handlePVCGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, res
 	return handlePVCGet(tw, infos, resourceName, selector, showLabels, outputFormat, namespace, allNamespaces)
 }
 
-func handleGenericGetMulti(tw *tabwriter.Writer, clusters []MultiGetClusterInfo, resourceType, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
+func handleGenericGetMulti(tw tableWriter, clusters []MultiGetClusterInfo, resourceType, resourceName, selector string, showLabels bool, outputFormat, namespace string, allNamespaces bool) error {
 	var infos []cluster.ClusterInfo
 	for _, c := range clusters {
 		infos = append(infos, toClusterInfo(c))
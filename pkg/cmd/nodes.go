@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// maxKubeletSkew is the number of minor versions kubelets may differ across the
+// fleet before the report warns about it
+const maxKubeletSkew = 1
+
+// nodeInventory counts the distinct node properties found in one cluster
+type nodeInventory struct {
+	Name     string
+	Nodes    int
+	Kubelets map[string]int
+	Runtimes map[string]int
+	OSImages map[string]int
+	Arches   map[string]int
+}
+
+func newNodesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "nodes",
+		Aliases: []string{"node"},
+		Short:   "Inspect nodes across managed clusters",
+	}
+	cmd.AddCommand(newNodesReportCommand())
+	return cmd
+}
+
+func newNodesReportCommand() *cobra.Command {
+	var selector string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarize kubelet versions, container runtimes, OS images and architectures per cluster",
+		Long: `Summarize the kubelet versions, container runtimes, OS images and CPU
+architectures of the nodes of every managed cluster.
+
+Clusters with mixed node properties, and kubelet versions that drift apart
+across the fleet, are flagged since they commonly break fleet-wide rollouts.`,
+		Example: `# Report node versions across all clusters
+kubectl multi nodes report
+
+# Only report worker nodes
+kubectl multi nodes report -l node-role.kubernetes.io/worker`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleNodesReportCommand(selector, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "node selector (label query) to filter on")
+
+	return cmd
+}
+
+func handleNodesReportCommand(selector, kubeconfig, remoteCtx string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tNODES\tKUBELET\tRUNTIME\tOS IMAGE\tARCH\n")
+
+	var findings []string
+	fleetKubelets := make(map[string]int)
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+
+		nodes, err := clusterInfo.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{
+			LabelSelector: selector,
+		})
+		cancel()
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "list nodes")
+			continue
+		}
+
+		inv := nodeInventory{
+			Name:     clusterInfo.Name,
+			Kubelets: make(map[string]int),
+			Runtimes: make(map[string]int),
+			OSImages: make(map[string]int),
+			Arches:   make(map[string]int),
+		}
+		for _, node := range nodes.Items {
+			info := node.Status.NodeInfo
+			inv.Nodes++
+			inv.Kubelets[info.KubeletVersion]++
+			inv.Runtimes[info.ContainerRuntimeVersion]++
+			inv.OSImages[info.OSImage]++
+			inv.Arches[info.Architecture]++
+			fleetKubelets[info.KubeletVersion]++
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", inv.Name, inv.Nodes,
+			formatValueCounts(inv.Kubelets), formatValueCounts(inv.Runtimes),
+			formatValueCounts(inv.OSImages), formatValueCounts(inv.Arches))
+
+		for _, check := range []struct {
+			what   string
+			counts map[string]int
+		}{
+			{"kubelet versions", inv.Kubelets},
+			{"container runtimes", inv.Runtimes},
+			{"OS images", inv.OSImages},
+			{"architectures", inv.Arches},
+		} {
+			if len(check.counts) > 1 {
+				findings = append(findings, fmt.Sprintf("cluster %s has %d different %s", inv.Name, len(check.counts), check.what))
+			}
+		}
+	}
+	tw.Flush()
+
+	if lowest, highest, ok := kubeletMinorRange(fleetKubelets); ok && highest-lowest > maxKubeletSkew {
+		findings = append(findings, fmt.Sprintf("kubelet versions across the fleet span 1.%d to 1.%d (%d minor versions)", lowest, highest, highest-lowest))
+	}
+
+	fmt.Println()
+	if len(findings) == 0 {
+		fmt.Println("✅ No node skew detected")
+		return nil
+	}
+	for _, finding := range findings {
+		fmt.Printf("⚠️  %s\n", finding)
+	}
+	return nil
+}
+
+// formatValueCounts renders counted values as "a(2),b(1)", most common first
+func formatValueCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "<none>"
+	}
+
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, fmt.Sprintf("%s(%d)", value, counts[value]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// kubeletMinorRange returns the lowest and highest minor version of the given kubelet versions
+func kubeletMinorRange(versions map[string]int) (int, int, bool) {
+	lowest, highest, ok := 0, 0, false
+	for version := range versions {
+		minor, err := parseMinorVersion(version)
+		if err != nil {
+			continue
+		}
+		if !ok || minor < lowest {
+			lowest = minor
+		}
+		if !ok || minor > highest {
+			highest = minor
+		}
+		ok = true
+	}
+	return lowest, highest, ok
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import "testing"
+
+// TestFormatValueCounts ensures values are ordered by count, then by name
+func TestFormatValueCounts(t *testing.T) {
+	counts := map[string]int{"v1.28.3": 1, "v1.29.0": 3, "v1.27.1": 1}
+
+	got := formatValueCounts(counts)
+	want := "v1.29.0(3),v1.27.1(1),v1.28.3(1)"
+	if got != want {
+		t.Errorf("formatValueCounts() = %q, want %q", got, want)
+	}
+
+	if got := formatValueCounts(map[string]int{}); got != "<none>" {
+		t.Errorf("formatValueCounts(empty) = %q, want %q", got, "<none>")
+	}
+}
+
+// TestKubeletMinorRange checks the minor version span of kubelet versions
+func TestKubeletMinorRange(t *testing.T) {
+	versions := map[string]int{"v1.27.2+k3s1": 2, "v1.29.0": 1, "garbage": 1}
+
+	lowest, highest, ok := kubeletMinorRange(versions)
+	if !ok || lowest != 27 || highest != 29 {
+		t.Errorf("kubeletMinorRange() = %d, %d, %v, want 27, 29, true", lowest, highest, ok)
+	}
+
+	if _, _, ok := kubeletMinorRange(map[string]int{}); ok {
+		t.Errorf("kubeletMinorRange(empty) reported a range")
+	}
+}
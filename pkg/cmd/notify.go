@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/multicluster"
+)
+
+// notifyTimeout bounds how long the end of a command waits for the notification
+const notifyTimeout = 10 * time.Second
+
+// commandStart is when the running command passed its flag checks
+var commandStart time.Time
+
+// notification is the JSON summary posted to --notify. Text makes Slack and other chat
+// webhooks show a readable message.
+type notification struct {
+	Text            string                `json:"text"`
+	Command         string                `json:"command"`
+	Result          string                `json:"result"`
+	Error           string                `json:"error,omitempty"`
+	Clusters        int                   `json:"clusters"`
+	Succeeded       []string              `json:"succeeded"`
+	Failed          []notificationFailure `json:"failed"`
+	Skipped         []string              `json:"skipped,omitempty"`
+	StartTime       time.Time             `json:"startTime"`
+	DurationSeconds float64               `json:"durationSeconds"`
+}
+
+type notificationFailure struct {
+	Cluster string `json:"cluster"`
+	Error   string `json:"error"`
+}
+
+// sendNotification posts the summary of the finished command to --notify when the
+// command ran at least --notify-after. A failed notification only warns, the
+// command itself already finished.
+func sendNotification(cmdErr error) {
+	if notifyURL == "" || commandStart.IsZero() {
+		return
+	}
+	elapsed := time.Since(commandStart)
+	if elapsed < notifyMin {
+		return
+	}
+
+	succeeded, failed, skipped := cluster.Results()
+	n := notification{
+		Command:         "kubectl multi " + strings.Join(os.Args[1:], " "),
+		Result:          "success",
+		Clusters:        len(succeeded) + len(failed) + len(skipped),
+		Succeeded:       append([]string{}, succeeded...),
+		Failed:          []notificationFailure{},
+		Skipped:         skipped,
+		StartTime:       commandStart.UTC(),
+		DurationSeconds: elapsed.Round(time.Millisecond).Seconds(),
+	}
+	for _, failure := range failed {
+		n.Failed = append(n.Failed, notificationFailure{Cluster: failure.Cluster, Error: failure.Err.Error()})
+	}
+	if cmdErr != nil {
+		n.Result = "error"
+		n.Error = cmdErr.Error()
+	}
+	n.Text = fmt.Sprintf("`%s` finished with %s after %s: %d succeeded, %d failed, %d skipped",
+		n.Command, n.Result, elapsed.Round(time.Second), len(succeeded), len(failed), len(skipped))
+
+	postNotification(n)
+}
+
+// postNotification posts a notification as JSON to --notify, warning when it fails
+func postNotification(n interface{}) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		printWarning("failed to encode notification: %v", err)
+		return
+	}
+	client := &http.Client{Timeout: notifyTimeout}
+	resp, err := client.Post(notifyURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		printWarning("failed to send notification: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		printWarning("notification was rejected: %s", resp.Status)
+	}
+}
+
+// notifyEnvPrefix is the prefix of the environment variables describing the matched
+// object to --notify-exec
+const notifyEnvPrefix = "KUBECTL_MULTI_NOTIFY_"
+
+// fieldCondition is one FIELD=VALUE or FIELD!=VALUE test of --notify-on
+type fieldCondition struct {
+	Path   []string
+	Value  string
+	Negate bool
+}
+
+// parseFieldConditions parses comma separated conditions like
+// status.phase=Failed,metadata.namespace!=test
+func parseFieldConditions(expr string) ([]fieldCondition, error) {
+	var conditions []fieldCondition
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		condition := fieldCondition{}
+		field, value, ok := strings.Cut(part, "!=")
+		if ok {
+			condition.Negate = true
+		} else if field, value, ok = strings.Cut(part, "="); !ok {
+			return nil, fmt.Errorf("invalid condition %q, expected FIELD=VALUE or FIELD!=VALUE", part)
+		}
+		field = strings.TrimPrefix(strings.TrimSpace(field), ".")
+		if field == "" {
+			return nil, fmt.Errorf("invalid condition %q, the field is empty", part)
+		}
+		condition.Path = strings.Split(field, ".")
+		condition.Value = strings.TrimSpace(value)
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("no condition given")
+	}
+	return conditions, nil
+}
+
+// matchesFieldConditions reports whether all conditions hold for an object. Fields are
+// compared as printed, so numbers and booleans match their text; a missing field is
+// empty.
+func matchesFieldConditions(obj map[string]interface{}, conditions []fieldCondition) bool {
+	for _, condition := range conditions {
+		value := ""
+		if field, found, _ := unstructured.NestedFieldNoCopy(obj, condition.Path...); found && field != nil {
+			value = fmt.Sprint(field)
+		}
+		if (value == condition.Value) == condition.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// watchNotifier fires --notify-exec and the --notify webhook for the objects of a
+// watch that start to match --notify-on. An object that keeps matching is notified
+// once, and again only after it stopped matching in between.
+type watchNotifier struct {
+	expr       string
+	conditions []fieldCondition
+	command    string
+	matching   map[string]bool
+}
+
+func newWatchNotifier(expr, command string) (*watchNotifier, error) {
+	conditions, err := parseFieldConditions(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --notify-on: %v", err)
+	}
+	if command == "" && notifyURL == "" {
+		return nil, fmt.Errorf("--notify-on requires --notify-exec or a --notify webhook")
+	}
+	return &watchNotifier{expr: expr, conditions: conditions, command: command, matching: make(map[string]bool)}, nil
+}
+
+// observe notifies about an event whose object now matches and did not before
+func (n *watchNotifier) observe(event multicluster.WatchEvent) {
+	obj := event.Object
+	key := event.Cluster + "/" + obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+	if event.Type == "DELETED" {
+		delete(n.matching, key)
+		return
+	}
+	matches := matchesFieldConditions(obj.Object, n.conditions)
+	notify := matches && !n.matching[key]
+	n.matching[key] = matches
+	if !notify {
+		return
+	}
+
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	text := fmt.Sprintf("%s %s in cluster %s matches %s", strings.ToLower(obj.GetKind()), name, event.Cluster, n.expr)
+	if n.command != "" {
+		env := []string{
+			notifyEnvPrefix + "CLUSTER=" + event.Cluster,
+			notifyEnvPrefix + "EVENT=" + event.Type,
+			notifyEnvPrefix + "KIND=" + obj.GetKind(),
+			notifyEnvPrefix + "NAMESPACE=" + obj.GetNamespace(),
+			notifyEnvPrefix + "NAME=" + obj.GetName(),
+			notifyEnvPrefix + "MESSAGE=" + text,
+		}
+		if err := runHook(n.command, env); err != nil {
+			printWarning("failed to run --notify-exec: %v", err)
+		}
+	}
+	if notifyURL != "" {
+		postNotification(map[string]interface{}{
+			"text":      text,
+			"cluster":   event.Cluster,
+			"type":      event.Type,
+			"condition": n.expr,
+			"object":    obj.Object,
+		})
+	}
+}
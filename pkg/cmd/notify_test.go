@@ -0,0 +1,41 @@
+package cmd
+
+import "testing"
+
+// TestFieldConditions ensures --notify-on conditions match printed field values
+func TestFieldConditions(t *testing.T) {
+	pod := map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "shop"},
+		"spec":     map[string]interface{}{"priority": int64(10)},
+		"status":   map[string]interface{}{"phase": "Failed"},
+	}
+
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{"status.phase=Failed", true},
+		{".status.phase=Running", false},
+		{"status.phase=Failed,metadata.namespace!=test", true},
+		{"status.phase=Failed,metadata.namespace!=shop", false},
+		{"spec.priority=10", true},
+		{"status.reason=", true},
+		{"status.reason!=", false},
+	}
+
+	for _, tt := range tests {
+		conditions, err := parseFieldConditions(tt.expr)
+		if err != nil {
+			t.Fatalf("parseFieldConditions(%q) returned error: %v", tt.expr, err)
+		}
+		if got := matchesFieldConditions(pod, conditions); got != tt.match {
+			t.Errorf("%q: got %v, want %v", tt.expr, got, tt.match)
+		}
+	}
+
+	for _, expr := range []string{"", "status.phase", "=Failed"} {
+		if _, err := parseFieldConditions(expr); err == nil {
+			t.Errorf("parseFieldConditions(%q) returned no error", expr)
+		}
+	}
+}
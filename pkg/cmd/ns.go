@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNsCommand() *cobra.Command {
+	var showPods bool
+
+	cmd := &cobra.Command{
+		Use:   "ns",
+		Short: "Show a matrix of namespaces across managed clusters",
+		Long: `Show every namespace of the managed clusters as a row, with a column per cluster
+holding the status of the namespace there, or - where it does not exist.`,
+		Example: `# Show which clusters have which namespaces
+kubectl multi ns
+
+# Include the number of pods in every namespace
+kubectl multi ns --pods
+
+# Create the shop namespace on all clusters that lack it
+kubectl multi ns ensure shop`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleNsCommand(showPods, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().BoolVar(&showPods, "pods", false, "show the number of pods in every namespace")
+	cmd.AddCommand(newNsEnsureCommand())
+
+	return cmd
+}
+
+func newNsEnsureCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "ensure NAME",
+		Short:       "Create a namespace on all managed clusters that lack it",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{mutatingAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleNsEnsureCommand(args[0], kubeconfig, remoteCtx)
+		},
+	}
+	return cmd
+}
+
+func handleNsCommand(showPods bool, kubeconfig, remoteCtx string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	// Cell of every namespace in every cluster that answered
+	cells := make(map[string]map[string]string)
+	var columns []string
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+		namespaces, err := clusterInfo.Client().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "list namespaces")
+			continue
+		}
+
+		podCounts := make(map[string]int)
+		if showPods {
+			ctx, cancel := clusterInfo.RequestContext()
+			pods, err := clusterInfo.Client().CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+			cancel()
+			if err != nil {
+				warnClusterFailure(clusterInfo.Name, err, "list pods")
+				continue
+			}
+			for _, pod := range pods.Items {
+				podCounts[pod.Namespace]++
+			}
+		}
+
+		columns = append(columns, clusterInfo.Name)
+		for _, ns := range namespaces.Items {
+			cell := string(ns.Status.Phase)
+			if showPods {
+				cell = fmt.Sprintf("%s (%d)", cell, podCounts[ns.Name])
+			}
+			if cells[ns.Name] == nil {
+				cells[ns.Name] = make(map[string]string)
+			}
+			cells[ns.Name][clusterInfo.Name] = cell
+		}
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	if len(cells) == 0 {
+		fmt.Fprintf(tw, "No resource found.\n")
+		return tw.Flush()
+	}
+
+	names := make([]string, 0, len(cells))
+	for name := range cells {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(tw, "NAMESPACE\t%s\n", strings.Join(columns, "\t"))
+	for _, name := range names {
+		row := []string{name}
+		for _, column := range columns {
+			cell, ok := cells[name][column]
+			if !ok {
+				cell = "-"
+			}
+			row = append(row, cell)
+		}
+		fmt.Fprintf(tw, "%s\n", strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func handleNsEnsureCommand(name, kubeconfig, remoteCtx string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tRESULT\n")
+
+	// Only the clusters that lack the namespace are changed
+	var missing []cluster.ClusterInfo
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil || clusterInfo.Context == remoteCtx {
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+
+		_, err := clusterInfo.Client().CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		cancel()
+		if errors.IsNotFound(err) {
+			missing = append(missing, clusterInfo)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t%s\tfailed\n", clusterInfo.Name, name)
+			warnClusterFailure(clusterInfo.Name, err, "get namespace %s", name)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\texists\n", clusterInfo.Name, name)
+	}
+
+	if len(missing) > 0 {
+		if err := checkMutationTargets(missing, remoteCtx); err != nil {
+			return err
+		}
+		confirmed, err := confirmCommand("ns ensure", missing, remoteCtx)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Namespace creation cancelled...")
+			return nil
+		}
+	}
+
+	for _, clusterInfo := range missing {
+		ctx, cancel := clusterInfo.RequestContext()
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		_, err := clusterInfo.Client().CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+		cancel()
+		writeAudit(clusterInfo.Name, clusterInfo.Context, []string{"namespace/" + name}, false, err)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t%s\tfailed\n", clusterInfo.Name, name)
+			warnClusterFailure(clusterInfo.Name, err, "create namespace %s", name)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\tcreated\n", clusterInfo.Name, name)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// bindingLabel names the Binding that delivered an object to a WEC
+	bindingLabel = "transport.kubestellar.io/originOwnerReferenceBindingKey"
+	// originWDSAnnotation names the WDS an object was delivered from
+	originWDSAnnotation = "transport.kubestellar.io/originWdsName"
+)
+
+// wdsTimeout bounds each lookup of a Binding or source object in the WDS
+const wdsTimeout = 30 * time.Second
+
+// bindingGVR is the resource of the Bindings KubeStellar derives from BindingPolicies
+var bindingGVR = schema.GroupVersionResource{Group: "control.kubestellar.io", Version: "v1alpha1", Resource: "bindings"}
+
+// orphan is a delivered object whose Binding or WDS source object is gone
+type orphan struct {
+	cluster   cluster.ClusterInfo
+	gvr       schema.GroupVersionResource
+	kind      string
+	namespace string
+	name      string
+	binding   string
+	reason    string
+}
+
+func newOrphansCommand() *cobra.Command {
+	var resources []string
+	var wdsContext string
+	var deleteOrphans bool
+
+	cmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "Find delivered objects whose WDS source object or Binding no longer exists",
+		Long: `Find the objects on the managed clusters that KubeStellar delivered from the WDS,
+recognized by their delivery label, whose source object or Binding no longer exists
+in the WDS. These objects are left behind and are candidates for cleanup.
+
+With --delete the orphans are deleted after a confirmation.`,
+		Example: `# List orphaned objects of all resource types
+kubectl multi orphans
+
+# Only check deployments and config maps, and delete the orphans
+kubectl multi orphans --resources deploy,cm --delete`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if deleteOrphans && readOnlyMode() {
+				return fmt.Errorf("%q with --delete changes clusters and is disabled in read-only mode", cmd.CommandPath())
+			}
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleOrphansCommand(resources, wdsContext, deleteOrphans, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&resources, "resources", nil, "comma separated resource types to check (default all listable types)")
+	cmd.Flags().StringVar(&wdsContext, "wds-context", defaultWDSContext, "kubeconfig context of the WDS the objects were delivered from")
+	cmd.Flags().BoolVar(&deleteOrphans, "delete", false, "delete the orphaned objects after a confirmation")
+
+	return cmd
+}
+
+func handleOrphansCommand(resources []string, wdsContext string, deleteOrphans bool, kubeconfig, remoteCtx string) error {
+	wds, err := cluster.GetRemoteDynamicClient(kubeconfig, wdsContext)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	checker := &wdsChecker{client: wds, exists: make(map[string]bool)}
+	var orphans []orphan
+	for _, clusterInfo := range clusters {
+		if clusterInfo.DynamicClient() == nil || clusterInfo.Context == remoteCtx || clusterInfo.Context == wdsContext {
+			continue
+		}
+
+		gvrs, err := orphanResources(clusterInfo, resources)
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "discover resource types")
+			continue
+		}
+
+		for _, gvr := range gvrs {
+			// Each type gets the whole cluster timeout, a cluster with many types would
+			// otherwise time out halfway
+			ctx, cancel := clusterInfo.RequestContext()
+			list, err := clusterInfo.DynamicClient().Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: bindingLabel})
+			stopped := ctx.Err() != nil
+			cancel()
+			if err != nil {
+				// Types that cannot be listed cannot hold delivered objects the agent manages
+				if errors.IsForbidden(err) || errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+					continue
+				}
+				warnClusterFailure(clusterInfo.Name, err, "list %s", gvr.Resource)
+				// The remaining types would time out or be aborted as well
+				if stopped {
+					break
+				}
+				continue
+			}
+			for _, obj := range list.Items {
+				// Objects of another WDS cannot be judged against this one
+				if origin := obj.GetAnnotations()[originWDSAnnotation]; origin != "" && origin != wdsContext {
+					continue
+				}
+				binding := obj.GetLabels()[bindingLabel]
+				reason, err := checker.orphanReason(gvr, obj.GetNamespace(), obj.GetName(), binding)
+				if err != nil {
+					return fmt.Errorf("failed to check the WDS %s: %v", wdsContext, err)
+				}
+				if reason == "" {
+					continue
+				}
+				orphans = append(orphans, orphan{
+					cluster:   clusterInfo,
+					gvr:       gvr,
+					kind:      obj.GetKind(),
+					namespace: obj.GetNamespace(),
+					name:      obj.GetName(),
+					binding:   binding,
+					reason:    reason,
+				})
+			}
+		}
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	if len(orphans) == 0 {
+		fmt.Fprintf(tw, "No resource found.\n")
+		return tw.Flush()
+	}
+	fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tKIND\tNAME\tBINDING\tREASON\n")
+	for _, o := range orphans {
+		namespace := o.namespace
+		if namespace == "" {
+			namespace = "<none>"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", o.cluster.Name, namespace, o.kind, o.name, o.binding, o.reason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !deleteOrphans {
+		return nil
+	}
+	return deleteOrphanedObjects(orphans, remoteCtx)
+}
+
+// orphanResources returns the resources to check in a cluster: the given types, or all
+// listable types of the cluster
+func orphanResources(clusterInfo cluster.ClusterInfo, resources []string) ([]schema.GroupVersionResource, error) {
+	var gvrs []schema.GroupVersionResource
+	if len(resources) > 0 {
+		for _, resourceType := range resources {
+			gvr, _, err := util.NewGVRResolver(resourceType).Resolve(clusterInfo.DiscoveryClient())
+			if err != nil {
+				return nil, err
+			}
+			gvrs = append(gvrs, gvr)
+		}
+		return gvrs, nil
+	}
+
+	// Partial results still cover the groups that answered
+	lists, err := clusterInfo.DiscoveryClient().ServerPreferredResources()
+	if len(lists) == 0 && err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") || !containsVerb(resource.Verbs, "list") {
+				continue
+			}
+			gvrs = append(gvrs, gv.WithResource(resource.Name))
+		}
+	}
+	sort.Slice(gvrs, func(i, j int) bool { return gvrs[i].String() < gvrs[j].String() })
+	return gvrs, nil
+}
+
+func containsVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// wdsChecker looks up Bindings and source objects in the WDS, each only once
+type wdsChecker struct {
+	client dynamic.Interface
+	exists map[string]bool
+}
+
+// orphanReason returns why a delivered object is orphaned, or an empty string when
+// its Binding and source object still exist
+func (c *wdsChecker) orphanReason(gvr schema.GroupVersionResource, namespace, name, binding string) (string, error) {
+	found, err := c.lookup(bindingGVR, "", binding)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "binding not found", nil
+	}
+	found, err = c.lookup(gvr, namespace, name)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "source not found", nil
+	}
+	return "", nil
+}
+
+func (c *wdsChecker) lookup(gvr schema.GroupVersionResource, namespace, name string) (bool, error) {
+	key := gvr.String() + "/" + namespace + "/" + name
+	if found, ok := c.exists[key]; ok {
+		return found, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wdsTimeout)
+	defer cancel()
+	var err error
+	if namespace != "" {
+		_, err = c.client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	} else {
+		_, err = c.client.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	c.exists[key] = err == nil
+	return err == nil, nil
+}
+
+// deleteOrphanedObjects deletes the orphans after the safety checks and confirmation
+func deleteOrphanedObjects(orphans []orphan, remoteCtx string) error {
+	var targets []cluster.ClusterInfo
+	seen := make(map[string]bool)
+	for _, o := range orphans {
+		if !seen[o.cluster.Name] {
+			seen[o.cluster.Name] = true
+			targets = append(targets, o.cluster)
+		}
+	}
+	if err := checkMutationTargets(targets, remoteCtx); err != nil {
+		return err
+	}
+	// Deleting orphans is a delete, so the confirmations of delete apply
+	confirmed, err := confirmCommand("delete", targets, remoteCtx)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Deletion cancelled...")
+		return nil
+	}
+
+	fmt.Println()
+	for _, o := range orphans {
+		ctx, cancel := o.cluster.RequestContext()
+		if o.namespace != "" {
+			err = o.cluster.DynamicClient().Resource(o.gvr).Namespace(o.namespace).Delete(ctx, o.name, metav1.DeleteOptions{})
+		} else {
+			err = o.cluster.DynamicClient().Resource(o.gvr).Delete(ctx, o.name, metav1.DeleteOptions{})
+		}
+		cancel()
+		object := strings.ToLower(o.kind) + "/" + o.name
+		writeAudit(o.cluster.Name, o.cluster.Context, []string{object}, false, err)
+		if err != nil {
+			warnClusterFailure(o.cluster.Name, err, "delete %s", object)
+			continue
+		}
+		fmt.Printf("%s deleted in cluster %s\n", object, o.cluster.Name)
+	}
+	return nil
+}
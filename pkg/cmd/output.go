@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// printClusterHeader prints the section header that precedes the output of one cluster
+func printClusterHeader(title string) {
+	if quiet {
+		return
+	}
+	fmt.Println(util.ColorizeHeader(fmt.Sprintf("=== Cluster: %s ===", title)))
+}
+
+// clusterNamespace returns the namespace given on the command line, or the configured
+// default namespace of the cluster when none was given
+func clusterNamespace(clusterInfo cluster.ClusterInfo, namespace string) string {
+	if namespace == "" {
+		return clusterInfo.DefaultNamespace()
+	}
+	return namespace
+}
+
+// namespaceNote shows a configured default namespace in a cluster header, since the
+// namespace then differs between clusters
+func namespaceNote(clusterInfo cluster.ClusterInfo, namespace string, allNamespaces bool) string {
+	if namespace != "" || allNamespaces || clusterInfo.DefaultNamespace() == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [namespace: %s]", clusterInfo.DefaultNamespace())
+}
+
+// mergedLayout reports whether the output of all clusters is combined into one
+// table or list instead of a section per cluster
+func mergedLayout() bool {
+	return layout == "merged"
+}
+
+// printPrefixed prints output with every line prefixed by the cluster it came from,
+// which replaces the cluster headers in the merged layout
+func printPrefixed(prefix, output string) {
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		fmt.Printf("[%s] %s\n", prefix, line)
+	}
+}
+
+// printWarning prints a warning unless --quiet is set
+func printWarning(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Printf("Warning: "+format+"\n", args...)
+}
+
+// printStderrWarning prints a warning to stderr unless --quiet is set, for checks that
+// run before a command and must not corrupt -o json or yaml output
+func printStderrWarning(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+}
+
+// tableWriter receives the tab separated rows of a table, usually a tabwriter
+type tableWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// newTable returns a writer aligning tab separated rows on out. Only the show columns
+// and none of the hide columns are printed, and cells longer than --max-column-width
+// are shortened unless --full-width is set. Columns stay aligned when the rows are
+// flushed a cluster at a time.
+func newTable(out io.Writer, show, hide []string) tableWriter {
+	tw := util.NewStreamTable(tabwriter.NewWriter(out, 0, 0, 2, ' ', 0))
+	width := tableWidth()
+	if width == 0 && len(show) == 0 && len(hide) == 0 {
+		return tw
+	}
+	filter := util.NewColumnFilter(tw, show, hide)
+	filter.MaxWidth = width
+	return filter
+}
+
+// tableWidth returns the maximum width of table cells, 0 for no limit
+func tableWidth() int {
+	if fullWidth {
+		return 0
+	}
+	return maxColWidth
+}
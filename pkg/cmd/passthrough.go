@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+func newPassthroughCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "x -- KUBECTL_ARGS...",
+		Short: "Run any kubectl command or kubectl plugin against all managed clusters",
+		Long: `Run any kubectl invocation, including verbs kubectl multi does not wrap and
+third-party kubectl plugins, once per selected cluster. --context is set per cluster
+and the output of each cluster is printed in its own section.`,
+		Example: `# Show the server version of every cluster
+kubectl multi x -- version
+
+# Run a kubectl plugin everywhere
+kubectl multi x -- neat get deployment nginx -o yaml
+
+# Label nodes in two clusters only
+kubectl multi --clusters cluster1,cluster2 x -- label nodes --all zone=lab`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
+			return handlePassthroughCommand(args, kubeconfig, remoteCtx, namespace, allNamespaces)
+		},
+	}
+	return cmd
+}
+
+func handlePassthroughCommand(args []string, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	// Any kubectl plugin may change clusters, so x is refused as a whole
+	if readOnlyMode() {
+		return fmt.Errorf("x runs arbitrary kubectl commands and is disabled in read-only mode")
+	}
+
+	// The command is only known at run time, so the safety checks of the mutating
+	// commands are applied here instead of through the command annotation. Only known
+	// read-only verbs skip them.
+	mutating := isMutation(args)
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	if mutating {
+		if err := checkMutationTargets(clusters, remoteCtx); err != nil {
+			return err
+		}
+		command := args[0]
+		if command == "rollout" && len(args) > 1 {
+			command += " " + args[1]
+		}
+		confirmed, err := confirmCommand(command, clusters, remoteCtx)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Command cancelled...")
+			return nil
+		}
+	}
+
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Context == remoteCtx {
+			continue
+		}
+
+		kubectlArgs := passthroughArgs(args, clusterInfo.Context, namespace, allNamespaces)
+		output, err := runKubectl(kubectlArgs, kubeconfig)
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, fmt.Errorf("%v: %s", err, strings.TrimSpace(output)), "run kubectl %s", args[0])
+			continue
+		}
+
+		if mergedLayout() {
+			printPrefixed(clusterInfo.Name, output)
+			continue
+		}
+		printClusterHeader(clusterInfo.Name)
+		fmt.Print(output)
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// passthroughArgs adds the cluster context and the namespace flags given to kubectl
+// multi to the kubectl args. They are inserted before a "--", which starts the
+// command of e.g. kubectl exec, and otherwise appended, so kubectl still finds the
+// plugin named by the first args.
+func passthroughArgs(args []string, context, namespace string, allNamespaces bool) []string {
+	extra := []string{"--context", context}
+	if namespace != "" {
+		extra = append(extra, "--namespace", namespace)
+	}
+	if allNamespaces {
+		extra = append(extra, "--all-namespaces")
+	}
+
+	for i, arg := range args {
+		if arg == "--" {
+			return append(append(append([]string{}, args[:i]...), extra...), args[i:]...)
+		}
+	}
+	return append(append([]string{}, args...), extra...)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// accessCheck is a permission a mutating command needs in every target cluster. The
+// resource is either the kind of a manifest object or a resource type from the
+// command line.
+type accessCheck struct {
+	Verbs        []string
+	GVK          schema.GroupVersionKind
+	ResourceType string
+	Namespace    string
+	Name         string
+}
+
+// checkAccess asks every target cluster with SelfSubjectAccessReviews whether the
+// checks are allowed and fails, listing the clusters that lack a permission, before
+// anything has been changed. The ITS cluster is skipped like by the commands.
+func checkAccess(clusters []cluster.ClusterInfo, itsContext string, checks []accessCheck, namespace string) error {
+	return checkClusterAccess(clusters, itsContext, func(cluster.ClusterInfo) []accessCheck { return checks }, namespace)
+}
+
+// checkClusterAccess is checkAccess with checks that differ per cluster, such as those
+// of manifests rendered for each cluster
+func checkClusterAccess(clusters []cluster.ClusterInfo, itsContext string, checksFor func(cluster.ClusterInfo) []accessCheck, namespace string) error {
+	var denied []string
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Context == itsContext || clusterInfo.Client() == nil {
+			continue
+		}
+		problems, err := clusterAccessProblems(clusterInfo, checksFor(clusterInfo), namespace)
+		if err != nil {
+			denied = append(denied, fmt.Sprintf("  %s: could not check permissions: %v", clusterInfo.Name, err))
+			continue
+		}
+		for _, problem := range problems {
+			denied = append(denied, fmt.Sprintf("  %s: %s", clusterInfo.Name, problem))
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("missing permissions, nothing was changed (use --skip-access-check to run anyway):\n%s", strings.Join(denied, "\n"))
+	}
+	return nil
+}
+
+// clusterAccessProblems returns the checks that are denied in one cluster. Kinds the
+// cluster does not know yet, such as those of a CRD applied in the same bundle, cannot
+// be checked and are skipped with a warning.
+func clusterAccessProblems(clusterInfo cluster.ClusterInfo, checks []accessCheck, namespace string) ([]string, error) {
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	mapper := util.NewRESTMapper(clusterInfo.DiscoveryClient())
+	var problems []string
+	for _, check := range checks {
+		var gvr schema.GroupVersionResource
+		var isNamespaced bool
+		if check.ResourceType != "" {
+			resolved, namespaced, err := util.NewGVRResolver(check.ResourceType).Resolve(clusterInfo.DiscoveryClient())
+			if err != nil {
+				return nil, err
+			}
+			gvr, isNamespaced = resolved, namespaced
+		} else {
+			mapping, err := mapper.RESTMapping(check.GVK.GroupKind(), check.GVK.Version)
+			if meta.IsNoMatchError(err) {
+				printWarning("cannot check permissions for %s in cluster %s: the kind is not known to the cluster yet", check.GVK.Kind, clusterInfo.Name)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %v", check.GVK.Kind, err)
+			}
+			gvr, isNamespaced = mapping.Resource, mapping.Scope.Name() == meta.RESTScopeNameNamespace
+		}
+
+		targetNS := ""
+		if isNamespaced {
+			targetNS = check.Namespace
+			if targetNS == "" {
+				targetNS = clusterInfo.TargetNamespace(namespace)
+			}
+		}
+
+		for _, verb := range check.Verbs {
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: targetNS,
+						Verb:      verb,
+						Group:     gvr.Group,
+						Resource:  gvr.Resource,
+						Name:      check.Name,
+					},
+				},
+			}
+			result, err := clusterInfo.Client().AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+			if err != nil {
+				return nil, err
+			}
+			if !result.Status.Allowed {
+				problems = append(problems, describeAccess(verb, gvr, check.Name, targetNS))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// describeAccess formats a denied permission like "cannot delete deployments.apps/nginx in namespace shop"
+func describeAccess(verb string, gvr schema.GroupVersionResource, name, namespace string) string {
+	resource := gvr.GroupResource().String()
+	if name != "" {
+		resource += "/" + name
+	}
+	if namespace == "" {
+		return fmt.Sprintf("cannot %s %s", verb, resource)
+	}
+	return fmt.Sprintf("cannot %s %s in namespace %s", verb, resource, namespace)
+}
+
+// manifestAccessChecks returns a check per object of the manifests at path. Only local
+// files and directories can be read ahead of the command.
+func manifestAccessChecks(path string, recursive bool, verbs []string) ([]accessCheck, error) {
+	if path == "-" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return nil, fmt.Errorf("permissions for %s cannot be checked ahead of time", path)
+	}
+
+	var files []string
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if file != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, file)
+		default:
+			if file == path {
+				files = append(files, file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests: %v", err)
+	}
+
+	var checks []accessCheck
+	for _, file := range files {
+		objects, err := readManifestObjects(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			checks = append(checks, accessCheck{
+				Verbs:     verbs,
+				GVK:       obj.GroupVersionKind(),
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+			})
+		}
+	}
+	return checks, nil
+}
+
+// readManifestObjects decodes all objects of a YAML or JSON file, expanding lists
+func readManifestObjects(file string) ([]unstructured.Unstructured, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests: %v", err)
+	}
+	defer f.Close()
+
+	var objects []unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		obj := unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+			}
+			objects = append(objects, list.Items...)
+			continue
+		}
+		objects = append(objects, obj)
+	}
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// TestCheckApplyAccessUncheckable ensures apply stops when the permissions for its
+// manifests cannot be checked ahead of time, instead of applying unchecked
+func TestCheckApplyAccessUncheckable(t *testing.T) {
+	clusters := []cluster.ClusterInfo{{Name: "cluster1", Context: "cluster1"}}
+	for _, path := range []string{"-", "https://example.com/app.yaml", "testdata/missing.yaml"} {
+		err := checkApplyAccess(clusters, "its1", func(cluster.ClusterInfo) string { return path }, false, "default")
+		if err == nil || !strings.Contains(err.Error(), "--skip-access-check") {
+			t.Errorf("checkApplyAccess(%s) = %v, want an error pointing to --skip-access-check", path, err)
+		}
+	}
+}
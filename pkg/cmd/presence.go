@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+func newPresenceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "presence TYPE/NAME",
+		Short: "Show which managed clusters have a given object",
+		Long: `Show a compact matrix of every managed cluster and whether it has the given
+object, whether the object is ready, and which container images it runs.
+
+This makes it obvious which clusters received a workload and which did not.`,
+		Example: `# Check which clusters run the frontend deployment
+kubectl multi presence deploy/frontend -n shop
+
+# The type and name may also be given as separate arguments
+kubectl multi presence configmap app-config -n shop`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceType, name, err := parseTypeAndName(args)
+			if err != nil {
+				return err
+			}
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			return handlePresenceCommand(resourceType, name, kubeconfig, remoteCtx, namespace)
+		},
+	}
+
+	return cmd
+}
+
+// parseTypeAndName accepts either TYPE/NAME or TYPE NAME
+func parseTypeAndName(args []string) (string, string, error) {
+	if len(args) == 2 {
+		return args[0], args[1], nil
+	}
+	parts := strings.SplitN(args[0], "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("expected TYPE/NAME or TYPE NAME, got %q", args[0])
+	}
+	return parts[0], parts[1], nil
+}
+
+func handlePresenceCommand(resourceType, name, kubeconfig, remoteCtx, namespace string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tEXISTS\tREADY\tIMAGES\tAGE\n")
+
+	resolver := util.NewGVRResolver(resourceType)
+	present := 0
+	for _, clusterInfo := range clusters {
+		if clusterInfo.DynamicClient() == nil {
+			continue
+		}
+
+		gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t?\t-\t-\t-\n", clusterInfo.Name)
+			warnClusterFailure(clusterInfo.Name, err, "discover resource type %s", resourceType)
+			continue
+		}
+
+		var obj *unstructured.Unstructured
+		ctx, cancel := clusterInfo.RequestContext()
+		if isNamespaced {
+			obj, err = clusterInfo.DynamicClient().Resource(gvr).Namespace(clusterInfo.TargetNamespace(namespace)).Get(ctx, name, metav1.GetOptions{})
+		} else {
+			obj, err = clusterInfo.DynamicClient().Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+		}
+		cancel()
+		if errors.IsNotFound(err) {
+			fmt.Fprintf(tw, "%s\t✗\t-\t-\t-\n", clusterInfo.Name)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t?\t-\t-\t-\n", clusterInfo.Name)
+			warnClusterFailure(clusterInfo.Name, err, "get %s/%s", resourceType, name)
+			continue
+		}
+
+		present++
+		age := util.FormatAge(obj.GetCreationTimestamp().Time)
+		fmt.Fprintf(tw, "%s\t✓\t%s\t%s\t%s\n", clusterInfo.Name, objectReadiness(obj), objectImages(obj), age)
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%s/%s present in %d of %d clusters\n", resourceType, name, present, len(clusters))
+	return nil
+}
+
+// objectReadiness summarizes the readiness of an arbitrary object, using replica
+// counts for workloads and the Ready or Available condition for everything else
+func objectReadiness(obj *unstructured.Unstructured) string {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		var desired *int32
+		if replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+			desired = new(int32)
+			*desired = int32(replicas)
+		}
+		return util.FormatReady(int32(ready), desired)
+	case "DaemonSet":
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+		return fmt.Sprintf("%d/%d", ready, desired)
+	case "Pod":
+		statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+		ready := 0
+		for _, s := range statuses {
+			if status, ok := s.(map[string]interface{}); ok && status["ready"] == true {
+				ready++
+			}
+		}
+		return fmt.Sprintf("%d/%d", ready, len(statuses))
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Ready" || condition["type"] == "Available" {
+			if condition["status"] == "True" {
+				return "Yes"
+			}
+			return "No"
+		}
+	}
+	return "-"
+}
+
+// objectImages returns the container images of a pod or pod template, which is
+// usually the quickest way to tell which version of a workload a cluster runs
+func objectImages(obj *unstructured.Unstructured) string {
+	containers, found, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	if !found {
+		containers, found, _ = unstructured.NestedSlice(obj.Object, "spec", "jobTemplate", "spec", "template", "spec", "containers")
+	}
+	if !found {
+		containers, _, _ = unstructured.NestedSlice(obj.Object, "spec", "containers")
+	}
+
+	var images []string
+	for _, c := range containers {
+		if container, ok := c.(map[string]interface{}); ok {
+			if image, ok := container["image"].(string); ok {
+				images = append(images, image)
+			}
+		}
+	}
+	if len(images) == 0 {
+		return "-"
+	}
+	return strings.Join(images, ",")
+}
@@ -1,11 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
 
 	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/multicluster"
+	"kubectl-multi/pkg/util"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -37,8 +48,9 @@ func newRolloutHistoryCommand() *cobra.Command {
 
 func newRolloutPauseCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "pause",
-		Short: "Pause a resource across all managed clusters",
+		Use:         "pause",
+		Short:       "Pause a resource across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
 			return handleRolloutSubcommand("pause", args, kubeconfig, remoteCtx)
@@ -49,8 +61,9 @@ func newRolloutPauseCommand() *cobra.Command {
 
 func newRolloutRestartCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "restart",
-		Short: "Restart a resource across all managed clusters",
+		Use:         "restart",
+		Short:       "Restart a resource across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
 			return handleRolloutSubcommand("restart", args, kubeconfig, remoteCtx)
@@ -61,8 +74,9 @@ func newRolloutRestartCommand() *cobra.Command {
 
 func newRolloutResumeCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "resume",
-		Short: "Resume a resource across all managed clusters",
+		Use:         "resume",
+		Short:       "Resume a resource across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
 			return handleRolloutSubcommand("resume", args, kubeconfig, remoteCtx)
@@ -72,21 +86,34 @@ func newRolloutResumeCommand() *cobra.Command {
 }
 
 func newRolloutStatusCommand() *cobra.Command {
+	var watch bool
+
 	cmd := &cobra.Command{
-		Use:   "status",
+		Use:   "status (TYPE/NAME | TYPE NAME)",
 		Short: "Show the status of the rollout across all managed clusters",
+		Long: `Show the status of the rollout across all managed clusters. With --watch a
+table with one row per cluster is redrawn on every change until the rollout is
+complete in all clusters that have the object.`,
+		Example: `# Follow the rollout of a deployment in all clusters
+kubectl multi rollout status deployment/nginx -n shop --watch`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			if watch {
+				return handleRolloutStatusWatch(args, kubeconfig, remoteCtx, namespace)
+			}
 			return handleRolloutSubcommand("status", args, kubeconfig, remoteCtx)
 		},
 	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "follow the rollout in a table with one row per cluster, redrawn on every change")
 	return cmd
 }
 
 func newRolloutUndoCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "undo",
-		Short: "Roll back to a previous rollout across all managed clusters",
+		Use:         "undo",
+		Short:       "Roll back to a previous rollout across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
 			return handleRolloutSubcommand("undo", args, kubeconfig, remoteCtx)
@@ -103,6 +130,19 @@ func handleRolloutSubcommand(subcommand string, extraArgs []string, kubeconfig,
 	if len(clusters) == 0 {
 		return fmt.Errorf("no clusters discovered")
 	}
+	if isMutation([]string{"rollout", subcommand}) {
+		if err := checkMutationTargets(clusters, remoteCtx); err != nil {
+			return err
+		}
+		confirmed, err := confirmCommand("rollout "+subcommand, clusters, remoteCtx)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Printf("Rollout %s cancelled...\n", subcommand)
+			return nil
+		}
+	}
 
 	// Find current context from kubeconfig
 	currentContext := ""
@@ -134,14 +174,7 @@ func handleRolloutSubcommand(subcommand string, extraArgs []string, kubeconfig,
 			args = append(args, extraArgs...)
 		}
 		args = append(args, "--context", cinfo.Context)
-		cmdOutput, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(cmdOutput)
-		}
-		fmt.Println()
+		runClusterKubectl(cinfo, args, kubeconfig, "rollout "+subcommand)
 	}
 
 	// 2. Run for KubeStellar clusters (excluding ITS and current)
@@ -154,22 +187,239 @@ func handleRolloutSubcommand(subcommand string, extraArgs []string, kubeconfig,
 			args = append(args, extraArgs...)
 		}
 		args = append(args, "--context", c.Context)
-		cmdOutput, err := runKubectl(args, kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", c.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(cmdOutput)
-		}
-		fmt.Println()
+		runClusterKubectl(c, args, kubeconfig, "rollout "+subcommand)
 	}
 
 	// 3. Print warning for ITS (control) cluster
 	if cinfo, ok := contextToCluster[itsContext]; ok {
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
+		printClusterHeader(cinfo.Context)
 		fmt.Printf("Cannot perform this operation on ITS (control) cluster: %s\n", cinfo.Context)
 		fmt.Println()
 	}
 
 	return nil
 }
+
+// rolloutState is the rollout of a workload in one cluster
+type rolloutState struct {
+	Revision  string
+	Desired   int64
+	Updated   int64
+	Ready     int64
+	Available int64
+	Status    string
+	Done      bool
+	Failed    bool
+}
+
+// workloadRollout reads the rollout of a Deployment, StatefulSet or DaemonSet, with a
+// status following the messages of kubectl rollout status
+func workloadRollout(obj *unstructured.Unstructured) rolloutState {
+	field := func(fields ...string) int64 {
+		value, _, _ := unstructured.NestedInt64(obj.Object, fields...)
+		return value
+	}
+	state := rolloutState{Revision: "-"}
+	observed := field("status", "observedGeneration")
+
+	switch obj.GetKind() {
+	case "Deployment":
+		if revision := obj.GetAnnotations()["deployment.kubernetes.io/revision"]; revision != "" {
+			state.Revision = revision
+		}
+		state.Desired = 1
+		if replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+			state.Desired = replicas
+		}
+		state.Updated = field("status", "updatedReplicas")
+		state.Ready = field("status", "readyReplicas")
+		state.Available = field("status", "availableReplicas")
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			if condition, ok := c.(map[string]interface{}); ok && condition["type"] == "Progressing" && condition["reason"] == "ProgressDeadlineExceeded" {
+				state.Status, state.Failed = "ProgressDeadlineExceeded", true
+				return state
+			}
+		}
+		replicas := field("status", "replicas")
+		switch {
+		case obj.GetGeneration() > observed:
+			state.Status = "WaitingForSpecUpdate"
+		case state.Updated < state.Desired:
+			state.Status = fmt.Sprintf("Updating (%d of %d)", state.Updated, state.Desired)
+		case replicas > state.Updated:
+			state.Status = fmt.Sprintf("TerminatingOld (%d pending)", replicas-state.Updated)
+		case state.Available < state.Updated:
+			state.Status = fmt.Sprintf("WaitingForAvailable (%d of %d)", state.Available, state.Updated)
+		default:
+			state.Status, state.Done = "Complete", true
+		}
+	case "StatefulSet":
+		if revision, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision"); revision != "" {
+			state.Revision = revision
+		}
+		state.Desired = 1
+		if replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+			state.Desired = replicas
+		}
+		state.Updated = field("status", "updatedReplicas")
+		state.Ready = field("status", "readyReplicas")
+		state.Available = field("status", "availableReplicas")
+		current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+		switch {
+		case obj.GetGeneration() > observed:
+			state.Status = "WaitingForSpecUpdate"
+		case state.Ready < state.Desired:
+			state.Status = fmt.Sprintf("WaitingForReady (%d of %d)", state.Ready, state.Desired)
+		case state.Updated < state.Desired || (state.Revision != "-" && current != state.Revision):
+			state.Status = fmt.Sprintf("Updating (%d of %d)", state.Updated, state.Desired)
+		default:
+			state.Status, state.Done = "Complete", true
+		}
+	case "DaemonSet":
+		state.Desired = field("status", "desiredNumberScheduled")
+		state.Updated = field("status", "updatedNumberScheduled")
+		state.Ready = field("status", "numberReady")
+		state.Available = field("status", "numberAvailable")
+		switch {
+		case obj.GetGeneration() > observed:
+			state.Status = "WaitingForSpecUpdate"
+		case state.Updated < state.Desired:
+			state.Status = fmt.Sprintf("Updating (%d of %d)", state.Updated, state.Desired)
+		case state.Available < state.Desired:
+			state.Status = fmt.Sprintf("WaitingForAvailable (%d of %d)", state.Available, state.Desired)
+		default:
+			state.Status, state.Done = "Complete", true
+		}
+	default:
+		state.Status = "Unsupported kind " + obj.GetKind()
+		state.Failed = true
+	}
+	return state
+}
+
+// handleRolloutStatusWatch follows a rollout in all clusters in one table that is
+// redrawn whenever the object changes in a cluster. It returns once the rollout is
+// complete or failed in every cluster that has the object, and fails when it was
+// interrupted before or a cluster could not be read.
+func handleRolloutStatusWatch(args []string, kubeconfig, remoteCtx, namespace string) error {
+	var resourceType, name string
+	switch {
+	case len(args) == 1 && strings.Contains(args[0], "/"):
+		resourceType, name, _ = strings.Cut(args[0], "/")
+	case len(args) == 2:
+		resourceType, name = args[0], args[1]
+	default:
+		return fmt.Errorf("rollout status --watch requires exactly one TYPE/NAME or TYPE NAME")
+	}
+
+	server, client, err := newCachedServer(kubeconfig, remoteCtx)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	opts := multicluster.ServerOptions{Namespace: namespace, Name: name}
+	states := make(map[string]*rolloutState)
+	objects, failures := server.List(resourceType, nil, opts)
+	var unread []string
+	for _, failure := range failures {
+		warnClusterFailure(failure.Cluster, failure.Err, "%s", failure.Operation)
+		unread = append(unread, failure.Cluster)
+	}
+	for i := range objects {
+		state := workloadRollout(&objects[i].Unstructured)
+		states[objects[i].Cluster] = &state
+	}
+
+	var names []string
+	for _, clusterInfo := range client.Clusters() {
+		names = append(names, clusterInfo.Name)
+	}
+	sort.Strings(names)
+
+	out := util.GetOutputStream()
+	redraw := term.IsTerminal(int(os.Stdout.Fd()))
+	finished := func() bool {
+		for _, state := range states {
+			if !state.Done && !state.Failed {
+				return false
+			}
+		}
+		return len(states) > 0
+	}
+
+	printRolloutTable(out, resourceType+"/"+name, names, states, redraw)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if !finished() {
+		opts.WatchOnly = true
+		server.Watch(ctx, resourceType, nil, opts, func(event multicluster.WatchEvent) {
+			switch {
+			case event.Object == nil:
+				// Notices would scroll the redrawn table
+				if !redraw {
+					printWatchNotice(resourceType, event)
+				}
+				return
+			case event.Type == "DELETED":
+				delete(states, event.Cluster)
+			default:
+				state := workloadRollout(event.Object)
+				states[event.Cluster] = &state
+			}
+			printRolloutTable(out, resourceType+"/"+name, names, states, redraw)
+			if finished() {
+				stop()
+			}
+		})
+	}
+
+	if len(states) == 0 {
+		if len(unread) > 0 {
+			return fmt.Errorf("%s/%s not found in the clusters that could be read; failed to read clusters %s", resourceType, name, strings.Join(unread, ", "))
+		}
+		return fmt.Errorf("%s/%s not found in any cluster", resourceType, name)
+	}
+	var failed []string
+	for _, clusterName := range names {
+		if state, ok := states[clusterName]; ok && state.Failed {
+			failed = append(failed, clusterName)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("rollout of %s/%s failed in clusters %s", resourceType, name, strings.Join(failed, ", "))
+	}
+	// The watch also ends when it is interrupted
+	if !finished() {
+		return fmt.Errorf("stopped before the rollout of %s/%s finished in all clusters", resourceType, name)
+	}
+	// A cluster that could not be read may still be rolling out
+	if len(unread) > 0 {
+		sort.Strings(unread)
+		return fmt.Errorf("rollout of %s/%s is unknown in clusters %s, which could not be read", resourceType, name, strings.Join(unread, ", "))
+	}
+	return nil
+}
+
+// printRolloutTable prints one row per cluster, replacing the previous table on a
+// terminal
+func printRolloutTable(out io.Writer, object string, names []string, states map[string]*rolloutState, redraw bool) {
+	if redraw {
+		fmt.Fprint(out, "\033[H\033[2J")
+	} else {
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "Rollout of %s\n\n", object)
+	tw := newTable(out, nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tREVISION\tDESIRED\tUPDATED\tREADY\tAVAILABLE\tSTATUS\n")
+	for _, clusterName := range names {
+		state, ok := states[clusterName]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\t-\tNotFound\n", clusterName)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n", clusterName, state.Revision, state.Desired, state.Updated, state.Ready, state.Available, state.Status)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestWorkloadRollout ensures deployment rollouts are reported like kubectl rollout status
+func TestWorkloadRollout(t *testing.T) {
+	deployment := func(generation, observed, replicas, updated, available int64, conditions ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind": "Deployment",
+			"metadata": map[string]interface{}{
+				"generation":  generation,
+				"annotations": map[string]interface{}{"deployment.kubernetes.io/revision": "4"},
+			},
+			"spec": map[string]interface{}{"replicas": int64(3)},
+			"status": map[string]interface{}{
+				"observedGeneration": observed,
+				"replicas":           replicas,
+				"updatedReplicas":    updated,
+				"readyReplicas":      available,
+				"availableReplicas":  available,
+				"conditions":         conditions,
+			},
+		}}
+	}
+	deadline := map[string]interface{}{"type": "Progressing", "reason": "ProgressDeadlineExceeded"}
+
+	tests := []struct {
+		name   string
+		obj    *unstructured.Unstructured
+		status string
+		done   bool
+		failed bool
+	}{
+		{"spec update", deployment(2, 1, 3, 3, 3), "WaitingForSpecUpdate", false, false},
+		{"updating", deployment(2, 2, 4, 1, 3), "Updating (1 of 3)", false, false},
+		{"old replicas", deployment(2, 2, 4, 3, 3), "TerminatingOld (1 pending)", false, false},
+		{"available", deployment(2, 2, 3, 3, 2), "WaitingForAvailable (2 of 3)", false, false},
+		{"complete", deployment(2, 2, 3, 3, 3), "Complete", true, false},
+		{"deadline", deployment(2, 2, 4, 1, 3, deadline), "ProgressDeadlineExceeded", false, true},
+	}
+
+	for _, tt := range tests {
+		state := workloadRollout(tt.obj)
+		if state.Status != tt.status || state.Done != tt.done || state.Failed != tt.failed || state.Revision != "4" {
+			t.Errorf("%s: got %+v, want status %q done %v failed %v", tt.name, state, tt.status, tt.done, tt.failed)
+		}
+	}
+}
@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"flag"
 	"fmt"
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/config"
 	"kubectl-multi/pkg/util"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"k8s.io/cli-runtime/pkg/genericclioptions" // Add this import
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -15,6 +23,39 @@ var (
 	allClusters   bool
 	namespace     string
 	allNamespaces bool
+	cacheDir      string
+	cacheTTL      time.Duration
+	qps           float32
+	burst         int
+	reqTimeout    time.Duration
+	clusterTO     time.Duration
+	retries       int
+	errPolicyFlag string
+	failFast      bool
+	ignoreErrors  bool
+	errorOutput   string
+	errorFile     string
+	noProgress    bool
+	quiet         bool
+	breakerMax    int
+	breakerProbe  time.Duration
+	configFile    string
+	clusterNames  []string
+	profileName   string
+	themeName     string
+	layout        string
+	insecureTLS   bool
+	caFile        string
+	auditLogPath  string
+	readOnly      bool
+	skipSafety    bool
+	notifyURL     string
+	notifyMin     time.Duration
+	eventsFile    string
+	maxColWidth   int
+	fullWidth     bool
+	compatCheck   string
+	errPolicy     errorPolicy
 )
 
 // Custom help function for root command
@@ -124,7 +165,11 @@ func Execute() error {
 	// Set custom help function for root command
 	rootCmd.SetHelpFunc(rootHelpFunc)
 
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	finishHooks(err)
+	sendNotification(err)
+	closeEventsFile()
+	return err
 }
 
 func init() {
@@ -134,11 +179,144 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&allClusters, "all-clusters", true, "operate on all managed clusters")
 	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "target namespace")
 	rootCmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "list resources across all namespaces")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", config.DefaultPath(), "path to the kubectl-multi config file with flag defaults and cluster groups")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "named profile from the config file to use (default $KUBECTL_MULTI_PROFILE)")
+	rootCmd.PersistentFlags().StringSliceVar(&clusterNames, "clusters", nil, "comma separated list of clusters or cluster groups to operate on (default all)")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", filepath.Join(homedir.HomeDir(), ".kube", "cache"), "directory for the API discovery cache (empty disables caching)")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 6*time.Hour, "how long cached API discovery results are used before they are refreshed")
+
+	rootCmd.PersistentFlags().Float32Var(&qps, "qps", 50, "maximum queries per second to each cluster's API server")
+	rootCmd.PersistentFlags().IntVar(&burst, "burst", 100, "maximum burst of requests to each cluster's API server")
+	rootCmd.PersistentFlags().DurationVar(&reqTimeout, "request-timeout", 0, "time to wait for a single API request before giving up (0 waits forever)")
+	rootCmd.PersistentFlags().BoolVar(&insecureTLS, "insecure-skip-tls-verify", false, "do not verify the API server certificates of the selected clusters (per cluster: clusterTLS in the config file)")
+	rootCmd.PersistentFlags().StringVar(&caFile, "certificate-authority", "", "CA file to verify the API server certificates of the selected clusters with (per cluster: clusterTLS in the config file)")
+
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 3, "number of times a read request is retried after throttling or transient errors")
+	rootCmd.PersistentFlags().IntVar(&breakerMax, "breaker-threshold", 5, "consecutive failures after which a cluster is marked degraded and no longer queried until a probe succeeds, in serve, ui and watches (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&breakerProbe, "breaker-probe-interval", 30*time.Second, "how often a degraded cluster is probed for recovery")
+	rootCmd.PersistentFlags().DurationVar(&clusterTO, "cluster-timeout", 0, "maximum time the API calls to a single cluster may take, e.g. 15s (0 waits forever)")
+
+	rootCmd.PersistentFlags().BoolVar(&failFast, "fail-fast", false, "abort the remaining clusters as soon as one cluster fails")
+	rootCmd.PersistentFlags().BoolVar(&ignoreErrors, "ignore-errors", false, "print cluster failures as warnings on stderr and exit 0 if at least one cluster succeeded")
+	rootCmd.PersistentFlags().StringVar(&errPolicyFlag, "error-policy", "all", "when failed clusters make the command exit non-zero: any, all or threshold=N%")
+
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "print only data rows, without cluster headers, warnings, failure summary or progress")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "default", "color theme for cluster headers and status values: default, bright, mono or none")
+	rootCmd.PersistentFlags().StringVar(&layout, "layout", "sections", "how the output of several clusters is combined: sections with a header per cluster, or merged into one table or list")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "do not show the progress line on stderr while clusters are queried")
+	rootCmd.PersistentFlags().StringVar(&errorOutput, "error-output", "text", "format of the cluster failure report: text or json")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "disable all commands that change clusters")
+	rootCmd.PersistentFlags().BoolVar(&skipSafety, "override-safety", false, "allow mutating commands on the clusters protected by mutationDeniedClusters or mutationAllowedClusters")
+	rootCmd.PersistentFlags().StringVar(&auditLogPath, "audit-log", "", "append a JSON line per cluster for every mutating command to this file")
+	rootCmd.PersistentFlags().StringVar(&notifyURL, "notify", "", "post a JSON summary of the command to this webhook URL, e.g. a Slack incoming webhook, when it finishes")
+	rootCmd.PersistentFlags().DurationVar(&notifyMin, "notify-after", 0, "only notify about commands that ran at least this long, e.g. 5m")
+	rootCmd.PersistentFlags().IntVar(&maxColWidth, "max-column-width", 80, "shorten table cells longer than this in the middle, e.g. long image names")
+	rootCmd.PersistentFlags().BoolVar(&fullWidth, "full-width", false, "print table cells in full, ignoring --max-column-width")
+	rootCmd.PersistentFlags().StringVar(&eventsFile, "events-file", "", "write progress events (ClusterStarted, ClusterSucceeded, ClusterFailed, RowEmitted) as JSON lines to this file, - for stderr")
+	rootCmd.PersistentFlags().StringVar(&compatCheck, "compat-check", "warn", "on startup, compare the plugin with the KubeStellar release of the WDS: warn about known incompatible releases, refuse them, or off")
+	rootCmd.PersistentFlags().StringVar(&errorFile, "error-file", "", "write the cluster failure report to this file instead of stderr (requires --error-output json)")
+
+	// -v and --vmodule control the klog verbosity, e.g. -v 2 logs per-cluster timings and
+	// retries and -v 6 or higher logs every API request like kubectl does
+	klogFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(klogFlags)
+	rootCmd.PersistentFlags().AddGoFlag(klogFlags.Lookup("v"))
+	rootCmd.PersistentFlags().AddGoFlag(klogFlags.Lookup("vmodule"))
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := loadUserConfig(cmd); err != nil {
+			return err
+		}
+
+		if err := checkReadOnly(cmd); err != nil {
+			return err
+		}
+
+		policy, err := parseErrorPolicy(errPolicyFlag)
+		if err != nil {
+			return err
+		}
+		errPolicy = policy
+		if errorOutput != "text" && errorOutput != "json" {
+			return fmt.Errorf("invalid error output %q, expected text or json", errorOutput)
+		}
+		if errorFile != "" && errorOutput != "json" {
+			return fmt.Errorf("--error-file requires --error-output json")
+		}
+		if layout != "sections" && layout != "merged" {
+			return fmt.Errorf("invalid layout %q, expected sections or merged", layout)
+		}
+		if failFast && ignoreErrors {
+			return fmt.Errorf("--fail-fast and --ignore-errors cannot be used together")
+		}
+		if compatCheck != "refuse" && compatCheck != "warn" && compatCheck != "off" {
+			return fmt.Errorf("invalid compat check %q, expected refuse, warn or off", compatCheck)
+		}
+
+		cluster.SetDiscoveryCache(cacheDir, cacheTTL)
+		cluster.SetClientOptions(qps, burst, reqTimeout)
+		cluster.SetClusterTimeout(clusterTO)
+		cluster.SetRetries(retries)
+		cluster.SetClusterFilter(userConfig.ExpandClusters(clusterNames))
+		cluster.SetNamespaceDefaults(userConfig.ClusterNamespaces)
+		cluster.SetCredentialOverrides(userConfig.ClusterCredentials)
+		cluster.SetClusterProviders(userConfig.ClusterProviders)
+		cluster.SetTLSOverrides(userConfig.ClusterTLS, config.TLS{InsecureSkipTLSVerify: insecureTLS, CertificateAuthority: caFile})
+		// One-shot commands are bounded by the timeouts, only the sessions that keep
+		// querying a dead cluster need the breaker
+		if longRunningCommand(cmd) {
+			cluster.SetCircuitBreaker(breakerMax, breakerProbe)
+		} else {
+			cluster.SetCircuitBreaker(0, breakerProbe)
+		}
+		cluster.SetFailFast(failFast)
+		cluster.SetQuiet(quiet)
+		if err := checkStartupCompat(cmd, kubeconfig); err != nil {
+			return err
+		}
+		// Colors are only written to terminals and can be turned off with NO_COLOR
+		_, noColor := os.LookupEnv("NO_COLOR")
+		if err := util.SetTheme(themeName, !noColor && term.IsTerminal(int(os.Stdout.Fd()))); err != nil {
+			return err
+		}
+		if err := openAuditLog(auditLogPath); err != nil {
+			return err
+		}
+		if err := openEventsFile(eventsFile); err != nil {
+			return err
+		}
+		// The progress line only makes sense when a person is watching both streams
+		cluster.SetProgress(!quiet && !noProgress && term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stderr.Fd())))
+		commandStart = time.Now()
+		return startHooks(cmd, args)
+	}
+	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		closeAuditLog()
+		cluster.ClearProgress()
+		klog.Flush()
+		reportAPIWarnings()
+		// The summary already explains the failure, usage would only bury it
+		cmd.SilenceUsage = true
+		return reportClusterResults(errPolicy)
+	}
+
+	rootCmd.RegisterFlagCompletionFunc("clusters", completeClusterNames)
+	rootCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	// Replaced by newCompletionCommand, which explains the kubectl plugin setup
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	// Add subcommands
 	rootCmd.AddCommand(newGetCommand())
 	rootCmd.AddCommand(newDescribeCommand())
 	rootCmd.AddCommand(newApplyCommand())
+	rootCmd.AddCommand(newKustomizeCommand())
+	rootCmd.AddCommand(newHelmCommand())
+	rootCmd.AddCommand(newNsCommand())
+	rootCmd.AddCommand(newCtxCommand())
+	rootCmd.AddCommand(newSnapshotCommand())
+	rootCmd.AddCommand(newOrphansCommand())
+	rootCmd.AddCommand(newClustersCommand())
+	rootCmd.AddCommand(newAnnotateClustersCommand())
 	rootCmd.AddCommand(newDeleteCommand())
 	rootCmd.AddCommand(newLogsCommand())
 	rootCmd.AddCommand(newExecCommand())
@@ -147,10 +325,24 @@ func init() {
 	rootCmd.AddCommand(newPatchCommand())
 	rootCmd.AddCommand(newScaleCommand())
 	rootCmd.AddCommand(newRolloutCommand())
+	rootCmd.AddCommand(newWaitCommand())
 	rootCmd.AddCommand(newPortForwardCommand())
 	rootCmd.AddCommand(newTopCommand())
 	rootCmd.AddCommand(newRunCommand())
+	rootCmd.AddCommand(newPassthroughCommand())
 	rootCmd.AddCommand(newMultiGetCommand()) // Register multiget
+	rootCmd.AddCommand(newDoctorCommand())
+	rootCmd.AddCommand(newCapacityCommand())
+	rootCmd.AddCommand(newUtilizationCommand())
+	rootCmd.AddCommand(newDeprecationsCommand())
+	rootCmd.AddCommand(newFindCommand())
+	rootCmd.AddCommand(newPresenceCommand())
+	rootCmd.AddCommand(newNodesCommand())
+	rootCmd.AddCommand(newSecurityReportCommand())
+	rootCmd.AddCommand(newSummaryCommand())
+	rootCmd.AddCommand(newUICommand())
+	rootCmd.AddCommand(newServeCommand())
+	rootCmd.AddCommand(newCompletionCommand())
 	rootCmd.AddCommand(util.VersionCmd)
 
 	// Add the install command - NEW LINE
@@ -160,9 +352,29 @@ func init() {
 		ErrOut: os.Stderr,
 	}
 	rootCmd.AddCommand(NewInstallCmd(streams))
+	rootCmd.AddCommand(newUpgradeCommand())
+	rootCmd.AddCommand(newUninstallCommand())
+	rootCmd.AddCommand(newCompatCommand())
+	rootCmd.AddCommand(newJoinCommand())
+	rootCmd.AddCommand(newDetachCommand())
 }
 
 // GetGlobalFlags returns the global flags that can be used by subcommands
 func GetGlobalFlags() (string, string, bool, string, bool) {
 	return kubeconfig, remoteCtx, allClusters, namespace, allNamespaces
 }
+
+// longRunningCommand reports whether a command keeps querying the clusters until it is
+// stopped: serve, ui and the watches of get and rollout status
+func longRunningCommand(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "serve", "ui":
+		return true
+	}
+	for _, name := range []string{"watch", "watch-only", "diff-watch"} {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Value.String() == "true" {
+			return true
+		}
+	}
+	return false
+}
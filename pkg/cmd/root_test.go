@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
 )
 
 // TestRootFlags ensures all expected global flags are registered
@@ -89,3 +91,34 @@ func TestRootExecuteInvalidSubcommand(t *testing.T) {
 		t.Errorf("unexpected error message: %v", err)
 	}
 }
+
+// TestLongRunningCommand ensures the circuit breaker is only turned on for the
+// commands that keep querying the clusters
+func TestLongRunningCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		cmd   func() *cobra.Command
+		flags []string
+		want  bool
+	}{
+		{name: "get", cmd: newGetCommand, want: false},
+		{name: "get --watch", cmd: newGetCommand, flags: []string{"--watch"}, want: true},
+		{name: "get --watch-only", cmd: newGetCommand, flags: []string{"--watch-only"}, want: true},
+		{name: "rollout status", cmd: newRolloutStatusCommand, want: false},
+		{name: "rollout status --watch", cmd: newRolloutStatusCommand, flags: []string{"--watch"}, want: true},
+		{name: "serve", cmd: newServeCommand, want: true},
+		{name: "ui", cmd: newUICommand, want: true},
+		{name: "describe", cmd: newDescribeCommand, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.cmd()
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+			if got := longRunningCommand(cmd); got != tt.want {
+				t.Errorf("longRunningCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
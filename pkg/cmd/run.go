@@ -11,8 +11,9 @@ import (
 
 func newRunCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "run",
-		Short: "Create and run a particular image in a pod across all managed clusters",
+		Use:         "run",
+		Short:       "Create and run a particular image in a pod across all managed clusters",
+		Annotations: map[string]string{mutatingAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Check for interactive flags
 			for _, arg := range args {
@@ -37,6 +38,17 @@ func handleRunMulti(args []string, kubeconfig, remoteCtx string) error {
 	if len(clusters) == 0 {
 		return fmt.Errorf("no clusters discovered")
 	}
+	if err := checkMutationTargets(clusters, remoteCtx); err != nil {
+		return err
+	}
+	confirmed, err := confirmCommand("run", clusters, remoteCtx)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Run cancelled...")
+		return nil
+	}
 
 	// Find current context from kubeconfig
 	currentContext := ""
@@ -63,14 +75,7 @@ func handleRunMulti(args []string, kubeconfig, remoteCtx string) error {
 
 	// 1. Run for current context (if present)
 	if cinfo, ok := contextToCluster[currentContext]; ok {
-		output, err := runKubectl(append([]string{"run"}, append(args, "--context", cinfo.Context)...), kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
-		}
-		fmt.Println()
+		runClusterKubectl(cinfo, append([]string{"run"}, append(args, "--context", cinfo.Context)...), kubeconfig, "run")
 	}
 
 	// 2. Run for KubeStellar clusters (excluding ITS and current)
@@ -78,19 +83,12 @@ func handleRunMulti(args []string, kubeconfig, remoteCtx string) error {
 		if c.Context == currentContext || c.Context == itsContext {
 			continue
 		}
-		output, err := runKubectl(append([]string{"run"}, append(args, "--context", c.Context)...), kubeconfig)
-		fmt.Printf("=== Cluster: %s ===\n", c.Context)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Print(output)
-		}
-		fmt.Println()
+		runClusterKubectl(c, append([]string{"run"}, append(args, "--context", c.Context)...), kubeconfig, "run")
 	}
 
 	// 3. Print warning for ITS (control) cluster
 	if cinfo, ok := contextToCluster[itsContext]; ok {
-		fmt.Printf("=== Cluster: %s ===\n", cinfo.Context)
+		printClusterHeader(cinfo.Context)
 		fmt.Printf("Cannot perform this operation on ITS (control) cluster: %s\n", cinfo.Context)
 		fmt.Println()
 	}
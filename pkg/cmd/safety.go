@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/config"
+)
+
+// mutatingAnnotation marks the commands that change clusters
+const mutatingAnnotation = "kubectl-multi/mutating"
+
+// isMutatingCommand reports whether cmd changes clusters
+func isMutatingCommand(cmd *cobra.Command) bool {
+	return cmd.Annotations[mutatingAnnotation] == "true"
+}
+
+// safetySettings returns the safety settings at the top of the config file, of its
+// defaults and of the selected profile. Each of them only adds protection.
+func safetySettings() []config.Settings {
+	settings := []config.Settings{
+		{
+			ReadOnly:                userConfig.ReadOnly,
+			MutationAllowedClusters: userConfig.MutationAllowedClusters,
+			MutationDeniedClusters:  userConfig.MutationDeniedClusters,
+		},
+		userConfig.Defaults,
+	}
+	if profileName != "" {
+		if profile, err := userConfig.Profile(profileName); err == nil {
+			settings = append(settings, profile)
+		}
+	}
+	return settings
+}
+
+// readOnlyMode reports whether mutating commands are disabled. readOnly in the config
+// file cannot be turned off from the command line.
+func readOnlyMode() bool {
+	if readOnly {
+		return true
+	}
+	for _, settings := range safetySettings() {
+		if settings.ReadOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReadOnly refuses to run a mutating command in read-only mode
+func checkReadOnly(cmd *cobra.Command) error {
+	if readOnlyMode() && isMutatingCommand(cmd) {
+		return fmt.Errorf("%q changes clusters and is disabled in read-only mode", cmd.CommandPath())
+	}
+	return nil
+}
+
+// mutationProtected reports whether the config file protects a cluster from mutating
+// commands. A cluster must pass the allow and deny lists of every safety setting.
+func mutationProtected(clusterName string) bool {
+	for _, settings := range safetySettings() {
+		if clusterListed(settings.MutationDeniedClusters, clusterName) {
+			return true
+		}
+		if len(settings.MutationAllowedClusters) > 0 && !clusterListed(settings.MutationAllowedClusters, clusterName) {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterListed reports whether a list of clusters and cluster groups holds a cluster
+func clusterListed(names []string, clusterName string) bool {
+	for _, name := range userConfig.ExpandClusters(names) {
+		if name == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMutationTargets refuses to run a mutating command when any of its target
+// clusters is protected, unless --override-safety is set. The ITS cluster is never
+// changed by the commands and therefore not checked.
+func checkMutationTargets(clusters []cluster.ClusterInfo, itsContext string) error {
+	if skipSafety {
+		return nil
+	}
+	var protected []string
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Context != itsContext && mutationProtected(clusterInfo.Name) {
+			protected = append(protected, clusterInfo.Name)
+		}
+	}
+	if len(protected) > 0 {
+		return fmt.Errorf("refusing to change protected clusters %s; exclude them with --clusters or pass --override-safety", strings.Join(protected, ", "))
+	}
+	return nil
+}
+
+// defaultConfirmations apply when the config file defines no confirmations at the top
+var defaultConfirmations = []config.Confirmation{{Command: "delete"}}
+
+// activeConfirmations returns the confirmation policy: the confirmations at the top of
+// the config file, or the default ones when it defines none, and those of the defaults
+// and the selected profile, which only add to them
+func activeConfirmations() []config.Confirmation {
+	confirmations := append([]config.Confirmation{}, defaultConfirmations...)
+	if userConfig.Confirmations != nil {
+		confirmations = append([]config.Confirmation{}, *userConfig.Confirmations...)
+	}
+	for _, settings := range safetySettings() {
+		confirmations = append(confirmations, settings.Confirmations...)
+	}
+	return confirmations
+}
+
+// confirmCommand asks for the confirmation phrase when the confirmation policy covers
+// the command and its target clusters, and reports whether the command may run
+func confirmCommand(command string, clusters []cluster.ClusterInfo, itsContext string) (bool, error) {
+	for _, confirmation := range activeConfirmations() {
+		if confirmation.Command != command {
+			continue
+		}
+
+		exempt := make(map[string]bool)
+		for _, name := range userConfig.ExpandClusters(confirmation.ExemptClusters) {
+			exempt[name] = true
+		}
+		var targets []string
+		for _, clusterInfo := range clusters {
+			if clusterInfo.Context != itsContext && !exempt[clusterInfo.Name] {
+				targets = append(targets, clusterInfo.Name)
+			}
+		}
+		if len(targets) == 0 || len(targets) < confirmation.MinClusters {
+			continue
+		}
+
+		phrase := confirmation.Phrase
+		if phrase == "" {
+			phrase = "yes"
+		}
+		fmt.Printf("Are you sure you want to run %q on %d cluster(s): %s ?\n", command, len(targets), strings.Join(targets, ", "))
+		fmt.Printf("Type '%s' to confirm, or anything else to cancel.\n", phrase)
+		response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("failed to read confirmation: %v", err)
+		}
+		// The phrase must be typed exactly, a phrase such as DELETE PROD is chosen to make
+		// the user stop and read
+		if strings.TrimSpace(response) != phrase {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// confirmYes asks to type yes before a command that was previewed runs
+func confirmYes() (bool, error) {
+	fmt.Print("Type 'yes' to continue: ")
+	response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %v", err)
+	}
+	return strings.TrimSpace(response) == "yes", nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/config"
+)
+
+// TestProfileSafetySettings ensures the safety settings of the selected profile apply
+// on top of those at the top of the config file
+func TestProfileSafetySettings(t *testing.T) {
+	savedConfig, savedProfile, savedReadOnly := userConfig, profileName, readOnly
+	defer func() { userConfig, profileName, readOnly = savedConfig, savedProfile, savedReadOnly }()
+
+	readOnly = false
+	userConfig = &config.Config{
+		MutationDeniedClusters: []string{"cluster3"},
+		Profiles: map[string]config.Settings{
+			"prod": {ReadOnly: true, MutationAllowedClusters: []string{"cluster1", "cluster2"}},
+			"lab":  {},
+		},
+	}
+	apply := &cobra.Command{Use: "apply", Annotations: map[string]string{mutatingAnnotation: "true"}}
+
+	profileName = "lab"
+	if err := checkReadOnly(apply); err != nil {
+		t.Errorf("--profile lab: unexpected error %v", err)
+	}
+	if mutationProtected("cluster4") {
+		t.Errorf("--profile lab: cluster4 should not be protected")
+	}
+
+	profileName = "prod"
+	if err := checkReadOnly(apply); err == nil {
+		t.Errorf("--profile prod: expected apply to be refused in read-only mode")
+	}
+	for clusterName, want := range map[string]bool{"cluster1": false, "cluster3": true, "cluster4": true} {
+		if got := mutationProtected(clusterName); got != want {
+			t.Errorf("--profile prod: mutationProtected(%s) = %v, want %v", clusterName, got, want)
+		}
+	}
+}
+
+// TestOverrideSafetyNotFromEnv ensures --override-safety must be given on the command
+// line, while the other global flags are read from the environment
+func TestOverrideSafetyNotFromEnv(t *testing.T) {
+	root := &cobra.Command{Use: "kubectl-multi"}
+	root.PersistentFlags().Bool("override-safety", false, "")
+	root.PersistentFlags().String("namespace", "", "")
+	apply := &cobra.Command{Use: "apply"}
+	root.AddCommand(apply)
+	if err := apply.ParseFlags(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("KUBECTL_MULTI_OVERRIDE_SAFETY", "true")
+	t.Setenv("KUBECTL_MULTI_NAMESPACE", "shop")
+	if err := applyEnv(apply); err != nil {
+		t.Fatal(err)
+	}
+	if got := apply.Flags().Lookup("override-safety").Value.String(); got != "false" {
+		t.Errorf("override-safety = %s, want false", got)
+	}
+	if got := apply.Flags().Lookup("namespace").Value.String(); got != "shop" {
+		t.Errorf("namespace = %s, want shop", got)
+	}
+}
+
+// TestActiveConfirmations ensures an empty confirmations list turns the default
+// confirmation of delete off, while profiles only add confirmations
+func TestActiveConfirmations(t *testing.T) {
+	savedConfig, savedProfile := userConfig, profileName
+	defer func() { userConfig, profileName = savedConfig, savedProfile }()
+
+	restart := config.Confirmation{Command: "rollout restart", MinClusters: 5}
+	tests := []struct {
+		name          string
+		confirmations *[]config.Confirmation
+		profile       []config.Confirmation
+		want          []string
+	}{
+		{name: "unset", want: []string{"delete"}},
+		{name: "empty", confirmations: &[]config.Confirmation{}, want: []string{}},
+		{name: "replaced", confirmations: &[]config.Confirmation{restart}, want: []string{"rollout restart"}},
+		{name: "profile adds", profile: []config.Confirmation{restart}, want: []string{"delete", "rollout restart"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userConfig = &config.Config{
+				Confirmations: tt.confirmations,
+				Profiles:      map[string]config.Settings{"prod": {Confirmations: tt.profile}},
+			}
+			profileName = "prod"
+			got := []string{}
+			for _, confirmation := range activeConfirmations() {
+				got = append(got, confirmation.Command)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("activeConfirmations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConfirmCommandExactPhrase ensures the confirmation phrase must be typed exactly
+func TestConfirmCommandExactPhrase(t *testing.T) {
+	savedConfig, savedProfile, savedStdin := userConfig, profileName, os.Stdin
+	defer func() { userConfig, profileName, os.Stdin = savedConfig, savedProfile, savedStdin }()
+
+	profileName = ""
+	userConfig = &config.Config{Confirmations: &[]config.Confirmation{{Command: "delete", Phrase: "DELETE PROD"}}}
+	clusters := []cluster.ClusterInfo{{Name: "cluster1", Context: "cluster1"}}
+	for response, want := range map[string]bool{"DELETE PROD\n": true, "delete prod\n": false} {
+		stdin, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.WriteString(response)
+		writer.Close()
+		os.Stdin = stdin
+		got, err := confirmCommand("delete", clusters, "its1")
+		stdin.Close()
+		if err != nil {
+			t.Fatalf("confirmCommand() failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("confirmCommand() with %q = %v, want %v", response, got, want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// securityTotals counts the pods of one cluster per security finding
+type securityTotals struct {
+	Name       string
+	Pods       int
+	Privileged int
+	HostAccess int
+	Root       int
+	NoLimits   int
+}
+
+func newSecurityReportCommand() *cobra.Command {
+	var summaryOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "security-report",
+		Short: "Audit the security posture of pods across managed clusters",
+		Long: `Audit the pods of every managed cluster and list the ones that:
+  - run privileged containers
+  - use the host network, PID or IPC namespace
+  - may run as root (runAsNonRoot not set and no non-zero runAsUser)
+  - have containers without CPU or memory limits
+
+Per-cluster totals are printed after the findings. All namespaces are audited
+unless a namespace is given with -n.`,
+		Example: `# Audit all pods in all clusters
+kubectl multi security-report
+
+# Audit a single namespace
+kubectl multi security-report -n shop
+
+# Only print the per-cluster totals
+kubectl multi security-report --summary`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			return handleSecurityReportCommand(summaryOnly, kubeconfig, remoteCtx, namespace)
+		},
+	}
+
+	cmd.Flags().BoolVar(&summaryOnly, "summary", false, "only print the per-cluster totals")
+
+	return cmd
+}
+
+func handleSecurityReportCommand(summaryOnly bool, kubeconfig, remoteCtx, namespace string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	if !summaryOnly {
+		fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tPOD\tFINDINGS\n")
+	}
+
+	var allTotals []securityTotals
+	found := false
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		ctx, cancel := clusterInfo.RequestContext()
+
+		pods, err := clusterInfo.Client().CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "list pods")
+			continue
+		}
+
+		totals := securityTotals{Name: clusterInfo.Name, Pods: len(pods.Items)}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			findings := podSecurityFindings(pod)
+			if len(findings) == 0 {
+				continue
+			}
+			totals.add(findings)
+			if !summaryOnly {
+				found = true
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", clusterInfo.Name, pod.Namespace, pod.Name, strings.Join(findings, ","))
+			}
+		}
+		allTotals = append(allTotals, totals)
+	}
+
+	if !summaryOnly {
+		if !found {
+			fmt.Fprintf(tw, "No findings.\n")
+		}
+		fmt.Fprintln(tw)
+	}
+
+	fleet := securityTotals{Name: "<fleet>"}
+	fmt.Fprintf(tw, "CLUSTER\tPODS\tPRIVILEGED\tHOST NAMESPACES\tROOT\tNO LIMITS\n")
+	for _, totals := range allTotals {
+		printSecurityTotals(tw, totals)
+		fleet.Pods += totals.Pods
+		fleet.Privileged += totals.Privileged
+		fleet.HostAccess += totals.HostAccess
+		fleet.Root += totals.Root
+		fleet.NoLimits += totals.NoLimits
+	}
+	printSecurityTotals(tw, fleet)
+	tw.Flush()
+
+	return nil
+}
+
+// add counts a pod with the given findings
+func (t *securityTotals) add(findings []string) {
+	hostAccess := false
+	for _, finding := range findings {
+		switch finding {
+		case "privileged":
+			t.Privileged++
+		case "hostNetwork", "hostPID", "hostIPC":
+			hostAccess = true
+		case "root":
+			t.Root++
+		case "no-limits":
+			t.NoLimits++
+		}
+	}
+	if hostAccess {
+		t.HostAccess++
+	}
+}
+
+func printSecurityTotals(tw tableWriter, t securityTotals) {
+	fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", t.Name, t.Pods, t.Privileged, t.HostAccess, t.Root, t.NoLimits)
+}
+
+// podSecurityFindings returns the security findings of a pod, each reported once
+func podSecurityFindings(pod *corev1.Pod) []string {
+	var findings []string
+	if pod.Spec.HostNetwork {
+		findings = append(findings, "hostNetwork")
+	}
+	if pod.Spec.HostPID {
+		findings = append(findings, "hostPID")
+	}
+	if pod.Spec.HostIPC {
+		findings = append(findings, "hostIPC")
+	}
+
+	privileged, root, noLimits := false, false, false
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		sc := container.SecurityContext
+		if sc != nil && sc.Privileged != nil && *sc.Privileged {
+			privileged = true
+		}
+		if mayRunAsRoot(pod.Spec.SecurityContext, sc) {
+			root = true
+		}
+		if container.Resources.Limits.Cpu().IsZero() || container.Resources.Limits.Memory().IsZero() {
+			noLimits = true
+		}
+	}
+
+	if privileged {
+		findings = append(findings, "privileged")
+	}
+	if root {
+		findings = append(findings, "root")
+	}
+	if noLimits {
+		findings = append(findings, "no-limits")
+	}
+	return findings
+}
+
+// mayRunAsRoot reports whether a container is not prevented from running as root,
+// with container-level settings taking precedence over pod-level ones
+func mayRunAsRoot(podSC *corev1.PodSecurityContext, sc *corev1.SecurityContext) bool {
+	var runAsNonRoot *bool
+	var runAsUser *int64
+	if podSC != nil {
+		runAsNonRoot = podSC.RunAsNonRoot
+		runAsUser = podSC.RunAsUser
+	}
+	if sc != nil {
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+		if sc.RunAsUser != nil {
+			runAsUser = sc.RunAsUser
+		}
+	}
+
+	if runAsUser != nil {
+		return *runAsUser == 0
+	}
+	return runAsNonRoot == nil || !*runAsNonRoot
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+
+	"kubectl-multi/pkg/multicluster"
+)
+
+// serveTokenPath returns the file serve writes its generated bearer token to, which
+// get --server reads by default
+func serveTokenPath() string {
+	return filepath.Join(homedir.HomeDir(), ".kubectl-multi", "serve-token")
+}
+
+func newServeCommand() *cobra.Command {
+	var listen string
+	var tokenFile string
+	var noAuth bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the resources of all managed clusters over HTTP from warm caches",
+		Long: `Run a long-lived daemon that keeps the clients of all managed clusters and an
+informer per requested resource type warm, and answers list, get and watch requests
+for all clusters from these caches. Dashboards and scripts avoid the discovery and
+connection setup every command otherwise pays.
+
+Endpoints:
+  GET /v1/list?resource=TYPE[&namespace=NS|&allNamespaces=true][&selector=SEL][&clusters=C1,C2]
+  GET /v1/get?resource=TYPE&name=NAME[...]
+  GET /v1/watch?resource=TYPE[...]     one JSON event per line
+  GET /clusters
+  GET /clusters/CLUSTER/resources/TYPE[?...]
+  GET /fleet/TYPE[?...]
+  GET /healthz
+
+Every request except /healthz needs the bearer token of the daemon in an
+Authorization header. The token is read from --token-file, or else generated at
+startup and written to ~/.kubectl-multi/serve-token, which get --server reads. The
+Host header must name the listen address, so web pages cannot reach the daemon
+through DNS rebinding. --no-auth turns the token off and is only allowed on
+loopback addresses.`,
+		Example: `# Serve on the default address and list pods of all clusters
+kubectl multi serve &
+curl -H "Authorization: Bearer $(cat ~/.kubectl-multi/serve-token)" 'http://localhost:8765/v1/list?resource=pods&allNamespaces=true'
+
+# Stream deployment changes of two clusters
+curl -N -H "Authorization: Bearer $(cat ~/.kubectl-multi/serve-token)" 'http://localhost:8765/v1/watch?resource=deployments&clusters=cluster1,cluster2'
+
+# Serve other hosts with a token managed elsewhere
+kubectl multi serve --listen 0.0.0.0:8765 --token-file /etc/kubectl-multi/token`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleServeCommand(listen, tokenFile, noAuth, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "localhost:8765", "address to serve the HTTP API on")
+	cmd.Flags().StringVar(&tokenFile, "token-file", "", "file with the bearer token clients must send, instead of a generated one")
+	cmd.Flags().BoolVar(&noAuth, "no-auth", false, "serve without a bearer token, only allowed on loopback addresses")
+	return cmd
+}
+
+func handleServeCommand(listen, tokenFile string, noAuth bool, kubeconfig, remoteCtx string) error {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return fmt.Errorf("invalid --listen address %q: %v", listen, err)
+	}
+	if noAuth && !isLoopbackHost(host) {
+		return fmt.Errorf("refusing to serve %s without authentication; remove --no-auth or listen on a loopback address", listen)
+	}
+	if noAuth && tokenFile != "" {
+		return fmt.Errorf("--token-file and --no-auth cannot be used together")
+	}
+
+	token := ""
+	if tokenFile != "" {
+		if token, err = readServeToken(tokenFile); err != nil {
+			return err
+		}
+	} else if !noAuth {
+		if token, err = generateServeToken(); err != nil {
+			return err
+		}
+		defer os.Remove(serveTokenPath())
+	}
+
+	server, client, err := newCachedServer(kubeconfig, remoteCtx)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	handler := multicluster.Protect(server.Handler(), token, allowedHosts(host, port))
+	httpServer := &http.Server{Addr: listen, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Serving %d clusters on http://%s\n", len(client.Clusters()), listen)
+		if token != "" && tokenFile == "" {
+			fmt.Fprintf(os.Stderr, "Bearer token written to %s\n", serveTokenPath())
+		}
+	}
+	klog.V(1).Infof("Serving clusters on %s", listen)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to serve: %v", err)
+	}
+	return nil
+}
+
+// readServeToken returns the bearer token in a file, without surrounding whitespace
+func readServeToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the token file: %v", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// generateServeToken returns a random bearer token and writes it to serveTokenPath,
+// readable only by the user
+func generateServeToken() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate a token: %v", err)
+	}
+	token := hex.EncodeToString(random)
+	path := serveTokenPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("failed to write the token: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write the token: %v", err)
+	}
+	return token, nil
+}
+
+// isLoopbackHost reports whether the host of a listen address only accepts
+// connections from this machine
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// allowedHosts returns the Host headers serve accepts for a listen address: the
+// address itself, and its other loopback names when it is a loopback address. A
+// wildcard address accepts any Host, which leaves the token as the only check.
+func allowedHosts(host, port string) []string {
+	if host == "" || net.ParseIP(host) != nil && net.ParseIP(host).IsUnspecified() {
+		return nil
+	}
+	hosts := []string{net.JoinHostPort(host, port)}
+	if isLoopbackHost(host) {
+		for _, name := range []string{"localhost", "127.0.0.1", "::1"} {
+			hosts = append(hosts, net.JoinHostPort(name, port))
+		}
+	}
+	return hosts
+}
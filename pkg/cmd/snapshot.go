@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/multicluster"
+	"kubectl-multi/pkg/util"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// snapshotManifestFile describes a snapshot in the top directory of the snapshot
+const snapshotManifestFile = "snapshot.yaml"
+
+// clusterScopedDir holds the cluster-scoped objects in place of a namespace directory
+const clusterScopedDir = "_cluster"
+
+// snapshotManifest records what a snapshot contains, so that it can be taken again
+type snapshotManifest struct {
+	Time          time.Time `json:"time"`
+	Resources     []string  `json:"resources"`
+	Namespace     string    `json:"namespace,omitempty"`
+	AllNamespaces bool      `json:"allNamespaces,omitempty"`
+	ShowSecrets   bool      `json:"showSecrets,omitempty"`
+}
+
+// serverAnnotations are set by the API server or kubectl rather than by the user
+var serverAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+func newSnapshotCommand() *cobra.Command {
+	var resources []string
+	var dir string
+	var showSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "snapshot --resources TYPES -o DIR",
+		Short: "Save the selected resources of all managed clusters to a directory",
+		Long: `Save the selected resources of every managed cluster as YAML files in a directory
+tree keyed by cluster:
+
+  DIR/CLUSTER/NAMESPACE/RESOURCE/NAME.yaml
+
+Cluster-scoped objects are saved under the _cluster namespace directory. Status and
+the fields set by the API server, such as the uid, resourceVersion and managedFields,
+are removed, so that snapshots of unchanged objects are identical. The values of
+secrets are replaced with their sizes unless --show-secrets is set, and the snapshot
+is only readable by the current user.`,
+		Example: `# Save the deployments, services and config maps of the app namespace
+kubectl multi snapshot --resources deploy,svc,cm -n app -o snapshots/today`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
+			return handleSnapshotCommand(resources, dir, showSecrets, kubeconfig, remoteCtx, namespace, allNamespaces)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&resources, "resources", nil, "comma separated resource types to save, e.g. deploy,svc,cm")
+	cmd.Flags().StringVarP(&dir, "output-dir", "o", "", "directory to save the snapshot to; it must not exist or be empty")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "save the values of secrets instead of their sizes")
+	cmd.MarkFlagRequired("resources")
+	cmd.MarkFlagRequired("output-dir")
+	cmd.AddCommand(newSnapshotDiffCommand())
+
+	return cmd
+}
+
+func handleSnapshotCommand(resources []string, dir string, showSecrets bool, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("directory %s is not empty", dir)
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	manifest := snapshotManifest{
+		Time:          time.Now().UTC(),
+		Resources:     resources,
+		Namespace:     namespace,
+		AllNamespaces: allNamespaces,
+		ShowSecrets:   showSecrets,
+	}
+	count, err := takeSnapshot(clusters, manifest, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved %d objects of %d clusters to %s\n", count, len(clusters), dir)
+	return nil
+}
+
+// takeSnapshot saves the objects selected by manifest from every cluster to dir and
+// returns the number of saved objects. Secrets are redacted unless the manifest says
+// otherwise, and the files are only readable by the current user.
+func takeSnapshot(clusters []cluster.ClusterInfo, manifest snapshotManifest, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return 0, fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+	if err := writeYAML(filepath.Join(dir, snapshotManifestFile), manifest); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, resourceType := range manifest.Resources {
+		resolver := util.NewGVRResolver(resourceType)
+		for _, clusterInfo := range clusters {
+			if clusterInfo.DynamicClient() == nil {
+				continue
+			}
+
+			gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+			if err != nil {
+				warnClusterFailure(clusterInfo.Name, err, "discover resource type %s", resourceType)
+				continue
+			}
+
+			var list *unstructured.UnstructuredList
+			ctx, cancel := clusterInfo.RequestContext()
+			if isNamespaced && !manifest.AllNamespaces {
+				list, err = clusterInfo.DynamicClient().Resource(gvr).Namespace(clusterInfo.TargetNamespace(manifest.Namespace)).List(ctx, metav1.ListOptions{})
+			} else {
+				list, err = clusterInfo.DynamicClient().Resource(gvr).List(ctx, metav1.ListOptions{})
+			}
+			cancel()
+			if err != nil {
+				warnClusterFailure(clusterInfo.Name, err, "list %s", resourceType)
+				continue
+			}
+
+			for i := range list.Items {
+				obj := &list.Items[i]
+				normalizeObject(obj)
+				if !manifest.ShowSecrets {
+					multicluster.RedactSecret(obj)
+				}
+				path := filepath.Join(dir, clusterInfo.Name, snapshotObjectPath(gvr, obj))
+				if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+					return count, fmt.Errorf("failed to create snapshot directory: %v", err)
+				}
+				if err := writeYAML(path, obj.Object); err != nil {
+					return count, err
+				}
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// snapshotObjectPath returns the path of an object below its cluster directory
+func snapshotObjectPath(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) string {
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = clusterScopedDir
+	}
+	resource := gvr.Resource
+	if gvr.Group != "" {
+		resource += "." + gvr.Group
+	}
+	return filepath.Join(namespace, resource, obj.GetName()+".yaml")
+}
+
+// normalizeObject removes the status and the fields the API server sets, which
+// change without a change by the user
+func normalizeObject(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+
+	annotations := obj.GetAnnotations()
+	for _, key := range serverAnnotations {
+		delete(annotations, key)
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+}
+
+func writeYAML(path string, value interface{}) error {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+func newSnapshotDiffCommand() *cobra.Command {
+	var live bool
+
+	cmd := &cobra.Command{
+		Use:   "diff DIR [DIR]",
+		Short: "Report the objects that changed between two snapshots, or since a snapshot",
+		Long: `Compare two snapshot directories, or with --live a snapshot and the current state of
+the clusters, and report per cluster the objects that were added, removed or changed
+along with the changed fields.`,
+		Example: `# What changed in the fleet between two snapshots
+kubectl multi snapshot diff snapshots/yesterday snapshots/today
+
+# What changed since yesterday's snapshot
+kubectl multi snapshot diff snapshots/yesterday --live`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if live == (len(args) == 2) {
+				return fmt.Errorf("expected two snapshot directories, or one with --live")
+			}
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleSnapshotDiffCommand(args, live, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().BoolVar(&live, "live", false, "compare the snapshot with the current state of the clusters")
+
+	return cmd
+}
+
+func handleSnapshotDiffCommand(dirs []string, live bool, kubeconfig, remoteCtx string) error {
+	if live {
+		data, err := os.ReadFile(filepath.Join(dirs[0], snapshotManifestFile))
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot: %v", err)
+		}
+		var manifest snapshotManifest
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", snapshotManifestFile, err)
+		}
+
+		clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+		if err != nil {
+			return fmt.Errorf("failed to discover clusters: %v", err)
+		}
+		if len(clusters) == 0 {
+			return fmt.Errorf("no clusters discovered")
+		}
+
+		liveDir, err := os.MkdirTemp("", "kubectl-multi-snapshot-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %v", err)
+		}
+		defer os.RemoveAll(liveDir)
+		manifest.Time = time.Now().UTC()
+		if _, err := takeSnapshot(clusters, manifest, liveDir); err != nil {
+			return err
+		}
+		dirs = append(dirs, liveDir)
+	}
+
+	before, err := readSnapshot(dirs[0])
+	if err != nil {
+		return err
+	}
+	after, err := readSnapshot(dirs[1])
+	if err != nil {
+		return err
+	}
+	printSnapshotDiff(before, after)
+	return nil
+}
+
+// readSnapshot reads the objects of a snapshot keyed by their path below the snapshot
+// directory, CLUSTER/NAMESPACE/RESOURCE/NAME.yaml
+func readSnapshot(dir string) (map[string]map[string]interface{}, error) {
+	objects := make(map[string]map[string]interface{})
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if entry.IsDir() || len(strings.Split(filepath.ToSlash(rel), "/")) != 4 || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+		objects[filepath.ToSlash(rel)] = obj
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %v", dir, err)
+	}
+	return objects, nil
+}
+
+// printSnapshotDiff prints the added (+), removed (-) and changed (~) objects of every
+// cluster, with the changed fields of the changed objects
+func printSnapshotDiff(before, after map[string]map[string]interface{}) {
+	paths := make(map[string]bool)
+	for path := range before {
+		paths[path] = true
+	}
+	for path := range after {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	added, removed, changed := 0, 0, 0
+	currentCluster := ""
+	for _, path := range sorted {
+		parts := strings.Split(path, "/")
+		clusterName, namespace, resource, name := parts[0], parts[1], parts[2], strings.TrimSuffix(parts[3], ".yaml")
+		if namespace != clusterScopedDir {
+			name = namespace + "/" + name
+		}
+
+		oldObj, inBefore := before[path]
+		newObj, inAfter := after[path]
+		var marker string
+		var fields []fieldChange
+		switch {
+		case !inBefore:
+			marker = "+"
+			added++
+		case !inAfter:
+			marker = "-"
+			removed++
+		default:
+			if fields = diffObjects(oldObj, newObj); len(fields) == 0 {
+				continue
+			}
+			marker = "~"
+			changed++
+		}
+
+		if clusterName != currentCluster {
+			if currentCluster != "" {
+				fmt.Println()
+			}
+			printClusterHeader(clusterName)
+			currentCluster = clusterName
+		}
+		fmt.Printf("%s %s %s\n", marker, resource, name)
+		for _, field := range fields {
+			fmt.Printf("    %s: %s -> %s\n", field.Path, field.Old, field.New)
+		}
+	}
+
+	if added+removed+changed == 0 {
+		fmt.Println("No changes.")
+		return
+	}
+	fmt.Printf("\n%d added, %d removed, %d changed\n", added, removed, changed)
+}
+
+// fieldChange is a changed field of an object, with its values encoded as JSON
+type fieldChange struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// diffObjects returns the changed fields of two versions of an object, sorted by path.
+// The values of secrets are replaced with their sizes, so a diff does not leak them.
+func diffObjects(oldObj, newObj map[string]interface{}) []fieldChange {
+	oldFields := make(map[string]string)
+	newFields := make(map[string]string)
+	flattenFields("", oldObj, oldFields)
+	flattenFields("", newObj, newFields)
+
+	var changes []fieldChange
+	for path, oldValue := range oldFields {
+		newValue, ok := newFields[path]
+		if !ok {
+			newValue = "<none>"
+		}
+		if oldValue != newValue {
+			changes = append(changes, fieldChange{Path: path, Old: oldValue, New: newValue})
+		}
+	}
+	for path, newValue := range newFields {
+		if _, ok := oldFields[path]; !ok {
+			changes = append(changes, fieldChange{Path: path, Old: "<none>", New: newValue})
+		}
+	}
+	if oldObj["kind"] == "Secret" || newObj["kind"] == "Secret" {
+		for i := range changes {
+			if isSecretValuePath(changes[i].Path) {
+				changes[i].Old = redactedSize(changes[i].Path, changes[i].Old)
+				changes[i].New = redactedSize(changes[i].Path, changes[i].New)
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// isSecretValuePath tells whether a field path of a secret holds a value, either in
+// data or stringData or in the last-applied annotation that holds them as well
+func isSecretValuePath(path string) bool {
+	return strings.HasPrefix(path, "data.") || strings.HasPrefix(path, "stringData.") ||
+		path == "metadata.annotations.kubectl.kubernetes.io/last-applied-configuration"
+}
+
+// redactedSize replaces a JSON encoded secret value with its size
+func redactedSize(path, value string) string {
+	if value == "<none>" {
+		return value
+	}
+	var decoded string
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return "<redacted>"
+	}
+	size := len(decoded)
+	if strings.HasPrefix(path, "data.") {
+		if raw, err := base64.StdEncoding.DecodeString(decoded); err == nil {
+			size = len(raw)
+		}
+	}
+	return fmt.Sprintf("<redacted: %d bytes>", size)
+}
+
+// flattenFields adds the leaf values of value to fields, keyed by their path such as
+// spec.template.spec.containers[0].image. Empty maps and lists are leaves.
+func flattenFields(path string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			for key, child := range v {
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				flattenFields(childPath, child, fields)
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, child := range v {
+				flattenFields(fmt.Sprintf("%s[%d]", path, i), child, fields)
+			}
+			return
+		}
+	}
+	data, _ := json.Marshal(value)
+	fields[path] = string(data)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDiffObjects ensures changed, added and removed fields are reported by path
+func TestDiffObjects(t *testing.T) {
+	oldObj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.24"}},
+			}},
+			"paused": true,
+		},
+	}
+	newObj := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"tier": "web"}},
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.25"}},
+			}},
+		},
+	}
+
+	want := []fieldChange{
+		{Path: "metadata.labels.tier", Old: "<none>", New: `"web"`},
+		{Path: "spec.paused", Old: "true", New: "<none>"},
+		{Path: "spec.template.spec.containers[0].image", Old: `"nginx:1.24"`, New: `"nginx:1.25"`},
+	}
+	if got := diffObjects(oldObj, newObj); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffObjects() = %v, want %v", got, want)
+	}
+	if got := diffObjects(oldObj, oldObj); len(got) != 0 {
+		t.Errorf("diffObjects() of equal objects = %v, want none", got)
+	}
+}
+
+// TestDiffObjectsSecret ensures the changed values of a secret are reported by size
+func TestDiffObjectsSecret(t *testing.T) {
+	oldObj := map[string]interface{}{
+		"kind":       "Secret",
+		"data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+		"stringData": map[string]interface{}{"token": "abc"},
+	}
+	newObj := map[string]interface{}{
+		"kind":       "Secret",
+		"data":       map[string]interface{}{"password": "czNjcjN0LXBhc3M="},
+		"stringData": map[string]interface{}{"token": "xyz", "user": "admin"},
+	}
+
+	want := []fieldChange{
+		{Path: "data.password", Old: "<redacted: 7 bytes>", New: "<redacted: 11 bytes>"},
+		{Path: "stringData.token", Old: "<redacted: 3 bytes>", New: "<redacted: 3 bytes>"},
+		{Path: "stringData.user", Old: "<none>", New: "<redacted: 5 bytes>"},
+	}
+	if got := diffObjects(oldObj, newObj); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffObjects() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// clusterSummary holds the resource counts of one cluster
+type clusterSummary struct {
+	Name            string
+	Namespaces      int
+	PodPhases       map[corev1.PodPhase]int
+	Deployments     int
+	Services        int
+	PVCs            int
+	CustomResources int
+}
+
+func newSummaryCommand() *cobra.Command {
+	var namespaces []string
+
+	cmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Print resource counts for every managed cluster",
+		Long: `Print, for every managed cluster, the number of namespaces, pods by phase,
+deployments, services, persistent volume claims and custom resources.
+
+All namespaces are counted unless --namespaces (or -n) limits the summary to
+selected namespaces. Cluster-scoped custom resources are always counted.`,
+		Example: `# Summarize all clusters
+kubectl multi summary
+
+# Only count resources in the shop and payments namespaces
+kubectl multi summary --namespaces shop,payments`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			if len(namespaces) == 0 && namespace != "" {
+				namespaces = []string{namespace}
+			}
+			return handleSummaryCommand(namespaces, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&namespaces, "namespaces", nil, "comma separated list of namespaces to include (default all)")
+
+	return cmd
+}
+
+func handleSummaryCommand(namespaces []string, kubeconfig, remoteCtx string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	// An empty namespace lists across all namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "CLUSTER\tNAMESPACES\tPODS\tRUNNING\tPENDING\tSUCCEEDED\tFAILED\tDEPLOYMENTS\tSERVICES\tPVCS\tCUSTOM RESOURCES\n")
+
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		summary, err := getClusterSummary(clusterInfo, namespaces)
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "summarize resources")
+			continue
+		}
+
+		pods := 0
+		for _, count := range summary.PodPhases {
+			pods += count
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			summary.Name, summary.Namespaces, pods,
+			summary.PodPhases[corev1.PodRunning], summary.PodPhases[corev1.PodPending],
+			summary.PodPhases[corev1.PodSucceeded], summary.PodPhases[corev1.PodFailed],
+			summary.Deployments, summary.Services, summary.PVCs, summary.CustomResources)
+	}
+
+	return nil
+}
+
+// getClusterSummary counts the resources of a cluster in the given namespaces
+func getClusterSummary(clusterInfo cluster.ClusterInfo, namespaces []string) (clusterSummary, error) {
+	summary := clusterSummary{Name: clusterInfo.Name, PodPhases: make(map[corev1.PodPhase]int)}
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	if namespaces[0] == "" {
+		nsList, err := clusterInfo.Client().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return summary, fmt.Errorf("failed to list namespaces: %v", err)
+		}
+		summary.Namespaces = len(nsList.Items)
+	} else {
+		summary.Namespaces = len(namespaces)
+	}
+
+	for _, ns := range namespaces {
+		pods, err := clusterInfo.Client().CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return summary, fmt.Errorf("failed to list pods: %v", err)
+		}
+		for _, pod := range pods.Items {
+			summary.PodPhases[pod.Status.Phase]++
+		}
+
+		deployments, err := clusterInfo.Client().AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return summary, fmt.Errorf("failed to list deployments: %v", err)
+		}
+		summary.Deployments += len(deployments.Items)
+
+		services, err := clusterInfo.Client().CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return summary, fmt.Errorf("failed to list services: %v", err)
+		}
+		summary.Services += len(services.Items)
+
+		pvcs, err := clusterInfo.Client().CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return summary, fmt.Errorf("failed to list persistent volume claims: %v", err)
+		}
+		summary.PVCs += len(pvcs.Items)
+	}
+
+	summary.CustomResources = countCustomResources(ctx, clusterInfo, namespaces)
+	return summary, nil
+}
+
+// countCustomResources counts the objects of every CRD installed in the cluster,
+// listing each through its storage version
+func countCustomResources(ctx context.Context, clusterInfo cluster.ClusterInfo, namespaces []string) int {
+	if clusterInfo.DynamicClient() == nil {
+		return 0
+	}
+
+	crds, err := clusterInfo.DynamicClient().Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		warnClusterFailure(clusterInfo.Name, err, "list custom resource definitions")
+		return 0
+	}
+
+	total := 0
+	for _, crd := range crds.Items {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+		scope, _, _ := unstructured.NestedString(crd.Object, "spec", "scope")
+
+		version := ""
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		for _, v := range versions {
+			if entry, ok := v.(map[string]interface{}); ok && entry["storage"] == true {
+				version, _ = entry["name"].(string)
+			}
+		}
+		if version == "" {
+			continue
+		}
+
+		gvr := schema.GroupVersionResource{Group: group, Version: version, Resource: plural}
+		if scope != "Namespaced" {
+			list, err := clusterInfo.DynamicClient().Resource(gvr).List(ctx, metav1.ListOptions{})
+			if err == nil {
+				total += len(list.Items)
+			}
+			continue
+		}
+		for _, ns := range namespaces {
+			list, err := clusterInfo.DynamicClient().Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
+			if err == nil {
+				total += len(list.Items)
+			}
+		}
+	}
+	return total
+}
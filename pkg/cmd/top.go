@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+var (
+	nodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+	podMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+)
+
+// topRow holds the usage of a single node or pod in one cluster
+type topRow struct {
+	Cluster   string
+	Namespace string
+	Name      string
+	CPU       resource.Quantity
+	Memory    resource.Quantity
+	// Allocatable values are only populated for nodes
+	AllocCPU    resource.Quantity
+	AllocMemory resource.Quantity
+}
+
+func newTopCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top [TYPE]",
+		Short: "Display resource (CPU/memory) usage across managed clusters",
+		Long: `Display resource (CPU/memory) usage of nodes or pods across all managed clusters.
+Usage is read from the metrics.k8s.io API of every cluster, so metrics-server
+must be installed on each cluster that should be reported.`,
+		Example: `# Show node usage across all clusters
+kubectl multi top nodes
+
+# Show pod usage in all namespaces, highest memory first
+kubectl multi top pods -A --sort-by memory`,
+	}
+	cmd.AddCommand(newTopNodesCommand())
+	cmd.AddCommand(newTopPodsCommand())
+	return cmd
+}
+
+func newTopNodesCommand() *cobra.Command {
+	var selector string
+	var sortBy string
+
+	cmd := &cobra.Command{
+		Use:     "nodes [NAME]",
+		Aliases: []string{"node", "no"},
+		Short:   "Display resource (CPU/memory) usage of nodes across managed clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceName := ""
+			if len(args) > 0 {
+				resourceName = args[0]
+			}
+			kubeconfig, remoteCtx, _, _, _ := GetGlobalFlags()
+			return handleTopNodesCommand(resourceName, selector, sortBy, kubeconfig, remoteCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "selector (label query) to filter on")
+	cmd.Flags().StringVar(&sortBy, "sort-by", "", "sort the merged rows of all clusters by cpu or memory")
+
+	return cmd
+}
+
+func newTopPodsCommand() *cobra.Command {
+	var selector string
+	var sortBy string
+
+	cmd := &cobra.Command{
+		Use:     "pods [NAME]",
+		Aliases: []string{"pod", "po"},
+		Short:   "Display resource (CPU/memory) usage of pods across managed clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceName := ""
+			if len(args) > 0 {
+				resourceName = args[0]
+			}
+			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
+			return handleTopPodsCommand(resourceName, selector, sortBy, kubeconfig, remoteCtx, namespace, allNamespaces)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "selector (label query) to filter on")
+	cmd.Flags().StringVar(&sortBy, "sort-by", "", "sort the merged rows of all clusters by cpu or memory")
+
+	return cmd
+}
+
+func handleTopNodesCommand(resourceName, selector, sortBy, kubeconfig, remoteCtx string) error {
+	if err := validateTopSortBy(sortBy); err != nil {
+		return err
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	var rows []topRow
+	var clusterOrder []string
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil || clusterInfo.DynamicClient() == nil {
+			continue
+		}
+
+		clusterRows, err := listNodeUsage(clusterInfo, resourceName, selector)
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "get node metrics")
+			continue
+		}
+		clusterOrder = append(clusterOrder, clusterInfo.Name)
+		rows = append(rows, clusterRows...)
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No resources found")
+		return nil
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "CLUSTER\tNAME\tCPU(cores)\tCPU%%\tMEMORY(bytes)\tMEMORY%%\n")
+	printNodeRow := func(r topRow) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Cluster, r.Name, formatCPU(r.CPU), formatPercent(r.CPU, r.AllocCPU),
+			formatMemory(r.Memory), formatPercent(r.Memory, r.AllocMemory))
+	}
+
+	printTopRows(rows, clusterOrder, sortBy, printNodeRow, true)
+
+	return nil
+}
+
+func handleTopPodsCommand(resourceName, selector, sortBy, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	if err := validateTopSortBy(sortBy); err != nil {
+		return err
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	targetNS := cluster.GetTargetNamespace(namespace)
+	if allNamespaces {
+		targetNS = ""
+	}
+
+	var rows []topRow
+	var clusterOrder []string
+	for _, clusterInfo := range clusters {
+		if clusterInfo.DynamicClient() == nil {
+			continue
+		}
+
+		clusterNS := targetNS
+		if !allNamespaces {
+			clusterNS = clusterInfo.TargetNamespace(namespace)
+		}
+		clusterRows, err := listPodUsage(clusterInfo, resourceName, selector, clusterNS)
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "get pod metrics")
+			continue
+		}
+		clusterOrder = append(clusterOrder, clusterInfo.Name)
+		rows = append(rows, clusterRows...)
+	}
+
+	if len(rows) == 0 {
+		if allNamespaces {
+			fmt.Println("No resource found.")
+		} else {
+			fmt.Printf("No resource found in %s namespace.\n", targetNS)
+		}
+		return nil
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	defer tw.Flush()
+
+	var printPodRow func(r topRow)
+	if allNamespaces {
+		fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tNAME\tCPU(cores)\tMEMORY(bytes)\n")
+		printPodRow = func(r topRow) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Cluster, r.Namespace, r.Name, formatCPU(r.CPU), formatMemory(r.Memory))
+		}
+	} else {
+		fmt.Fprintf(tw, "CLUSTER\tNAME\tCPU(cores)\tMEMORY(bytes)\n")
+		printPodRow = func(r topRow) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Cluster, r.Name, formatCPU(r.CPU), formatMemory(r.Memory))
+		}
+	}
+
+	printTopRows(rows, clusterOrder, sortBy, printPodRow, false)
+
+	return nil
+}
+
+// printTopRows prints the usage rows followed by a total row per cluster and a fleet-wide total.
+// Without --sort-by the cluster total directly follows that cluster's rows; with --sort-by the
+// merged rows are printed first and all totals are grouped at the end.
+func printTopRows(rows []topRow, clusterOrder []string, sortBy string, printRow func(topRow), withAllocatable bool) {
+	totals := make(map[string]*topRow)
+	fleet := &topRow{Cluster: "<fleet>", Name: "<total>"}
+	for _, r := range rows {
+		t, ok := totals[r.Cluster]
+		if !ok {
+			t = &topRow{Cluster: r.Cluster, Name: "<total>"}
+			totals[r.Cluster] = t
+		}
+		addTopUsage(t, r, withAllocatable)
+		addTopUsage(fleet, r, withAllocatable)
+	}
+
+	if sortBy != "" {
+		sortTopRows(rows, sortBy)
+		for _, r := range rows {
+			printRow(r)
+		}
+		for _, name := range clusterOrder {
+			if t, ok := totals[name]; ok {
+				printRow(*t)
+			}
+		}
+	} else {
+		for _, name := range clusterOrder {
+			for _, r := range rows {
+				if r.Cluster == name {
+					printRow(r)
+				}
+			}
+			if t, ok := totals[name]; ok {
+				printRow(*t)
+			}
+		}
+	}
+
+	printRow(*fleet)
+}
+
+func addTopUsage(total *topRow, r topRow, withAllocatable bool) {
+	total.CPU.Add(r.CPU)
+	total.Memory.Add(r.Memory)
+	if withAllocatable {
+		total.AllocCPU.Add(r.AllocCPU)
+		total.AllocMemory.Add(r.AllocMemory)
+	}
+}
+
+// listNodeUsage reads node metrics and node allocatable resources from a single cluster
+func listNodeUsage(clusterInfo cluster.ClusterInfo, resourceName, selector string) ([]topRow, error) {
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	metrics, err := clusterInfo.DynamicClient().Resource(nodeMetricsGVR).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("metrics API not available: %v", err)
+	}
+
+	nodes, err := clusterInfo.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	allocatable := make(map[string]corev1.ResourceList)
+	for _, node := range nodes.Items {
+		allocatable[node.Name] = node.Status.Allocatable
+	}
+
+	var rows []topRow
+	for _, item := range metrics.Items {
+		if resourceName != "" && item.GetName() != resourceName {
+			continue
+		}
+		usage, _, _ := unstructured.NestedStringMap(item.Object, "usage")
+		row := topRow{
+			Cluster: clusterInfo.Name,
+			Name:    item.GetName(),
+			CPU:     parseQuantity(usage["cpu"]),
+			Memory:  parseQuantity(usage["memory"]),
+		}
+		if alloc, ok := allocatable[item.GetName()]; ok {
+			row.AllocCPU = alloc[corev1.ResourceCPU]
+			row.AllocMemory = alloc[corev1.ResourceMemory]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// listPodUsage reads pod metrics from a single cluster, summing the usage of all containers
+func listPodUsage(clusterInfo cluster.ClusterInfo, resourceName, selector, targetNS string) ([]topRow, error) {
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	metrics, err := clusterInfo.DynamicClient().Resource(podMetricsGVR).Namespace(targetNS).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("metrics API not available: %v", err)
+	}
+
+	var rows []topRow
+	for _, item := range metrics.Items {
+		if resourceName != "" && item.GetName() != resourceName {
+			continue
+		}
+		row := topRow{
+			Cluster:   clusterInfo.Name,
+			Namespace: item.GetNamespace(),
+			Name:      item.GetName(),
+		}
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usage, _, _ := unstructured.NestedStringMap(container, "usage")
+			row.CPU.Add(parseQuantity(usage["cpu"]))
+			row.Memory.Add(parseQuantity(usage["memory"]))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func validateTopSortBy(sortBy string) error {
+	switch sortBy {
+	case "", "cpu", "memory":
+		return nil
+	default:
+		return fmt.Errorf("--sort-by must be one of cpu or memory, got %q", sortBy)
+	}
+}
+
+// sortTopRows sorts rows in descending order of the requested resource
+func sortTopRows(rows []topRow, sortBy string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if sortBy == "memory" {
+			return rows[i].Memory.Cmp(rows[j].Memory) > 0
+		}
+		return rows[i].CPU.Cmp(rows[j].CPU) > 0
+	})
+}
+
+func parseQuantity(value string) resource.Quantity {
+	if strings.TrimSpace(value) == "" {
+		return resource.Quantity{}
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return resource.Quantity{}
+	}
+	return q
+}
+
+// formatCPU renders a CPU quantity in millicores like kubectl top
+func formatCPU(q resource.Quantity) string {
+	return fmt.Sprintf("%dm", q.MilliValue())
+}
+
+// formatMemory renders a memory quantity in mebibytes like kubectl top
+func formatMemory(q resource.Quantity) string {
+	return fmt.Sprintf("%dMi", q.Value()/(1024*1024))
+}
+
+func formatPercent(used, total resource.Quantity) string {
+	if total.IsZero() {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("%d%%", used.MilliValue()*100/total.MilliValue())
+}
@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/multicluster"
+	"kubectl-multi/pkg/util"
+)
+
+// uiRow is one resource shown in the ui
+type uiRow struct {
+	Cluster cluster.ClusterInfo
+	Object  unstructured.Unstructured
+}
+
+// uiClusterStatus is the state of one cluster in the clusters pane
+type uiClusterStatus struct {
+	Name    string
+	Objects int
+	Err     error
+}
+
+// uiState is everything the ui renders
+type uiState struct {
+	resourceType string
+	namespace    string
+	allNS        bool
+	filter       string
+	clusters     []cluster.ClusterInfo
+	server       *multicluster.Server
+	statuses     []uiClusterStatus
+	rows         []uiRow
+	refreshedAt  time.Time
+	message      string
+	// terminal edits the input line when stdin and stdout are a terminal, so a refresh
+	// redraws the line being typed instead of wiping it
+	terminal *term.Terminal
+}
+
+// uiPrompt is shown where the commands are typed
+const uiPrompt = "> "
+
+func newUICommand() *cobra.Command {
+	var refresh time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "ui [TYPE]",
+		Short: "Browse resources of all managed clusters interactively",
+		Long: `Start an interactive terminal view with the managed clusters in one pane and
+the resources of the selected type in another. The view refreshes periodically
+and accepts commands to switch the resource type, filter rows, and describe, show
+logs of or delete a row.
+
+Commands:
+  type TYPE     show another resource type, e.g. type deployments
+  filter TEXT   only show rows whose cluster, namespace or name contains TEXT
+  describe N    describe the object in row N
+  logs N        show the last 100 log lines of the pod in row N
+  delete N      delete the object in row N after confirmation
+  refresh       reload now
+  quit          leave the ui`,
+		Example: `# Browse pods of all clusters, refreshing every 5 seconds
+kubectl multi ui
+
+# Browse deployments in all namespaces
+kubectl multi ui deployments -A --refresh 10s`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceType := "pods"
+			if len(args) == 1 {
+				resourceType = args[0]
+			}
+			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
+			return handleUICommand(resourceType, refresh, kubeconfig, remoteCtx, namespace, allNamespaces)
+		},
+	}
+
+	cmd.Flags().DurationVar(&refresh, "refresh", 5*time.Second, "how often the view is reloaded")
+
+	return cmd
+}
+
+func handleUICommand(resourceType string, refresh time.Duration, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	server, client, err := newCachedServer(kubeconfig, remoteCtx)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	// Failures are shown in the clusters pane; printing warnings would garble the view
+	quiet = true
+	cluster.SetProgress(false)
+
+	state := &uiState{
+		resourceType: resourceType,
+		namespace:    namespace,
+		allNS:        allNamespaces,
+		clusters:     client.Clusters(),
+		server:       server,
+	}
+
+	out := util.GetOutputStream()
+	stdin := int(os.Stdin.Fd())
+	if term.IsTerminal(stdin) && term.IsTerminal(int(os.Stdout.Fd())) {
+		oldState, err := term.MakeRaw(stdin)
+		if err != nil {
+			return fmt.Errorf("failed to set up the terminal: %v", err)
+		}
+		defer term.Restore(stdin, oldState)
+		state.terminal = term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, out}, uiPrompt)
+		out = state.terminal
+	}
+	input := state.readInput()
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	state.load()
+	for {
+		state.render(out)
+
+		select {
+		case <-ticker.C:
+			state.load()
+		case line, ok := <-input:
+			if !ok || line == "quit" || line == "q" {
+				return nil
+			}
+			state.message = ""
+			if state.handle(line, input, out, kubeconfig) {
+				state.load()
+			}
+		}
+	}
+}
+
+// readInput sends every line typed by the user until stdin is closed. On a terminal,
+// Ctrl-C and Ctrl-D on an empty line close the input as well.
+func (s *uiState) readInput() <-chan string {
+	input := make(chan string)
+	go func() {
+		defer close(input)
+		if s.terminal != nil {
+			for {
+				line, err := s.terminal.ReadLine()
+				if err != nil {
+					return
+				}
+				input <- strings.TrimSpace(line)
+			}
+		}
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			input <- strings.TrimSpace(scanner.Text())
+		}
+	}()
+	return input
+}
+
+// prompt asks for the next line. The terminal draws the prompt itself below anything
+// written to it.
+func (s *uiState) prompt(out io.Writer) {
+	if s.terminal == nil {
+		fmt.Fprint(out, uiPrompt)
+	}
+}
+
+// load reads the resources of the current type in every cluster from the informer
+// caches, so a refresh does not list the clusters again
+func (s *uiState) load() {
+	s.statuses = nil
+	s.rows = nil
+
+	objects, failures := s.server.List(s.resourceType, nil, multicluster.ServerOptions{Namespace: s.namespace, AllNamespaces: s.allNS})
+	failed := make(map[string]error, len(failures))
+	for _, failure := range failures {
+		failed[failure.Cluster] = failure.Err
+	}
+	counts := make(map[string]int)
+	byName := make(map[string]cluster.ClusterInfo, len(s.clusters))
+	for _, clusterInfo := range s.clusters {
+		byName[clusterInfo.Name] = clusterInfo
+	}
+	for _, obj := range objects {
+		counts[obj.Cluster]++
+		s.rows = append(s.rows, uiRow{Cluster: byName[obj.Cluster], Object: obj.Unstructured})
+	}
+	for _, clusterInfo := range s.clusters {
+		s.statuses = append(s.statuses, uiClusterStatus{Name: clusterInfo.Name, Objects: counts[clusterInfo.Name], Err: failed[clusterInfo.Name]})
+	}
+	s.refreshedAt = time.Now()
+}
+
+// visibleRows returns the rows matching the filter
+func (s *uiState) visibleRows() []uiRow {
+	if s.filter == "" {
+		return s.rows
+	}
+	var rows []uiRow
+	for _, row := range s.rows {
+		text := row.Cluster.Name + "/" + row.Object.GetNamespace() + "/" + row.Object.GetName()
+		if strings.Contains(text, s.filter) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// render redraws the whole screen. The view is written at once so the terminal redraws
+// the input line a single time.
+func (s *uiState) render(out io.Writer) {
+	view := &bytes.Buffer{}
+	s.draw(view)
+	out.Write(view.Bytes())
+	s.prompt(out)
+}
+
+// draw writes the view
+func (s *uiState) draw(out io.Writer) {
+	fmt.Fprint(out, "\033[H\033[2J")
+	scope := "namespace " + s.namespace
+	if s.allNS {
+		scope = "all namespaces"
+	} else if s.namespace == "" {
+		scope = "the default namespace of each cluster"
+	}
+	fmt.Fprintf(out, "kubectl multi ui - %s in %s - refreshed %s\n\n", s.resourceType, scope, s.refreshedAt.Format("15:04:05"))
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "CLUSTER\tSTATUS\tOBJECTS\n")
+	for _, status := range s.statuses {
+		if status.Err != nil {
+			fmt.Fprintf(tw, "%s\tError: %v\t-\n", status.Name, status.Err)
+		} else {
+			fmt.Fprintf(tw, "%s\tOK\t%d\n", status.Name, status.Objects)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(out)
+
+	rows := s.visibleRows()
+	if len(rows) == 0 {
+		fmt.Fprintln(out, "No resource found.")
+	} else {
+		fmt.Fprintf(tw, "#\tCLUSTER\tNAMESPACE\tNAME\tREADY\tAGE\n")
+		for i, row := range rows {
+			age := util.FormatAge(row.Object.GetCreationTimestamp().Time)
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", i+1, row.Cluster.Name, row.Object.GetNamespace(), row.Object.GetName(), objectReadiness(&row.Object), age)
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintln(out)
+	if s.filter != "" {
+		fmt.Fprintf(out, "filter: %s\n", s.filter)
+	}
+	if s.message != "" {
+		fmt.Fprintln(out, s.message)
+	}
+	fmt.Fprintln(out, "[type TYPE | filter TEXT | describe N | logs N | delete N | refresh | quit]")
+}
+
+// handle runs a command typed by the user and reports whether the view must be reloaded
+func (s *uiState) handle(line string, input <-chan string, out io.Writer, kubeconfig string) bool {
+	command, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case "":
+		return false
+	case "refresh", "r":
+		return true
+	case "type", "t":
+		if arg == "" {
+			s.message = "usage: type TYPE"
+			return false
+		}
+		s.resourceType = arg
+		s.filter = ""
+		return true
+	case "filter", "f", "/":
+		s.filter = arg
+		return false
+	case "describe", "logs", "delete":
+		row, err := s.row(arg)
+		if err != nil {
+			s.message = err.Error()
+			return false
+		}
+		return s.runAction(command, row, input, out, kubeconfig)
+	}
+
+	s.message = fmt.Sprintf("unknown command %q", command)
+	return false
+}
+
+// row returns the visible row with the given 1-based number
+func (s *uiState) row(arg string) (uiRow, error) {
+	rows := s.visibleRows()
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(rows) {
+		return uiRow{}, fmt.Errorf("expected a row number between 1 and %d", len(rows))
+	}
+	return rows[n-1], nil
+}
+
+// runAction describes, shows the logs of, or deletes the object of a row
+func (s *uiState) runAction(action string, row uiRow, input <-chan string, out io.Writer, kubeconfig string) bool {
+	obj := row.Object
+	fmt.Fprint(out, "\033[H\033[2J")
+
+	switch action {
+	case "describe":
+		output, err := describeObjects(row.Cluster, util.NewGVRResolver(s.resourceType), []string{obj.GetName()}, "", true, 500, 0, obj.GetNamespace(), false)
+		if err != nil {
+			output = fmt.Sprintf("Error describing %s in cluster %s: %v\n", obj.GetName(), row.Cluster.Name, err)
+		}
+		fmt.Fprint(out, output)
+
+	case "logs":
+		if obj.GetKind() != "Pod" {
+			s.message = "logs are only available for pods"
+			return false
+		}
+		args := buildLogsArgs(obj.GetName(), false, false, "", "", "", false, 100, 0, obj.GetNamespace(), false, row.Cluster.Context)
+		output, err := executeKubectlLogs(args, kubeconfig, row.Cluster.Name)
+		if err != nil {
+			output = fmt.Sprintf("Error getting logs for pod '%s' in cluster %s: %v\n", obj.GetName(), row.Cluster.Name, err)
+		}
+		fmt.Fprint(out, output)
+
+	case "delete":
+		if readOnlyMode() {
+			s.message = "delete is disabled in read-only mode"
+			return false
+		}
+		if mutationProtected(row.Cluster.Name) && !skipSafety {
+			s.message = fmt.Sprintf("cluster %s is protected from changes", row.Cluster.Name)
+			return false
+		}
+		fmt.Fprintf(out, "Delete %s %s/%s in cluster %s? [y/N]\n", obj.GetKind(), obj.GetNamespace(), obj.GetName(), row.Cluster.Name)
+		s.prompt(out)
+		if answer := <-input; answer != "y" && answer != "yes" {
+			s.message = "delete cancelled"
+			return false
+		}
+		s.message = s.deleteObject(row)
+		return true
+	}
+
+	fmt.Fprintln(out, "\n-- press Enter to return --")
+	s.prompt(out)
+	<-input
+	return false
+}
+
+// deleteObject deletes the object of a row and returns the outcome for the status line
+func (s *uiState) deleteObject(row uiRow) string {
+	obj := row.Object
+	gvr, isNamespaced, err := util.NewGVRResolver(s.resourceType).Resolve(row.Cluster.DiscoveryClient())
+	if err != nil {
+		return fmt.Sprintf("failed to discover resource type %s in cluster %s: %v", s.resourceType, row.Cluster.Name, err)
+	}
+
+	ctx, cancel := row.Cluster.RequestContext()
+	defer cancel()
+	if isNamespaced {
+		err = row.Cluster.DynamicClient().Resource(gvr).Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+	} else {
+		err = row.Cluster.DynamicClient().Resource(gvr).Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+	}
+	writeAudit(row.Cluster.Name, row.Cluster.Context, []string{strings.ToLower(obj.GetKind()) + "/" + obj.GetName()}, false, err)
+	if err != nil {
+		return fmt.Sprintf("failed to delete %s in cluster %s: %v", obj.GetName(), row.Cluster.Name, err)
+	}
+	return fmt.Sprintf("deleted %s %s in cluster %s", obj.GetKind(), obj.GetName(), row.Cluster.Name)
+}
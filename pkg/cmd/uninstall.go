@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/homedir"
+
+	"kubectl-multi/pkg/config"
+)
+
+func newUninstallCommand() *cobra.Command {
+	var purge bool
+	var includeDemo bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the plugin and optionally its data and demo clusters",
+		Long: `Remove the plugin binary, or uninstall it through krew when it was installed
+with krew. What will be removed is listed and confirmed first.
+
+--purge also removes ~/.kubectl-multi, with the config file and the demo state, and
+the discovery and http caches in the --cache-dir when it is not the cache shared with
+kubectl. --include-demo deletes
+the kind clusters and kubeconfig contexts created by install --demo.`,
+		Example: `# Remove the plugin
+kubectl multi uninstall
+
+# Remove everything, including the demo environment
+kubectl multi uninstall --purge --include-demo`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleUninstallCommand(purge, includeDemo, yes)
+		},
+	}
+
+	cmd.Flags().BoolVar(&purge, "purge", false, "also remove the config directory and the discovery cache of the plugin")
+	cmd.Flags().BoolVar(&includeDemo, "include-demo", false, "also delete the kind clusters and contexts created by install --demo")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	return cmd
+}
+
+func handleUninstallCommand(purge, includeDemo, yes bool) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot find the plugin binary: %v", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("cannot find the plugin binary: %v", err)
+	}
+	krew := strings.Contains(filepath.ToSlash(executable), "/.krew/")
+
+	var demoClusters, demoContexts []demoObject
+	if includeDemo {
+		if demoClusters, demoContexts, _, err = readDemoState(); err != nil {
+			return fmt.Errorf("failed to read the demo state: %v", err)
+		}
+		if len(demoClusters) > 0 {
+			if _, err := exec.LookPath("kind"); err != nil {
+				return fmt.Errorf("kind is required to delete the demo clusters and not in PATH")
+			}
+		}
+	}
+	var dirs []string
+	if purge {
+		dirs = append(dirs, filepath.Dir(config.DefaultPath()))
+		// The default cache is shared with kubectl and stays
+		if cacheDir != "" && filepath.Clean(cacheDir) != filepath.Join(homedir.HomeDir(), ".kube", "cache") {
+			cacheDirs, err := pluginCacheDirs(cacheDir)
+			if err != nil {
+				return err
+			}
+			dirs = append(dirs, cacheDirs...)
+		}
+	}
+
+	fmt.Println("This will remove:")
+	if krew {
+		fmt.Println("  the plugin, with kubectl krew uninstall multi")
+	} else {
+		fmt.Printf("  the plugin binary %s\n", executable)
+	}
+	for _, dir := range dirs {
+		fmt.Printf("  the directory %s\n", dir)
+	}
+	for _, demo := range demoClusters {
+		fmt.Printf("  the kind cluster %s\n", demo.name)
+	}
+	for _, demo := range demoContexts {
+		fmt.Printf("  the kubeconfig context %s%s\n", demo.name, inKubeconfig(demo.kubeconfig))
+	}
+	if includeDemo && len(demoClusters) == 0 && len(demoContexts) == 0 {
+		fmt.Println("  (no demo clusters were created by install --demo)")
+	}
+	if !yes {
+		confirmed, err := confirmYes()
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Uninstall cancelled")
+			return nil
+		}
+	}
+
+	// The demo goes first, as it needs the state file removed by --purge
+	failed := 0
+	// The clusters and contexts are removed from the kubeconfig they were created in,
+	// which may not be the one of this run
+	for _, demo := range demoClusters {
+		args := []string{"delete", "cluster", "--name", demo.name}
+		if demo.kubeconfig != "" {
+			args = append(args, "--kubeconfig", demo.kubeconfig)
+		}
+		if err := runUninstallStep("kind", args...); err != nil {
+			printWarning("failed to delete the kind cluster %s: %v", demo.name, err)
+			failed++
+		}
+	}
+	for _, demo := range demoContexts {
+		args := []string{"config", "delete-context", demo.name}
+		if demo.kubeconfig != "" {
+			args = append(args, "--kubeconfig", demo.kubeconfig)
+		}
+		// Contexts of deleted clusters may be gone already
+		if output, err := exec.Command("kubectl", args...).CombinedOutput(); err != nil &&
+			!strings.Contains(string(output), "not in") {
+			printWarning("failed to delete the context %s%s: %s", demo.name, inKubeconfig(demo.kubeconfig), strings.TrimSpace(string(output)))
+			failed++
+		}
+	}
+	if includeDemo && failed == 0 {
+		if err := os.Remove(demoStatePath()); err != nil && !os.IsNotExist(err) {
+			printWarning("failed to remove %s: %v", demoStatePath(), err)
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			printWarning("failed to remove %s: %v", dir, err)
+			failed++
+		}
+	}
+
+	if krew {
+		if err := runUninstallStep("kubectl", "krew", "uninstall", "multi"); err != nil {
+			return fmt.Errorf("krew uninstall failed: %v", err)
+		}
+	} else if err := removeBinary(executable); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", executable, err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("the plugin was removed but %d cleanup steps failed", failed)
+	}
+	fmt.Println("kubectl multi was uninstalled")
+	return nil
+}
+
+// inKubeconfig names a kubeconfig that is not the default one in messages
+func inKubeconfig(kubeconfig string) string {
+	if kubeconfig == "" {
+		return ""
+	}
+	return " in " + kubeconfig
+}
+
+// removeBinary removes the running binary. Windows cannot remove a running binary, so
+// it is renamed for the next upgrade or install to overwrite.
+func removeBinary(executable string) error {
+	if runtime.GOOS == "windows" {
+		old := executable + ".old"
+		os.Remove(old)
+		if err := os.Rename(executable, old); err != nil {
+			return err
+		}
+		fmt.Printf("Delete %s to finish the uninstall\n", old)
+		return nil
+	}
+	return os.Remove(executable)
+}
+
+func runUninstallStep(name string, args ...string) error {
+	fmt.Printf("Executing: %s %s\n", name, strings.Join(args, " "))
+	cmd := exec.Command(name, args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// pluginCacheDirs returns the directories the discovery cache creates in a --cache-dir.
+// A directory holding anything else is refused, as removing the caches could then
+// delete data the plugin does not own.
+func pluginCacheDirs(cacheDir string) ([]string, error) {
+	entries, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cache directory: %v", err)
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || (entry.Name() != "discovery" && entry.Name() != "http") {
+			return nil, fmt.Errorf("%s contains %s and does not look like a cache of the plugin; remove its discovery and http directories yourself", cacheDir, entry.Name())
+		}
+		dirs = append(dirs, filepath.Join(cacheDir, entry.Name()))
+	}
+	return dirs, nil
+}
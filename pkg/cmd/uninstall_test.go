@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestPluginCacheDirs ensures --purge only removes the caches of the plugin and
+// refuses directories holding anything else
+func TestPluginCacheDirs(t *testing.T) {
+	cacheDir := t.TempDir()
+	for _, name := range []string{"discovery", "http"} {
+		if err := os.Mkdir(filepath.Join(cacheDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirs, err := pluginCacheDirs(cacheDir)
+	if err != nil {
+		t.Fatalf("pluginCacheDirs() failed: %v", err)
+	}
+	want := []string{filepath.Join(cacheDir, "discovery"), filepath.Join(cacheDir, "http")}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("pluginCacheDirs() = %v, want %v", dirs, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(cacheDir, "notes.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pluginCacheDirs(cacheDir); err == nil {
+		t.Errorf("pluginCacheDirs() accepted a directory with other files")
+	}
+
+	if dirs, err := pluginCacheDirs(filepath.Join(cacheDir, "missing")); err != nil || dirs != nil {
+		t.Errorf("pluginCacheDirs() of a missing directory = %v, %v, want nothing", dirs, err)
+	}
+}
+
+// TestDemoStateKubeconfig ensures the demo state remembers the kubeconfig each demo
+// cluster and context was created in, so uninstall deletes them from it
+func TestDemoStateKubeconfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	kubeconfig := filepath.Join(t.TempDir(), "demo.kubeconfig")
+
+	steps := []struct {
+		kubeconfig string
+		kind, name string
+	}{
+		{"", "cluster", "kubeflex"},
+		{kubeconfig, "cluster", "cluster1"},
+		{kubeconfig, "context", "cluster1"},
+		{kubeconfig, "context", "cluster1"},
+		{"", "context", "its1"},
+	}
+	for _, step := range steps {
+		o := &InstallOptions{Kubeconfig: step.kubeconfig}
+		if err := o.recordDemo(step.kind, step.name); err != nil {
+			t.Fatalf("recordDemo(%s, %s) failed: %v", step.kind, step.name, err)
+		}
+	}
+
+	clusters, contexts, _, err := readDemoState()
+	if err != nil {
+		t.Fatalf("readDemoState() failed: %v", err)
+	}
+	wantClusters := []demoObject{{name: "kubeflex"}, {name: "cluster1", kubeconfig: kubeconfig}}
+	wantContexts := []demoObject{{name: "cluster1", kubeconfig: kubeconfig}, {name: "its1"}}
+	if !reflect.DeepEqual(clusters, wantClusters) {
+		t.Errorf("clusters = %v, want %v", clusters, wantClusters)
+	}
+	if !reflect.DeepEqual(contexts, wantContexts) {
+		t.Errorf("contexts = %v, want %v", contexts, wantContexts)
+	}
+}
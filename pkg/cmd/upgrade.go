@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"kubectl-multi/pkg/util"
+)
+
+const (
+	// releaseRepo is the GitHub repository the plugin is released from
+	releaseRepo = "kubestellar/kubectl-multi-plugin"
+	// binaryName is the binary in the release archives built by goreleaser
+	binaryName = "kubectl-multi"
+)
+
+// upgradeTimeout bounds each request to GitHub
+const upgradeTimeout = 2 * time.Minute
+
+// pluginRelease is the part of a GitHub release the upgrade reads
+type pluginRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func newUpgradeCommand() *cobra.Command {
+	var checkOnly bool
+	var targetVersion string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade the plugin to the latest release",
+		Long: `Check the latest release of the plugin, verify it works with the KubeStellar
+version installed in the hosting cluster of the current context, and replace the
+running binary with it. A plugin installed with krew is upgraded through krew.
+
+With --check-only nothing is replaced; the command fails when a newer release is
+available or the installed KubeStellar is not supported, so CI can gate on it.`,
+		Example: `# Upgrade to the latest release
+kubectl multi upgrade
+
+# Fail when the plugin is outdated
+kubectl multi upgrade --check-only
+
+# Install a specific release
+kubectl multi upgrade --version v0.3.0`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, _, _, _, _ := GetGlobalFlags()
+			return handleUpgradeCommand(targetVersion, checkOnly, force, kubeconfig)
+		},
+	}
+
+	cmd.Flags().BoolVar(&checkOnly, "check-only", false, "only report whether an upgrade is available, failing when it is")
+	cmd.Flags().StringVar(&targetVersion, "version", "", "the release to install, e.g. v0.3.0 (defaults to the latest)")
+	cmd.Flags().BoolVar(&force, "force", false, "upgrade even when the installed KubeStellar is not supported or the plugin is up to date")
+	return cmd
+}
+
+func handleUpgradeCommand(targetVersion string, checkOnly, force bool, kubeconfig string) error {
+	release, err := fetchRelease(targetVersion)
+	if err != nil {
+		return err
+	}
+	current := util.Version
+	fmt.Printf("Current version: %s\n", current)
+	fmt.Printf("Release:         %s\n", release.TagName)
+
+	upToDate := false
+	if currentVersion, err := version.ParseSemantic(current); err == nil {
+		if releaseVersion, err := version.ParseSemantic(release.TagName); err == nil {
+			upToDate = !currentVersion.LessThan(releaseVersion)
+		}
+	}
+
+	// The KubeStellar version is only checked when it can be found; the plugin also
+	// works with clusters that are not managed by KubeStellar
+	compatErr := error(nil)
+	if ksVersion, err := detectKubeStellarVersion(kubeconfig); err != nil {
+		printWarning("cannot detect the KubeStellar version: %v", err)
+	} else {
+		fmt.Printf("KubeStellar:     %s\n", ksVersion)
+		var warnings []string
+		warnings, compatErr = checkCompatibility(release.TagName, ksVersion)
+		for _, warning := range warnings {
+			printWarning("%s", warning)
+		}
+	}
+
+	if checkOnly {
+		if compatErr != nil {
+			return compatErr
+		}
+		if !upToDate {
+			return fmt.Errorf("%s is available, run kubectl multi upgrade", release.TagName)
+		}
+		fmt.Println("The plugin is up to date.")
+		return nil
+	}
+	if upToDate && !force {
+		fmt.Println("The plugin is up to date.")
+		return nil
+	}
+	if compatErr != nil && !force {
+		return fmt.Errorf("%v; use --force to upgrade anyway", compatErr)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot find the plugin binary: %v", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("cannot find the plugin binary: %v", err)
+	}
+	if strings.Contains(filepath.ToSlash(executable), "/.krew/") {
+		fmt.Println("The plugin was installed with krew, upgrading through krew...")
+		krew := exec.Command("kubectl", "krew", "upgrade", "multi")
+		krew.Stdout, krew.Stderr = os.Stdout, os.Stderr
+		return krew.Run()
+	}
+
+	binary, err := downloadRelease(release)
+	if err != nil {
+		return err
+	}
+	if err := replaceBinary(executable, binary); err != nil {
+		return err
+	}
+	fmt.Printf("Upgraded %s to %s\n", executable, release.TagName)
+	return nil
+}
+
+// fetchRelease returns the latest release, or the one tagged targetVersion
+func fetchRelease(targetVersion string) (*pluginRelease, error) {
+	url := "https://api.github.com/repos/" + releaseRepo + "/releases/latest"
+	if targetVersion != "" {
+		if !strings.HasPrefix(targetVersion, "v") {
+			targetVersion = "v" + targetVersion
+		}
+		url = "https://api.github.com/repos/" + releaseRepo + "/releases/tags/" + targetVersion
+	}
+	data, err := httpGet(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the release: %v", err)
+	}
+	var release pluginRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("failed to read the release: %v", err)
+	}
+	return &release, nil
+}
+
+// downloadRelease downloads the archive of the release for this platform, verifies it
+// against the checksums of the release and returns the binary in it
+func downloadRelease(release *pluginRelease) ([]byte, error) {
+	ext := ".tar.gz"
+	if runtime.GOOS == "windows" {
+		ext = ".zip"
+	}
+	archiveName := fmt.Sprintf("%s_%s_%s_%s%s", binaryName, strings.TrimPrefix(release.TagName, "v"), runtime.GOOS, runtime.GOARCH, ext)
+	var archiveURL, checksumsURL string
+	for _, asset := range release.Assets {
+		switch asset.Name {
+		case archiveName:
+			archiveURL = asset.URL
+		case binaryName + "_checksums.txt":
+			checksumsURL = asset.URL
+		}
+	}
+	if archiveURL == "" {
+		return nil, fmt.Errorf("release %s has no archive for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	if checksumsURL == "" {
+		return nil, fmt.Errorf("release %s has no checksums", release.TagName)
+	}
+
+	fmt.Printf("Downloading %s...\n", archiveName)
+	archive, err := httpGet(archiveURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", archiveName, err)
+	}
+	checksums, err := httpGet(checksumsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the checksums: %v", err)
+	}
+	sum := sha256.Sum256(archive)
+	if !checksumListed(string(checksums), archiveName, hex.EncodeToString(sum[:])) {
+		return nil, fmt.Errorf("the checksum of %s does not match the release", archiveName)
+	}
+
+	if ext == ".zip" {
+		return binaryFromZip(archive)
+	}
+	return binaryFromTarGz(archive)
+}
+
+// checksumListed reports whether a sha256sum style list has sum for name
+func checksumListed(checksums, name, sum string) bool {
+	for _, line := range strings.Split(checksums, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == name {
+			return strings.EqualFold(fields[0], sum)
+		}
+	}
+	return false
+}
+
+func binaryFromTarGz(archive []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("the archive has no %s binary", binaryName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Base(header.Name) == binaryName {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+func binaryFromZip(archive []byte) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range reader.File {
+		if filepath.Base(file.Name) == binaryName+".exe" {
+			f, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return io.ReadAll(f)
+		}
+	}
+	return nil, fmt.Errorf("the archive has no %s.exe binary", binaryName)
+}
+
+// replaceBinary writes the new binary next to the running one and renames it into
+// place, so an interrupted upgrade leaves the old binary working. Windows cannot
+// overwrite a running binary, so the old one is moved aside first.
+func replaceBinary(executable string, binary []byte) error {
+	dir := filepath.Dir(executable)
+	tmp, err := os.CreateTemp(dir, "."+binaryName+"-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %v", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := executable + ".old"
+		os.Remove(old)
+		if err := os.Rename(executable, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), executable)
+}
+
+// httpGet returns the body of a successful GET request
+func httpGet(url string) ([]byte, error) {
+	client := &http.Client{Timeout: upgradeTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+// TestChecksumListed ensures a release archive is only accepted with its own checksum
+func TestChecksumListed(t *testing.T) {
+	checksums := `0a1b2c  kubectl-multi_0.3.0_linux_amd64.tar.gz
+3d4e5f  kubectl-multi_0.3.0_darwin_arm64.tar.gz
+`
+	tests := []struct {
+		name, sum string
+		want      bool
+	}{
+		{"kubectl-multi_0.3.0_linux_amd64.tar.gz", "0a1b2c", true},
+		{"kubectl-multi_0.3.0_linux_amd64.tar.gz", "0A1B2C", true},
+		{"kubectl-multi_0.3.0_linux_amd64.tar.gz", "3d4e5f", false},
+		{"kubectl-multi_0.3.0_windows_amd64.zip", "0a1b2c", false},
+	}
+
+	for _, tt := range tests {
+		if got := checksumListed(checksums, tt.name, tt.sum); got != tt.want {
+			t.Errorf("checksumListed(%q, %q) = %v, want %v", tt.name, tt.sum, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// namespaceUtilization is the requests and limits of one namespace of a cluster and
+// the ceiling they are measured against: the quota, or the allocatable of the nodes
+type namespaceUtilization struct {
+	Namespace   string
+	Pods        int
+	ReqCPU      resource.Quantity
+	ReqMemory   resource.Quantity
+	LimCPU      resource.Quantity
+	LimMemory   resource.Quantity
+	CeilCPU     resource.Quantity
+	CeilMemory  resource.Quantity
+	QuotaCPU    bool
+	QuotaMemory bool
+}
+
+func newUtilizationCommand() *cobra.Command {
+	var threshold int
+
+	cmd := &cobra.Command{
+		Use:   "utilization",
+		Short: "Compare the resource requests of a namespace with its quota on every cluster",
+		Long: `Sum the resource requests and limits of the pods of a namespace on every managed
+cluster and compare the requests with the ceiling of the namespace: its ResourceQuota
+when it has one, otherwise the allocatable resources of the nodes.
+
+Clusters where the requests reach --threshold percent of the ceiling are marked
+NearLimit, and OverLimit at 100 percent.`,
+		Example: `# Show how close team-a is to its quota on every cluster
+kubectl multi utilization -n team-a
+
+# Mark clusters from 90 percent on
+kubectl multi utilization -n team-a --threshold 90`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, _ := GetGlobalFlags()
+			return handleUtilizationCommand(threshold, kubeconfig, remoteCtx, namespace)
+		},
+	}
+
+	cmd.Flags().IntVar(&threshold, "threshold", 80, "percentage of the ceiling from which a cluster is marked NearLimit")
+
+	return cmd
+}
+
+func handleUtilizationCommand(threshold int, kubeconfig, remoteCtx, namespace string) error {
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "CLUSTER\tNAMESPACE\tPODS\tCPU REQUESTS\tCPU LIMITS\tCPU CEILING\tCPU USED\tMEMORY REQUESTS\tMEMORY LIMITS\tMEMORY CEILING\tMEMORY USED\tSTATUS\n")
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Client() == nil {
+			continue
+		}
+
+		u, err := getNamespaceUtilization(clusterInfo, clusterInfo.TargetNamespace(namespace))
+		if err != nil {
+			warnClusterFailure(clusterInfo.Name, err, "compute utilization")
+			continue
+		}
+
+		cpuCeiling, memoryCeiling := formatCPU(u.CeilCPU), formatMemory(u.CeilMemory)
+		if u.QuotaCPU {
+			cpuCeiling += " (quota)"
+		}
+		if u.QuotaMemory {
+			memoryCeiling += " (quota)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			clusterInfo.Name, u.Namespace, u.Pods,
+			formatCPU(u.ReqCPU), formatCPU(u.LimCPU), cpuCeiling, formatPercent(u.ReqCPU, u.CeilCPU),
+			formatMemory(u.ReqMemory), formatMemory(u.LimMemory), memoryCeiling, formatPercent(u.ReqMemory, u.CeilMemory),
+			utilizationStatus(u, threshold))
+	}
+	return nil
+}
+
+// getNamespaceUtilization sums the requests and limits of the non-terminated pods of
+// a namespace and determines its ceiling
+func getNamespaceUtilization(clusterInfo cluster.ClusterInfo, namespace string) (namespaceUtilization, error) {
+	u := namespaceUtilization{Namespace: namespace}
+	ctx, cancel := clusterInfo.RequestContext()
+	defer cancel()
+
+	pods, err := clusterInfo.Client().CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return u, fmt.Errorf("failed to list pods: %v", err)
+	}
+	for i := range pods.Items {
+		u.Pods++
+		requests, limits := resourcehelper.PodRequestsAndLimits(&pods.Items[i])
+		u.ReqCPU.Add(requests[corev1.ResourceCPU])
+		u.ReqMemory.Add(requests[corev1.ResourceMemory])
+		u.LimCPU.Add(limits[corev1.ResourceCPU])
+		u.LimMemory.Add(limits[corev1.ResourceMemory])
+	}
+
+	// The tightest quota wins when a namespace has several
+	quotas, err := clusterInfo.Client().CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return u, fmt.Errorf("failed to list resource quotas: %v", err)
+	}
+	for _, quota := range quotas.Items {
+		for _, name := range []corev1.ResourceName{corev1.ResourceRequestsCPU, corev1.ResourceCPU} {
+			if hard, ok := quota.Status.Hard[name]; ok && (!u.QuotaCPU || hard.Cmp(u.CeilCPU) < 0) {
+				u.CeilCPU, u.QuotaCPU = hard, true
+			}
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceRequestsMemory, corev1.ResourceMemory} {
+			if hard, ok := quota.Status.Hard[name]; ok && (!u.QuotaMemory || hard.Cmp(u.CeilMemory) < 0) {
+				u.CeilMemory, u.QuotaMemory = hard, true
+			}
+		}
+	}
+	if u.QuotaCPU && u.QuotaMemory {
+		return u, nil
+	}
+
+	nodes, err := clusterInfo.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return u, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	var allocCPU, allocMemory resource.Quantity
+	for _, node := range nodes.Items {
+		allocCPU.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		allocMemory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+	}
+	if !u.QuotaCPU {
+		u.CeilCPU = allocCPU
+	}
+	if !u.QuotaMemory {
+		u.CeilMemory = allocMemory
+	}
+	return u, nil
+}
+
+// utilizationStatus returns OverLimit when the requests reach the ceiling, NearLimit
+// from threshold percent of it on, and OK otherwise
+func utilizationStatus(u namespaceUtilization, threshold int) string {
+	status := "OK"
+	for _, pair := range [][2]resource.Quantity{{u.ReqCPU, u.CeilCPU}, {u.ReqMemory, u.CeilMemory}} {
+		requested, ceiling := pair[0], pair[1]
+		if ceiling.IsZero() {
+			continue
+		}
+		percent := requested.MilliValue() * 100 / ceiling.MilliValue()
+		if percent >= 100 {
+			return "OverLimit"
+		}
+		if percent >= int64(threshold) {
+			status = "NearLimit"
+		}
+	}
+	return status
+}
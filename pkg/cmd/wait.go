@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+func newWaitCommand() *cobra.Command {
+	var forCondition string
+	var selector string
+	var all bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "wait TYPE[/NAME] [NAME...] --for=CONDITION",
+		Short: "Wait for a condition on resources in all managed clusters",
+		Long: `Wait until a condition holds for the given resources in every managed cluster.
+The condition is checked per cluster with kubectl wait and accepts the same forms:
+
+  --for=condition=Available              a status condition is True
+  --for=condition=Ready=false            a status condition has the given value
+  --for=jsonpath='{.status.phase}'=Running
+                                         a JSONPath expression has the given value
+  --for=jsonpath='{.status.readyReplicas}'
+                                         a JSONPath expression exists
+  --for=delete                           the resources are deleted
+
+--timeout bounds the whole wait, not each cluster. The command fails when the
+condition is not met in every cluster.`,
+		Example: `# Wait for a deployment to become available everywhere
+kubectl multi wait deployment/nginx --for=condition=Available -n shop
+
+# Wait until all pods of an app are running
+kubectl multi wait pods -l app=web --for=jsonpath='{.status.phase}'=Running
+
+# Wait until a job is gone from every cluster
+kubectl multi wait job/migrate --for=delete --timeout=2m`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeconfig, remoteCtx, _, namespace, allNamespaces := GetGlobalFlags()
+			return handleWaitCommand(args, forCondition, selector, all, timeout, kubeconfig, remoteCtx, namespace, allNamespaces)
+		},
+	}
+
+	cmd.Flags().StringVar(&forCondition, "for", "", "the condition to wait for: condition=NAME[=VALUE], jsonpath='{JSONPATH}'[=VALUE] or delete")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "selector (label query) to filter on")
+	cmd.Flags().BoolVar(&all, "all", false, "select all resources of the type in the namespace")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "how long to wait for all clusters, e.g. 2m")
+	cmd.MarkFlagRequired("for")
+	return cmd
+}
+
+// validateWaitCondition checks the forms of --for that kubectl wait accepts, so a
+// typo fails once instead of in every cluster
+func validateWaitCondition(forCondition string) error {
+	switch {
+	case forCondition == "delete":
+		return nil
+	case strings.HasPrefix(forCondition, "condition="):
+		if strings.TrimPrefix(forCondition, "condition=") == "" {
+			return fmt.Errorf("--for=condition= requires a condition name")
+		}
+		return nil
+	case strings.HasPrefix(forCondition, "jsonpath="):
+		expression := strings.TrimPrefix(forCondition, "jsonpath=")
+		end := strings.LastIndex(expression, "}")
+		if !strings.HasPrefix(expression, "{") || end < 0 {
+			return fmt.Errorf("--for=jsonpath requires an expression in braces, e.g. jsonpath='{.status.phase}'=Running")
+		}
+		if rest := expression[end+1:]; rest != "" && !strings.HasPrefix(rest, "=") {
+			return fmt.Errorf("unexpected %q after the JSONPath expression, expected =VALUE", rest)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported --for %q, expected condition=NAME[=VALUE], jsonpath='{JSONPATH}'[=VALUE] or delete", forCondition)
+}
+
+func handleWaitCommand(args []string, forCondition, selector string, all bool, timeout time.Duration, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	if err := validateWaitCondition(forCondition); err != nil {
+		return err
+	}
+
+	clusters, err := cluster.DiscoverClusters(kubeconfig, remoteCtx)
+	if err != nil {
+		return fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters discovered")
+	}
+
+	waitArgs := append([]string{"wait"}, args...)
+	waitArgs = append(waitArgs, "--for="+forCondition)
+	if selector != "" {
+		waitArgs = append(waitArgs, "--selector", selector)
+	}
+	if all {
+		waitArgs = append(waitArgs, "--all")
+	}
+
+	// The clusters are waited for one after another within one deadline; a cluster
+	// reached after the deadline is still checked once
+	deadline := time.Now().Add(timeout)
+	tw := newTable(util.GetOutputStream(), nil, nil)
+	fmt.Fprintf(tw, "CLUSTER\tOBJECT\tRESULT\n")
+	waited, failed := 0, 0
+	for _, clusterInfo := range clusters {
+		if clusterInfo.Context == remoteCtx {
+			continue
+		}
+		waited++
+
+		remaining := time.Until(deadline).Round(time.Second)
+		if remaining < 0 {
+			remaining = 0
+		}
+		clusterArgs := append(append([]string{}, waitArgs...), "--timeout", remaining.String())
+		targetNS := ""
+		if !allNamespaces {
+			targetNS = clusterNamespace(clusterInfo, namespace)
+		}
+		output, err := runKubectl(passthroughArgs(clusterArgs, clusterInfo.Context, targetNS, allNamespaces), kubeconfig)
+		if err != nil {
+			failed++
+			fmt.Fprintf(tw, "%s\t-\t%s\n", clusterInfo.Name, waitFailure(output))
+			cluster.RecordFailure(clusterInfo.Name, "wait for "+forCondition, fmt.Errorf("%v: %s", err, strings.TrimSpace(output)))
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			if object, result, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", clusterInfo.Name, object, result)
+			}
+		}
+	}
+	tw.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("condition %s not met in %d of %d clusters", forCondition, failed, waited)
+	}
+	return nil
+}
+
+// waitFailure returns the reason kubectl wait printed for a failed wait
+func waitFailure(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	reason := strings.TrimPrefix(strings.TrimSpace(lines[len(lines)-1]), "error: ")
+	if reason == "" {
+		return "failed"
+	}
+	return reason
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import "testing"
+
+// TestValidateWaitCondition ensures the --for forms of kubectl wait are accepted
+func TestValidateWaitCondition(t *testing.T) {
+	tests := []struct {
+		forCondition string
+		valid        bool
+	}{
+		{"delete", true},
+		{"condition=Available", true},
+		{"condition=Ready=false", true},
+		{"jsonpath={.status.phase}=Running", true},
+		{"jsonpath={.status.readyReplicas}", true},
+		{"condition=", false},
+		{"jsonpath=.status.phase=Running", false},
+		{"jsonpath={.status.phase}Running", false},
+		{"ready", false},
+	}
+
+	for _, tt := range tests {
+		if err := validateWaitCondition(tt.forCondition); (err == nil) != tt.valid {
+			t.Errorf("validateWaitCondition(%q) = %v, want valid %v", tt.forCondition, err, tt.valid)
+		}
+	}
+}
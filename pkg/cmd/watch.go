@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/multicluster"
+	"kubectl-multi/pkg/util"
+)
+
+// newCachedServer builds an informer backed server for the selected clusters, so the
+// repeated reads of watch, ui and serve are answered from cache instead of listing
+// every cluster again
+func newCachedServer(kubeconfig, remoteCtx string) (*multicluster.Server, *multicluster.MultiClusterClient, error) {
+	client, err := multicluster.New(multicluster.Options{
+		Kubeconfig:     kubeconfig,
+		RemoteContext:  remoteCtx,
+		Clusters:       userConfig.ExpandClusters(clusterNames),
+		ClusterTimeout: clusterTO,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return multicluster.NewServer(client), client, nil
+}
+
+// newServerClient returns the client of the serve daemon of --server, authenticated
+// with the token of --server-token-file, or else with the token serve generated when
+// its file exists
+func newServerClient() (*multicluster.ServerClient, error) {
+	token := ""
+	if serverTokenFile != "" {
+		var err error
+		if token, err = readServeToken(serverTokenFile); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(serveTokenPath()); err == nil {
+		if token, err = readServeToken(serveTokenPath()); err != nil {
+			return nil, err
+		}
+	}
+	return multicluster.NewServerClient(serverAddress, token)
+}
+
+// newObjectLister returns the function reading the objects of a resource type from a
+// running serve daemon when --server is set, or else from the informer caches of a
+// server in this process, which the returned function closes
+func newObjectLister(ctx context.Context, kubeconfig, remoteCtx string) (func(string, multicluster.ServerOptions) ([]multicluster.Object, []cluster.ClusterFailure, error), func(), error) {
+	if serverAddress != "" {
+		client, err := newServerClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		names := userConfig.ExpandClusters(clusterNames)
+		return func(resourceType string, opts multicluster.ServerOptions) ([]multicluster.Object, []cluster.ClusterFailure, error) {
+			return client.List(ctx, resourceType, names, opts)
+		}, func() {}, nil
+	}
+
+	server, _, err := newCachedServer(kubeconfig, remoteCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func(resourceType string, opts multicluster.ServerOptions) ([]multicluster.Object, []cluster.ClusterFailure, error) {
+		objects, failures := server.List(resourceType, nil, opts)
+		return objects, failures, nil
+	}, server.Close, nil
+}
+
+// handleGetServer prints the objects of a resource type once, read from the running
+// serve daemon of --server. Tables and csv are printed like --refresh prints them, the
+// other formats like -o prints the objects of the clusters.
+func handleGetServer(resourceType, resourceName, outputFormat, selector string, showLabels bool, namespace string, allNamespaces bool) error {
+	out := util.GetOutputStream()
+	serverOpts := multicluster.ServerOptions{Namespace: namespace, AllNamespaces: allNamespaces, Name: resourceName}
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector: %v", err)
+		}
+		serverOpts.Selector = parsed
+	}
+
+	client, err := newServerClient()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	objects, failures, err := client.List(ctx, resourceType, userConfig.ExpandClusters(clusterNames), serverOpts)
+	if err != nil {
+		return err
+	}
+	for i := range objects {
+		redactSecret(&objects[i].Unstructured)
+	}
+	for _, failure := range failures {
+		warnClusterFailure(failure.Cluster, failure.Err, "%s", failure.Operation)
+	}
+
+	switch outputFormat {
+	case "", "wide", "table", "csv":
+	default:
+		return multicluster.Print(out, outputFormat, objects)
+	}
+	printer, err := newStreamPrinter(out, "--server", resourceType, outputFormat, showLabels, allNamespaces)
+	if err != nil {
+		return err
+	}
+	if err := printer.WriteHeader(); err != nil {
+		return err
+	}
+	for i := range objects {
+		if err := printer.WriteRow(objects[i].Cluster, &objects[i].Unstructured); err != nil {
+			return err
+		}
+	}
+	return printer.Flush()
+}
+
+// handleGetWatch prints the objects of a resource type in all clusters, unless
+// watchOnly is set, and then every change of them until interrupted. Each cluster is
+// listed once to fill an informer cache; after that only the watch events of the
+// clusters are read. Clusters that fail are re-listed and watched again by their
+// informers.
+func handleGetWatch(resourceType, resourceName, outputFormat, selector string, showLabels, watchOnly bool, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	writeEvent, err := newWatchWriter(resourceType, outputFormat, showLabels, allNamespaces)
+	if err != nil {
+		return err
+	}
+	var notifier *watchNotifier
+	if notifyOn != "" {
+		if notifier, err = newWatchNotifier(notifyOn, notifyExec); err != nil {
+			return err
+		}
+	}
+
+	serverOpts := multicluster.ServerOptions{Namespace: namespace, AllNamespaces: allNamespaces, Name: resourceName, WatchOnly: watchOnly}
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector: %v", err)
+		}
+		serverOpts.Selector = parsed
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var writeErr error
+	send := func(event multicluster.WatchEvent) {
+		if writeErr != nil {
+			return
+		}
+		if event.Object == nil {
+			printWatchNotice(resourceType, event)
+			return
+		}
+		redactSecret(event.Object)
+		if event.OldObject != nil {
+			redactSecret(event.OldObject)
+		}
+		if writeErr = writeEvent(event); writeErr != nil {
+			stop()
+			return
+		}
+		if notifier != nil {
+			notifier.observe(event)
+		}
+	}
+
+	if serverAddress != "" {
+		client, err := newServerClient()
+		if err != nil {
+			return err
+		}
+		// The daemon streams the clusters that fail as notices
+		if err := client.Watch(ctx, resourceType, userConfig.ExpandClusters(clusterNames), serverOpts, send); err != nil {
+			return err
+		}
+		return writeErr
+	}
+
+	server, _, err := newCachedServer(kubeconfig, remoteCtx)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	failures := server.Watch(ctx, resourceType, nil, serverOpts, send)
+	// The failures were printed as notices when they happened
+	for _, failure := range failures {
+		cluster.RecordFailure(failure.Cluster, failure.Operation, failure.Err)
+	}
+	return writeErr
+}
+
+// newWatchWriter returns the function printing one watch event. Tables and csv print
+// a row per event; json prints one object per line with the cluster and event type,
+// so other tools can read the stream as it comes:
+//
+//	{"cluster":"cluster1","type":"MODIFIED","object":{...}}
+func newWatchWriter(resourceType, outputFormat string, showLabels, allNamespaces bool) (func(multicluster.WatchEvent) error, error) {
+	out := util.GetOutputStream()
+	if diffWatch {
+		if outputFormat != "" {
+			return nil, fmt.Errorf("--diff-watch prints its own format and cannot be combined with -o")
+		}
+		return func(event multicluster.WatchEvent) error {
+			return printWatchDiff(out, event)
+		}, nil
+	}
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(out)
+		return func(event multicluster.WatchEvent) error {
+			return encoder.Encode(event)
+		}, nil
+	}
+
+	printer, err := newStreamPrinter(out, "--watch", resourceType, outputFormat, showLabels, allNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	if err := printer.WriteHeader(); err != nil {
+		return nil, err
+	}
+	return func(event multicluster.WatchEvent) error {
+		if err := printer.WriteRow(event.Cluster, event.Object); err != nil {
+			return err
+		}
+		return printer.Flush()
+	}, nil
+}
+
+// newStreamPrinter builds the printer of the output formats that can be written
+// row by row as the objects come, the tables and csv
+func newStreamPrinter(out io.Writer, mode, resourceType, outputFormat string, showLabels, allNamespaces bool) (multicluster.Printer, error) {
+	opts := multicluster.PrinterOptions{
+		WithNamespace: allNamespaces,
+		ShowLabels:    showLabels,
+		ShowColumns:   shownColumns,
+		HideColumns:   hiddenColumns,
+		MaxWidth:      tableWidth(),
+	}
+	if handler, ok := multicluster.LookupResourceHandler(resourceType); ok {
+		opts.Columns = handler.Columns
+	}
+	switch outputFormat {
+	case "", "wide":
+		opts.Wide = outputFormat == "wide"
+		outputFormat = "table"
+	case "table", "csv":
+	default:
+		return nil, fmt.Errorf("%s does not support -o %s, use table, wide, csv or json", mode, outputFormat)
+	}
+	return multicluster.NewPrinter(outputFormat, out, opts)
+}
+
+// handleGetRefresh prints the objects of a resource type in all clusters every
+// interval until interrupted, replacing the previous output on a terminal like
+// watch(1) does. The clusters are discovered once and the objects read from informer
+// caches, so a refresh costs no calls to the clusters.
+func handleGetRefresh(resourceType, resourceName, outputFormat, selector string, showLabels bool, interval time.Duration, kubeconfig, remoteCtx, namespace string, allNamespaces bool) error {
+	out := util.GetOutputStream()
+	// Validate the output format before the clusters are contacted
+	if _, err := newStreamPrinter(io.Discard, "--refresh", resourceType, outputFormat, showLabels, allNamespaces); err != nil {
+		return err
+	}
+	serverOpts := multicluster.ServerOptions{Namespace: namespace, AllNamespaces: allNamespaces, Name: resourceName}
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector: %v", err)
+		}
+		serverOpts.Selector = parsed
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	list, closeList, err := newObjectLister(ctx, kubeconfig, remoteCtx)
+	if err != nil {
+		return err
+	}
+	defer closeList()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	redraw := term.IsTerminal(int(os.Stdout.Fd()))
+	command := strings.Join(os.Args[1:], " ")
+
+	for {
+		objects, failures, err := list(resourceType, serverOpts)
+		if err != nil {
+			return err
+		}
+		if redraw {
+			fmt.Fprint(out, "\033[H\033[2J")
+		}
+		fmt.Fprintf(out, "Every %s: %s    %s\n\n", interval, command, time.Now().Format("15:04:05"))
+		printer, err := newStreamPrinter(out, "--refresh", resourceType, outputFormat, showLabels, allNamespaces)
+		if err != nil {
+			return err
+		}
+		if err := printer.WriteHeader(); err != nil {
+			return err
+		}
+		for i := range objects {
+			redactSecret(&objects[i].Unstructured)
+			if err := printer.WriteRow(objects[i].Cluster, &objects[i].Unstructured); err != nil {
+				return err
+			}
+		}
+		if err := printer.Flush(); err != nil {
+			return err
+		}
+		for _, failure := range failures {
+			warnClusterFailure(failure.Cluster, failure.Err, "%s", failure.Operation)
+		}
+		if !redraw {
+			fmt.Fprintln(out)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// printWatchDiff prints an event like snapshot diff does: added and deleted objects
+// with + and -, and modified objects with ~ and only the fields that changed since the
+// cached version. Modifications of nothing but the resource version are skipped.
+func printWatchDiff(out io.Writer, event multicluster.WatchEvent) error {
+	obj := event.Object
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+
+	marker := "~"
+	var changes []fieldChange
+	switch event.Type {
+	case "ADDED":
+		marker = "+"
+	case "DELETED":
+		marker = "-"
+	default:
+		if event.OldObject != nil {
+			changes = diffObjects(watchedFields(event.OldObject), watchedFields(obj))
+			if len(changes) == 0 {
+				return nil
+			}
+		}
+	}
+
+	if _, err := fmt.Fprintf(out, "%s %s %s %s\n", event.Cluster, marker, strings.ToLower(obj.GetKind()), name); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if _, err := fmt.Fprintf(out, "    %s: %s -> %s\n", change.Path, change.Old, change.New); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchedFields returns the fields of an object compared by --diff-watch, without
+// those the API server changes on every write
+func watchedFields(obj *unstructured.Unstructured) map[string]interface{} {
+	fields := obj.DeepCopy()
+	unstructured.RemoveNestedField(fields.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(fields.Object, "metadata", "managedFields")
+	return fields.Object
+}
+
+// printWatchNotice tells on stderr that the watch of a cluster failed or resumed, so
+// a cluster does not silently drop out of the stream
+func printWatchNotice(resourceType string, event multicluster.WatchEvent) {
+	if quiet {
+		return
+	}
+	cluster.ClearProgress()
+	switch event.Type {
+	case multicluster.WatchError:
+		fmt.Fprintf(os.Stderr, "Warning: failed to watch %s in cluster %s: %s\n", resourceType, event.Cluster, event.Message)
+	case multicluster.WatchReconnected:
+		fmt.Fprintf(os.Stderr, "Reconnected to cluster %s, watching %s again\n", event.Cluster, resourceType)
+	}
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+)
+
+// Settings are flag defaults read from the config file. Flags given on the command
+// line always win.
+type Settings struct {
+	Kubeconfig    string   `json:"kubeconfig,omitempty"`
+	RemoteContext string   `json:"remoteContext,omitempty"`
+	Namespace     string   `json:"namespace,omitempty"`
+	Output        string   `json:"output,omitempty"`
+	Clusters      []string `json:"clusters,omitempty"`
+	Theme         string   `json:"theme,omitempty"`
+	Layout        string   `json:"layout,omitempty"`
+	AuditLog      string   `json:"auditLog,omitempty"`
+	Notify        string   `json:"notify,omitempty"`
+	NotifyAfter   string   `json:"notifyAfter,omitempty"`
+	ColumnWidth   string   `json:"columnWidth,omitempty"`
+	CompatCheck   string   `json:"compatCheck,omitempty"`
+	WDSContext    string   `json:"wdsContext,omitempty"`
+	// ReadOnly, MutationAllowedClusters, MutationDeniedClusters and Confirmations add to
+	// the safety settings of the same name at the top of the config file, so a profile
+	// such as prod can protect its clusters. They never lift a protection.
+	ReadOnly                bool           `json:"readOnly,omitempty"`
+	MutationAllowedClusters []string       `json:"mutationAllowedClusters,omitempty"`
+	MutationDeniedClusters  []string       `json:"mutationDeniedClusters,omitempty"`
+	Confirmations           []Confirmation `json:"confirmations,omitempty"`
+}
+
+// Credentials replace the kubeconfig user of one cluster, for fleets that mix
+// authentication methods. Set a token, a client certificate or an exec provider.
+type Credentials struct {
+	Token             string          `json:"token,omitempty"`
+	TokenFile         string          `json:"tokenFile,omitempty"`
+	ClientCertificate string          `json:"clientCertificate,omitempty"`
+	ClientKey         string          `json:"clientKey,omitempty"`
+	Exec              *ExecCredential `json:"exec,omitempty"`
+}
+
+// ExecCredential runs a client-go credential plugin such as aws, gke-gcloud-auth-plugin
+// or kubelogin
+type ExecCredential struct {
+	APIVersion string            `json:"apiVersion,omitempty"`
+	Command    string            `json:"command"`
+	Args       []string          `json:"args,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+}
+
+// TLS overrides how the API server certificate of one cluster is verified, e.g. for
+// lab clusters with self-signed certificates
+type TLS struct {
+	InsecureSkipTLSVerify bool   `json:"insecureSkipTLSVerify,omitempty"`
+	CertificateAuthority  string `json:"certificateAuthority,omitempty"`
+}
+
+// Confirmation makes a command ask for a typed phrase before it changes clusters
+type Confirmation struct {
+	// Command is the command path below kubectl multi, e.g. delete or rollout restart
+	Command string `json:"command"`
+	// MinClusters only asks when at least this many clusters that are not exempt are
+	// targeted
+	MinClusters int `json:"minClusters,omitempty"`
+	// Phrase must be typed to confirm (default "yes")
+	Phrase string `json:"phrase,omitempty"`
+	// ExemptClusters never need a confirmation, e.g. lab clusters. Groups are accepted.
+	ExemptClusters []string `json:"exemptClusters,omitempty"`
+}
+
+// ClusterProvider is an executable that prints additional clusters, for fleets whose
+// inventory lives outside of KubeStellar
+type ClusterProvider struct {
+	Name    string            `json:"name"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// Hook runs shell commands around the commands of kubectl multi. The command and
+// cluster are passed in KUBECTL_MULTI_* environment variables.
+type Hook struct {
+	// Commands the hook applies to, e.g. apply or rollout restart (default all)
+	Commands []string `json:"commands,omitempty"`
+	// PreCommand runs before the command; a non-zero exit stops it
+	PreCommand string `json:"preCommand,omitempty"`
+	// PostCommand runs after the command
+	PostCommand string `json:"postCommand,omitempty"`
+	// PreCluster runs per discovered cluster; a non-zero exit leaves the cluster out
+	PreCluster string `json:"preCluster,omitempty"`
+	// PostCluster runs after the command per cluster it used
+	PostCluster string `json:"postCluster,omitempty"`
+}
+
+// Column is a column of the get table, read from each object with a JSONPath
+// expression
+type Column struct {
+	Name     string `json:"name"`
+	JSONPath string `json:"jsonPath"`
+	// Format converts the value, e.g. age, upper or lower
+	Format string `json:"format,omitempty"`
+	// Wide columns are only printed with -o wide
+	Wide bool `json:"wide,omitempty"`
+}
+
+// Config is the content of ~/.kubectl-multi/config.yaml
+type Config struct {
+	Defaults Settings `json:"defaults,omitempty"`
+	// ClusterGroups name sets of clusters that can be passed to --clusters
+	ClusterGroups map[string][]string `json:"clusterGroups,omitempty"`
+	// ClusterNamespaces map cluster names to the namespace used when -n is not given
+	ClusterNamespaces map[string]string `json:"clusterNamespaces,omitempty"`
+	// ClusterCredentials override the kubeconfig credentials per cluster name
+	ClusterCredentials map[string]Credentials `json:"clusterCredentials,omitempty"`
+	// ClusterTLS override the TLS verification per cluster name
+	ClusterTLS map[string]TLS `json:"clusterTLS,omitempty"`
+	// ReadOnly disables all commands that change clusters, e.g. on shared jump hosts
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// MutationAllowedClusters, when set, are the only clusters mutating commands may
+	// change; MutationDeniedClusters may never be changed. Both accept cluster groups.
+	MutationAllowedClusters []string `json:"mutationAllowedClusters,omitempty"`
+	MutationDeniedClusters  []string `json:"mutationDeniedClusters,omitempty"`
+	// Confirmations replace the default confirmation of delete when set, so an empty
+	// list turns it off. A pointer tells the empty list from an unset one.
+	Confirmations *[]Confirmation `json:"confirmations,omitempty"`
+	// ClusterProviders add the clusters printed by executables to the discovered ones
+	ClusterProviders []ClusterProvider `json:"clusterProviders,omitempty"`
+	// Hooks run shell commands before and after commands and per cluster
+	Hooks []Hook `json:"hooks,omitempty"`
+	// Columns replace the columns of get per resource type, e.g. pods or a CRD
+	Columns map[string][]Column `json:"columns,omitempty"`
+	// Profiles are named settings selected with --profile, e.g. prod, staging or lab
+	Profiles map[string]Settings `json:"profiles,omitempty"`
+}
+
+// DefaultPath returns the path of the config file used when --config is not set
+func DefaultPath() string {
+	return filepath.Join(homedir.HomeDir(), ".kubectl-multi", "config.yaml")
+}
+
+// Load reads the config file at path. A missing file is only an error when the path
+// was given explicitly; otherwise an empty config is returned.
+func Load(path string, explicit bool) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Profile returns the settings of a named profile
+func (c *Config) Profile(name string) (Settings, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Settings{}, fmt.Errorf("profile %q not found in config file", name)
+	}
+	return profile, nil
+}
+
+// ExpandClusters replaces cluster group names with the clusters of the group
+func (c *Config) ExpandClusters(names []string) []string {
+	var clusters []string
+	for _, name := range names {
+		if group, ok := c.ClusterGroups[name]; ok {
+			clusters = append(clusters, group...)
+		} else {
+			clusters = append(clusters, name)
+		}
+	}
+	return clusters
+}
+
+// Save writes cfg to the config file at path, creating its directory when needed.
+// Comments in an existing file are not preserved.
+func Save(path string, cfg *Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package multicluster
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Protect wraps the handler of a server so that every request must carry the bearer
+// token, unless token is empty, and a Host header from hosts, unless hosts is empty.
+// Checking the Host keeps web pages from reaching a server on localhost through DNS
+// rebinding. /healthz only needs the Host, so liveness probes need no token.
+func Protect(handler http.Handler, token string, hosts []string) http.Handler {
+	allowed := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		allowed[strings.ToLower(host)] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(allowed) > 0 && !allowed[strings.ToLower(r.Host)] {
+			http.Error(w, "unknown host "+r.Host, http.StatusForbidden)
+			return
+		}
+		if token != "" && r.URL.Path != "/healthz" {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,171 @@
+// Package multicluster gives other Go programs the cluster discovery, fan-out and
+// printing that the kubectl multi commands are built on, without running the plugin.
+package multicluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// ClusterAnnotation records the cluster an object was read from in aggregated output
+const ClusterAnnotation = "kubectl-multi.kubestellar.io/cluster"
+
+// Options configure a MultiClusterClient. Zero values use the defaults of the plugin.
+type Options struct {
+	// Kubeconfig is the kubeconfig file; empty uses the default loading rules
+	Kubeconfig string
+	// RemoteContext is the ITS context listing the ManagedClusters, "its1" by default
+	RemoteContext string
+	// Clusters limits the client to the named clusters
+	Clusters []string
+	// ClusterTimeout bounds the API calls of one operation per cluster
+	ClusterTimeout time.Duration
+}
+
+// MultiClusterClient runs operations against all workload clusters of a KubeStellar
+// installation. Its options only apply to the client, so a program may use several
+// clients with different options at once.
+type MultiClusterClient struct {
+	remoteCtx string
+	timeout   time.Duration
+	clusters  []cluster.ClusterInfo
+}
+
+// Object is an object read from one cluster
+type Object struct {
+	Cluster string
+	unstructured.Unstructured
+}
+
+// New discovers the clusters and builds a client for them
+func New(opts Options) (*MultiClusterClient, error) {
+	if opts.RemoteContext == "" {
+		opts.RemoteContext = "its1"
+	}
+	selected := make(map[string]bool, len(opts.Clusters))
+	for _, name := range opts.Clusters {
+		selected[name] = true
+	}
+
+	clusters, err := cluster.DiscoverClusters(opts.Kubeconfig, opts.RemoteContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover clusters: %v", err)
+	}
+	c := &MultiClusterClient{remoteCtx: opts.RemoteContext, timeout: opts.ClusterTimeout}
+	for _, clusterInfo := range clusters {
+		// The ITS only hosts the ManagedClusters, the commands skip it as well
+		if clusterInfo.Context == opts.RemoteContext {
+			continue
+		}
+		// Clients are built on first use, so leaving a cluster out here costs nothing
+		if len(selected) > 0 && !selected[clusterInfo.Name] {
+			continue
+		}
+		c.clusters = append(c.clusters, clusterInfo)
+	}
+	if len(c.clusters) == 0 {
+		return nil, fmt.Errorf("no clusters discovered")
+	}
+	return c, nil
+}
+
+// Clusters returns the workload clusters of the client
+func (c *MultiClusterClient) Clusters() []cluster.ClusterInfo {
+	return c.clusters
+}
+
+// ForEach calls fn for every cluster with a context bounded by the cluster timeout.
+// The errors of fn are returned as failures and fn is still called for the remaining
+// clusters.
+func (c *MultiClusterClient) ForEach(fn func(ctx context.Context, clusterInfo cluster.ClusterInfo) error) []cluster.ClusterFailure {
+	var failures []cluster.ClusterFailure
+	for _, clusterInfo := range c.clusters {
+		if clusterInfo.Client() == nil {
+			failures = append(failures, cluster.ClusterFailure{Cluster: clusterInfo.Name, Operation: "connect", Err: fmt.Errorf("no client available")})
+			continue
+		}
+		ctx, cancel := c.requestContext(clusterInfo)
+		err := fn(ctx, clusterInfo)
+		cancel()
+		if err != nil {
+			cluster.RecordFailure(clusterInfo.Name, "run", err)
+			failures = append(failures, cluster.ClusterFailure{Cluster: clusterInfo.Name, Operation: "run", Err: err})
+		}
+	}
+	return failures
+}
+
+// requestContext returns the context of one call of fn, bounded by the cluster timeout
+// of the client
+func (c *MultiClusterClient) requestContext(clusterInfo cluster.ClusterInfo) (context.Context, context.CancelFunc) {
+	ctx, cancel := clusterInfo.RequestContext()
+	if c.timeout == 0 {
+		return ctx, cancel
+	}
+	timeoutCtx, cancelTimeout := context.WithTimeout(ctx, c.timeout)
+	return timeoutCtx, func() {
+		cancelTimeout()
+		cancel()
+	}
+}
+
+// List lists a resource type, given like on the kubectl command line, in every
+// cluster. An empty namespace uses the default namespace of each cluster.
+func (c *MultiClusterClient) List(resourceType, namespace string, allNamespaces bool, opts metav1.ListOptions) ([]Object, []cluster.ClusterFailure) {
+	resolver := util.NewGVRResolver(resourceType)
+	var objects []Object
+	failures := c.ForEach(func(ctx context.Context, clusterInfo cluster.ClusterInfo) error {
+		gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+		if err != nil {
+			return fmt.Errorf("failed to discover resource type %s: %v", resourceType, err)
+		}
+		var resourceClient dynamic.ResourceInterface = clusterInfo.DynamicClient().Resource(gvr)
+		if isNamespaced && !allNamespaces {
+			resourceClient = clusterInfo.DynamicClient().Resource(gvr).Namespace(clusterInfo.TargetNamespace(namespace))
+		}
+		list, err := resourceClient.List(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %v", gvr.Resource, err)
+		}
+		for _, item := range list.Items {
+			objects = append(objects, Object{Cluster: clusterInfo.Name, Unstructured: item})
+		}
+		return nil
+	})
+	return objects, failures
+}
+
+// Get returns the named object from every cluster that has it
+func (c *MultiClusterClient) Get(resourceType, namespace, name string) ([]Object, []cluster.ClusterFailure) {
+	resolver := util.NewGVRResolver(resourceType)
+	var objects []Object
+	failures := c.ForEach(func(ctx context.Context, clusterInfo cluster.ClusterInfo) error {
+		gvr, isNamespaced, err := resolver.Resolve(clusterInfo.DiscoveryClient())
+		if err != nil {
+			return fmt.Errorf("failed to discover resource type %s: %v", resourceType, err)
+		}
+		var resourceClient dynamic.ResourceInterface = clusterInfo.DynamicClient().Resource(gvr)
+		if isNamespaced {
+			resourceClient = clusterInfo.DynamicClient().Resource(gvr).Namespace(clusterInfo.TargetNamespace(namespace))
+		}
+		obj, err := resourceClient.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get %s %s: %v", gvr.Resource, name, err)
+		}
+		objects = append(objects, Object{Cluster: clusterInfo.Name, Unstructured: *obj})
+		return nil
+	})
+	return objects, failures
+}
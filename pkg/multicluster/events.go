@@ -0,0 +1,48 @@
+package multicluster
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// Events returns a channel receiving the progress events of all cluster operations,
+// ClusterStarted, ClusterSucceeded, ClusterFailed and RowEmitted, until the returned
+// function is called. Operations block while the channel is full, so it must be
+// drained for as long as it is subscribed.
+func Events(buffer int) (<-chan cluster.Event, func()) {
+	events := make(chan cluster.Event, buffer)
+	done := make(chan struct{})
+	unsubscribe := cluster.Subscribe(func(event cluster.Event) {
+		select {
+		case events <- event:
+		case <-done:
+		}
+	})
+	return events, func() {
+		unsubscribe()
+		close(done)
+	}
+}
+
+// EmitObjectRow emits a RowEmitted event for an object printed for a cluster
+func EmitObjectRow(clusterName string, obj *unstructured.Unstructured) {
+	cluster.EmitRow(clusterName, map[string]string{
+		"kind":      obj.GetKind(),
+		"namespace": obj.GetNamespace(),
+		"name":      obj.GetName(),
+	})
+}
+
+// eventPrinter emits a RowEmitted event for every row of the wrapped Printer
+type eventPrinter struct {
+	Printer
+}
+
+func (p eventPrinter) WriteRow(clusterName string, obj *unstructured.Unstructured) error {
+	if err := p.Printer.WriteRow(clusterName, obj); err != nil {
+		return err
+	}
+	EmitObjectRow(clusterName, obj)
+	return nil
+}
@@ -0,0 +1,149 @@
+package multicluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// GetOptions are the arguments of kubectl multi get passed to a registered handler
+type GetOptions struct {
+	ResourceType  string
+	ResourceName  string
+	Selector      string
+	ShowLabels    bool
+	Namespace     string
+	AllNamespaces bool
+}
+
+// Column is a table column whose cells are read from each object with a JSONPath
+// expression such as "{.spec.replicas}". Like kubectl's table columns, columns with
+// a Priority above 0 are only printed with -o wide. Format names a column format
+// that converts the value, e.g. age.
+type Column struct {
+	Header   string
+	JSONPath string
+	Priority int32
+	Format   string
+}
+
+// ResourceHandler customizes get and describe for a resource type. Get takes over the
+// whole table; otherwise Columns are printed after the cluster, namespace and name.
+// Describe returns the description of one cluster's objects; without it the describers
+// of kubectl are used. The events of a described object are appended by describe.
+type ResourceHandler struct {
+	// Names are the resource type and its aliases as typed on the command line
+	Names    []string
+	Columns  []Column
+	Get      func(w io.Writer, clusters []cluster.ClusterInfo, opts GetOptions) error
+	Describe func(ctx context.Context, clusterInfo cluster.ClusterInfo, namespace, name string) (string, error)
+}
+
+var (
+	handlersMu    sync.RWMutex
+	handlers      = make(map[string]ResourceHandler)
+	columnFormats = map[string]func(string) string{
+		"age": func(value string) string {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return value
+			}
+			return util.FormatAge(t)
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+)
+
+// RegisterColumnFormat registers a column format that columns select by name
+func RegisterColumnFormat(name string, format func(string) string) error {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	if _, ok := columnFormats[name]; ok {
+		return fmt.Errorf("a column format %s is already registered", name)
+	}
+	columnFormats[name] = format
+	return nil
+}
+
+// RegisterResourceHandler registers a handler for its names. Registered handlers take
+// precedence over the built-in ones, so they can also replace how a built-in resource
+// type is printed. A name can only be registered once.
+func RegisterResourceHandler(handler ResourceHandler) error {
+	if len(handler.Names) == 0 {
+		return fmt.Errorf("resource handler has no names")
+	}
+	if handler.Get == nil && handler.Describe == nil && len(handler.Columns) == 0 {
+		return fmt.Errorf("resource handler for %s has neither columns nor a get or describe function", handler.Names[0])
+	}
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	for _, column := range handler.Columns {
+		if err := jsonpath.New(column.Header).Parse(column.JSONPath); err != nil {
+			return fmt.Errorf("invalid JSONPath for column %s: %v", column.Header, err)
+		}
+		if _, ok := columnFormats[column.Format]; column.Format != "" && !ok {
+			return fmt.Errorf("unknown format %q for column %s", column.Format, column.Header)
+		}
+	}
+	for _, name := range handler.Names {
+		if _, ok := handlers[strings.ToLower(name)]; ok {
+			return fmt.Errorf("a resource handler for %s is already registered", name)
+		}
+	}
+	for _, name := range handler.Names {
+		handlers[strings.ToLower(name)] = handler
+	}
+	return nil
+}
+
+// LookupResourceHandler returns the handler registered for a resource type
+func LookupResourceHandler(resourceType string) (ResourceHandler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	handler, ok := handlers[strings.ToLower(resourceType)]
+	return handler, ok
+}
+
+// ColumnValues returns the cells of the columns for one object. Fields missing from
+// the object are printed as <none>, like kubectl's custom columns.
+func ColumnValues(columns []Column, obj *unstructured.Unstructured) []string {
+	values := make([]string, 0, len(columns))
+	for _, column := range columns {
+		parser := jsonpath.New(column.Header).AllowMissingKeys(true)
+		if err := parser.Parse(column.JSONPath); err != nil {
+			values = append(values, "<error>")
+			continue
+		}
+		var value strings.Builder
+		if err := parser.Execute(&value, obj.Object); err != nil || value.Len() == 0 {
+			values = append(values, "<none>")
+			continue
+		}
+		if format := columnFormat(column.Format); format != nil {
+			values = append(values, format(value.String()))
+			continue
+		}
+		values = append(values, value.String())
+	}
+	return values
+}
+
+// columnFormat returns the registered column format of a name
+func columnFormat(name string) func(string) string {
+	if name == "" {
+		return nil
+	}
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	return columnFormats[name]
+}
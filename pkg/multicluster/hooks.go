@@ -0,0 +1,42 @@
+package multicluster
+
+import (
+	"sync"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// Hooks run around the commands of kubectl multi, e.g. to record metrics or to keep
+// clusters out of a command. Commands are named by their path below kubectl multi,
+// such as "get" or "rollout restart". Unset functions are skipped.
+type Hooks struct {
+	// PreCommand runs before the command; an error stops it
+	PreCommand func(command string, args []string) error
+	// PostCommand runs after the command with the error it returned
+	PostCommand func(command string, args []string, err error)
+	// PreCluster runs for every discovered cluster; an error leaves the cluster out
+	PreCluster func(command string, clusterInfo cluster.ClusterInfo) error
+	// PostCluster runs after the command for every cluster it used, with the first
+	// error of that cluster
+	PostCluster func(command, clusterName string, err error)
+}
+
+var (
+	hooksMu sync.Mutex
+	hooks   []Hooks
+)
+
+// RegisterHooks adds hooks that run for every command, after the hooks registered
+// before them
+func RegisterHooks(h Hooks) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, h)
+}
+
+// RegisteredHooks returns the hooks in the order they were registered
+func RegisteredHooks() []Hooks {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	return append([]Hooks(nil), hooks...)
+}
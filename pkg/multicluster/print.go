@@ -0,0 +1,118 @@
+package multicluster
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes/scheme"
+	kubectlget "k8s.io/kubectl/pkg/cmd/get"
+)
+
+// NewResourcePrinter builds a kubectl printer for the given -o format. The wide format
+// is printed from server-side tables and therefore has no printer of its own.
+func NewResourcePrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	if outputFormat == "wide" {
+		return printers.NewTablePrinter(printers.PrintOptions{Wide: true}), nil
+	}
+
+	if strings.HasPrefix(outputFormat, "custom-columns=") {
+		spec := strings.TrimPrefix(outputFormat, "custom-columns=")
+		printer, err := kubectlget.NewCustomColumnsPrinterFromSpec(spec, unstructured.UnstructuredJSONScheme, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom-columns spec: %v", err)
+		}
+		return printer, nil
+	}
+
+	printFlags := genericclioptions.NewPrintFlags("").WithTypeSetter(scheme.Scheme)
+	printFlags.OutputFormat = &outputFormat
+	printer, err := printFlags.ToPrinter()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported output format %q: %v", outputFormat, err)
+	}
+	return printer, nil
+}
+
+// Print writes objects of several clusters. An empty format prints a table with the
+// cluster of each object. Formats with a registered Printer use it; the other kubectl
+// -o formats print one list in which every object carries its cluster in the
+// ClusterAnnotation.
+func Print(w io.Writer, outputFormat string, objects []Object) error {
+	if outputFormat == "" {
+		outputFormat = "table"
+	}
+	if HasPrinter(outputFormat) {
+		printer, err := NewPrinter(outputFormat, w, PrinterOptions{WithNamespace: true})
+		if err != nil {
+			return err
+		}
+		if err := printer.WriteHeader(); err != nil {
+			return err
+		}
+		for i := range objects {
+			if err := printer.WriteRow(objects[i].Cluster, &objects[i].Unstructured); err != nil {
+				return err
+			}
+		}
+		return printer.Flush()
+	}
+
+	printer, err := NewResourcePrinter(outputFormat)
+	if err != nil {
+		return err
+	}
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+	for _, obj := range objects {
+		list.Items = append(list.Items, *withClusterAnnotation(obj.Cluster, &obj.Unstructured))
+	}
+	return printer.PrintObj(list, w)
+}
+
+// withClusterAnnotation returns a copy of obj annotated with the cluster it was read from
+func withClusterAnnotation(clusterName string, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	item := obj.DeepCopy()
+	annotations := item.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[ClusterAnnotation] = clusterName
+	item.SetAnnotations(annotations)
+	return item
+}
+
+// RedactSecret replaces the values of a secret with their sizes. The last-applied
+// annotation holds the values as well and is redacted too. Other objects are left
+// unchanged.
+func RedactSecret(obj *unstructured.Unstructured) {
+	if obj.GetKind() != "Secret" {
+		return
+	}
+
+	if data, found, _ := unstructured.NestedStringMap(obj.Object, "data"); found {
+		for key, value := range data {
+			size := len(value)
+			if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+				size = len(decoded)
+			}
+			data[key] = fmt.Sprintf("<redacted: %d bytes>", size)
+		}
+		unstructured.SetNestedStringMap(obj.Object, data, "data")
+	}
+	if stringData, found, _ := unstructured.NestedStringMap(obj.Object, "stringData"); found {
+		for key, value := range stringData {
+			stringData[key] = fmt.Sprintf("<redacted: %d bytes>", len(value))
+		}
+		unstructured.SetNestedStringMap(obj.Object, stringData, "stringData")
+	}
+
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+		annotations["kubectl.kubernetes.io/last-applied-configuration"] = "<redacted>"
+		obj.SetAnnotations(annotations)
+	}
+}
@@ -0,0 +1,232 @@
+package multicluster
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"kubectl-multi/pkg/util"
+)
+
+// Printer writes objects of several clusters in one output format. WriteHeader is
+// called once before the rows and Flush once after them; formats that print a whole
+// document, like json, only write on Flush.
+type Printer interface {
+	WriteHeader() error
+	WriteRow(clusterName string, obj *unstructured.Unstructured) error
+	Flush() error
+}
+
+// PrinterOptions select the columns of the row based formats. Rows always start with
+// the cluster and the name; Columns are printed after them, those with a Priority
+// only when Wide is set.
+type PrinterOptions struct {
+	Columns       []Column
+	WithNamespace bool
+	ShowLabels    bool
+	Wide          bool
+	// ShowColumns and HideColumns select the columns of the table format by header
+	ShowColumns []string
+	HideColumns []string
+	// MaxWidth shortens longer cells of the table format in the middle
+	MaxWidth int
+}
+
+// visibleColumns returns the Columns printed with these options
+func (o PrinterOptions) visibleColumns() []Column {
+	if o.Wide {
+		return o.Columns
+	}
+	var columns []Column
+	for _, column := range o.Columns {
+		if column.Priority == 0 {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// PrinterFactory builds a printer writing to w
+type PrinterFactory func(w io.Writer, opts PrinterOptions) Printer
+
+var (
+	printersMu       sync.RWMutex
+	printerFactories = map[string]PrinterFactory{
+		"table": newTablePrinter,
+		"csv":   newCSVPrinter,
+		"json":  func(w io.Writer, _ PrinterOptions) Printer { return newListPrinter(w, &printers.JSONPrinter{}) },
+		"yaml":  func(w io.Writer, _ PrinterOptions) Printer { return newListPrinter(w, &printers.YAMLPrinter{}) },
+	}
+)
+
+// RegisterPrinter adds an output format selectable with -o, or replaces a built-in one
+func RegisterPrinter(outputFormat string, factory PrinterFactory) {
+	printersMu.Lock()
+	defer printersMu.Unlock()
+	printerFactories[outputFormat] = factory
+}
+
+// HasPrinter reports whether a Printer is registered for the output format
+func HasPrinter(outputFormat string) bool {
+	printersMu.RLock()
+	defer printersMu.RUnlock()
+	_, ok := printerFactories[outputFormat]
+	return ok
+}
+
+// PrinterFormats returns the registered output formats, sorted
+func PrinterFormats() []string {
+	printersMu.RLock()
+	defer printersMu.RUnlock()
+	formats := make([]string, 0, len(printerFactories))
+	for format := range printerFactories {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// NewPrinter builds the Printer registered for the output format
+func NewPrinter(outputFormat string, w io.Writer, opts PrinterOptions) (Printer, error) {
+	printersMu.RLock()
+	factory, ok := printerFactories[outputFormat]
+	printersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format %q, expected one of %s", outputFormat, strings.Join(PrinterFormats(), ", "))
+	}
+	return eventPrinter{factory(w, opts)}, nil
+}
+
+// rowHeaders returns the column headers of the row based formats
+func rowHeaders(opts PrinterOptions, last string) []string {
+	headers := []string{"CLUSTER"}
+	if opts.WithNamespace {
+		headers = append(headers, "NAMESPACE")
+	}
+	headers = append(headers, "NAME")
+	for _, column := range opts.visibleColumns() {
+		headers = append(headers, strings.ToUpper(column.Header))
+	}
+	headers = append(headers, last)
+	if opts.ShowLabels {
+		headers = append(headers, "LABELS")
+	}
+	return headers
+}
+
+// rowCells returns the cells of one object, ending with the given last cell
+func rowCells(opts PrinterOptions, clusterName string, obj *unstructured.Unstructured, last string) []string {
+	cells := []string{clusterName}
+	if opts.WithNamespace {
+		cells = append(cells, obj.GetNamespace())
+	}
+	cells = append(cells, obj.GetName())
+	cells = append(cells, ColumnValues(opts.visibleColumns(), obj)...)
+	cells = append(cells, last)
+	if opts.ShowLabels {
+		cells = append(cells, util.FormatLabels(obj.GetLabels()))
+	}
+	return cells
+}
+
+// tablePrinter prints aligned columns ending with the age of each object
+type tablePrinter struct {
+	tw interface {
+		io.Writer
+		Flush() error
+	}
+	opts PrinterOptions
+	rows int
+}
+
+func newTablePrinter(w io.Writer, opts PrinterOptions) Printer {
+	p := &tablePrinter{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0), opts: opts}
+	if len(opts.ShowColumns) > 0 || len(opts.HideColumns) > 0 || opts.MaxWidth > 0 {
+		filter := util.NewColumnFilter(p.tw, opts.ShowColumns, opts.HideColumns)
+		filter.MaxWidth = opts.MaxWidth
+		p.tw = filter
+	}
+	return p
+}
+
+// WriteHeader is deferred to the first row, so an empty result prints only a notice
+func (p *tablePrinter) WriteHeader() error {
+	return nil
+}
+
+func (p *tablePrinter) WriteRow(clusterName string, obj *unstructured.Unstructured) error {
+	if p.rows == 0 {
+		fmt.Fprintln(p.tw, strings.Join(rowHeaders(p.opts, "AGE"), "\t"))
+	}
+	p.rows++
+	age := util.FormatAge(obj.GetCreationTimestamp().Time)
+	_, err := fmt.Fprintln(p.tw, strings.Join(rowCells(p.opts, clusterName, obj, age), "\t"))
+	return err
+}
+
+func (p *tablePrinter) Flush() error {
+	if p.rows == 0 {
+		fmt.Fprintln(p.tw, "No resource found.")
+	}
+	return p.tw.Flush()
+}
+
+// csvPrinter prints RFC 4180 rows ending with the creation time of each object
+type csvPrinter struct {
+	w    *csv.Writer
+	opts PrinterOptions
+}
+
+func newCSVPrinter(w io.Writer, opts PrinterOptions) Printer {
+	return &csvPrinter{w: csv.NewWriter(w), opts: opts}
+}
+
+func (p *csvPrinter) WriteHeader() error {
+	return p.w.Write(rowHeaders(p.opts, "CREATED"))
+}
+
+func (p *csvPrinter) WriteRow(clusterName string, obj *unstructured.Unstructured) error {
+	created := obj.GetCreationTimestamp().UTC().Format(time.RFC3339)
+	return p.w.Write(rowCells(p.opts, clusterName, obj, created))
+}
+
+func (p *csvPrinter) Flush() error {
+	p.w.Flush()
+	return p.w.Error()
+}
+
+// listPrinter collects all objects into one List that is printed on Flush
+type listPrinter struct {
+	w       io.Writer
+	printer printers.ResourcePrinter
+	list    *unstructured.UnstructuredList
+}
+
+func newListPrinter(w io.Writer, printer printers.ResourcePrinter) Printer {
+	return &listPrinter{
+		w:       w,
+		printer: printer,
+		list:    &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}},
+	}
+}
+
+func (p *listPrinter) WriteHeader() error {
+	return nil
+}
+
+func (p *listPrinter) WriteRow(clusterName string, obj *unstructured.Unstructured) error {
+	p.list.Items = append(p.list.Items, *withClusterAnnotation(clusterName, obj))
+	return nil
+}
+
+func (p *listPrinter) Flush() error {
+	return p.printer.PrintObj(p.list, p.w)
+}
@@ -0,0 +1,79 @@
+package multicluster
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// clusterSummary is one entry of the /clusters endpoint
+type clusterSummary struct {
+	Name    string `json:"name"`
+	Context string `json:"context"`
+	Server  string `json:"server,omitempty"`
+}
+
+// serveClusters answers GET /clusters with the clusters of the server
+func (s *Server) serveClusters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	summaries := make([]clusterSummary, 0, len(s.client.Clusters()))
+	for _, clusterInfo := range s.client.Clusters() {
+		summary := clusterSummary{Name: clusterInfo.Name, Context: clusterInfo.Context}
+		if clusterInfo.RestConfig != nil {
+			summary.Server = clusterInfo.RestConfig.Host
+		}
+		summaries = append(summaries, summary)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"clusters": summaries}); err != nil {
+		klog.Warningf("Failed to write response: %v", err)
+	}
+}
+
+// serveClusterResources answers GET /clusters/{cluster}/resources/{resource} with the
+// objects of one cluster
+func (s *Server) serveClusterResources(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/clusters/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] != "resources" || parts[2] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	clusterName, resourceType := parts[0], parts[2]
+	if len(s.clusters([]string{clusterName})) == 0 {
+		http.Error(w, "unknown cluster "+clusterName, http.StatusNotFound)
+		return
+	}
+
+	_, _, opts, err := parseServerRequest(r, resourceType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	objects, failures := s.List(resourceType, []string{clusterName}, opts)
+	writeList(w, objects, failures)
+}
+
+// serveFleet answers GET /fleet/{resource} with the objects of all clusters, merged
+// into one List like kubectl multi get -o json --layout merged
+func (s *Server) serveFleet(w http.ResponseWriter, r *http.Request) {
+	resourceType := strings.TrimPrefix(r.URL.Path, "/fleet/")
+	if resourceType == "" || strings.Contains(resourceType, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	_, clusterNames, opts, err := parseServerRequest(r, resourceType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	objects, failures := s.List(resourceType, clusterNames, opts)
+	writeList(w, objects, failures)
+}
@@ -0,0 +1,521 @@
+package multicluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"kubectl-multi/pkg/cluster"
+	"kubectl-multi/pkg/util"
+)
+
+// syncTimeout bounds how long a new informer may take to fill its cache. Requests
+// arriving later than that for an informer that is still not synced fail at once.
+const syncTimeout = 30 * time.Second
+
+// Server answers list, get and watch requests for all clusters of a client from
+// informer caches. The informer of a cluster, resource and namespace is started by the
+// first request for it and kept running, so later requests are answered without
+// calling the clusters again.
+type Server struct {
+	client *MultiClusterClient
+
+	mu        sync.Mutex
+	informers map[informerKey]*clusterInformer
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// informerKey identifies an informer. The namespace is passed to the cluster, so users
+// that may only read one namespace can be served. Names and selectors are filtered
+// from the cache instead, as an informer per name or selector would never be stopped.
+type informerKey struct {
+	cluster   string
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// clusterInformer is the informer of the objects of one resource in one namespace, or
+// all namespaces, of one cluster. The informer re-lists and reconnects by itself;
+// listeners are told when that starts and stops failing.
+type clusterInformer struct {
+	informer cache.SharedIndexInformer
+	started  time.Time
+	cluster  string
+
+	mu           sync.Mutex
+	failing      bool
+	listeners    map[int]func(WatchEvent)
+	nextListener int
+}
+
+// matches reports whether an object of an informer passes the name and selector of a
+// request; the namespace is already selected by the informer
+func matches(obj *unstructured.Unstructured, opts ServerOptions) bool {
+	if opts.Name != "" && obj.GetName() != opts.Name {
+		return false
+	}
+	return opts.Selector == nil || opts.Selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// ServerOptions filter the objects of a list, get or watch request
+type ServerOptions struct {
+	Namespace     string
+	AllNamespaces bool
+	Selector      labels.Selector
+	Name          string
+	// WatchOnly skips the ADDED events of the objects that exist when a watch starts
+	WatchOnly bool
+}
+
+// WatchEvent is one change of an object in one cluster, or a notice about the watch of
+// a cluster with a Message and no Object
+type WatchEvent struct {
+	Cluster string                     `json:"cluster"`
+	Type    string                     `json:"type"`
+	Object  *unstructured.Unstructured `json:"object,omitempty"`
+	Message string                     `json:"message,omitempty"`
+	// OldObject is the cached version a MODIFIED Object replaced
+	OldObject *unstructured.Unstructured `json:"-"`
+}
+
+const (
+	// WatchError is sent when a cluster cannot be listed or watched. Its informer keeps
+	// retrying, so the cluster stays in the stream.
+	WatchError = "ERROR"
+	// WatchReconnected is sent when a failing cluster was listed or watched again; the
+	// changes missed in between follow as ordinary events
+	WatchReconnected = "RECONNECTED"
+)
+
+// NewServer creates a server for the clusters of client
+func NewServer(client *MultiClusterClient) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		client:    client,
+		informers: make(map[informerKey]*clusterInformer),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Close stops all informers
+func (s *Server) Close() {
+	s.cancel()
+}
+
+// informer returns the synced informer of a resource type in the namespace of opts in
+// one cluster, starting it on first use. An informer that did not sync before ctx
+// is done is returned with the error, as it keeps retrying in the background.
+func (s *Server) informer(ctx context.Context, clusterInfo cluster.ClusterInfo, resourceType string, opts ServerOptions) (*clusterInformer, error) {
+	gvr, namespaced, err := util.NewGVRResolver(resourceType).Resolve(clusterInfo.DiscoveryClient())
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover resource type %s: %v", resourceType, err)
+	}
+
+	key := informerKey{cluster: clusterInfo.Name, gvr: gvr}
+	if namespaced && !opts.AllNamespaces {
+		key.namespace = clusterInfo.TargetNamespace(opts.Namespace)
+	}
+	s.mu.Lock()
+	ci, ok := s.informers[key]
+	if !ok {
+		ci = s.newInformer(clusterInfo, key)
+		s.informers[key] = ci
+		klog.V(1).Infof("Starting informer for %s in cluster %s", gvr.String(), clusterInfo.Name)
+		go ci.informer.Run(s.ctx.Done())
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithDeadline(ctx, ci.started.Add(syncTimeout))
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), ci.informer.HasSynced) {
+		return ci, fmt.Errorf("timed out waiting for %s of cluster %s", gvr.Resource, clusterInfo.Name)
+	}
+	return ci, nil
+}
+
+// newInformer builds the informer of one resource in the namespace of key in one
+// cluster. Its list and watch calls and the errors of its reflector update
+// whether the cluster is failing.
+func (s *Server) newInformer(clusterInfo cluster.ClusterInfo, key informerKey) *clusterInformer {
+	var client dynamic.ResourceInterface = clusterInfo.DynamicClient().Resource(key.gvr)
+	if key.namespace != "" {
+		client = clusterInfo.DynamicClient().Resource(key.gvr).Namespace(key.namespace)
+	}
+	ci := &clusterInformer{
+		started:   time.Now(),
+		cluster:   clusterInfo.Name,
+		listeners: make(map[int]func(WatchEvent)),
+	}
+	ci.informer = cache.NewSharedIndexInformerWithOptions(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				list, err := client.List(s.ctx, options)
+				ci.setFailing(err)
+				return list, err
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				w, err := client.Watch(s.ctx, options)
+				ci.setFailing(err)
+				return w, err
+			},
+		},
+		&unstructured.Unstructured{},
+		cache.SharedIndexInformerOptions{
+			Indexers:          cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+			ObjectDescription: key.gvr.String(),
+		},
+	)
+	// Expired resource versions and broken connections end the watch with an error
+	// before the reflector re-lists
+	ci.informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		cache.DefaultWatchErrorHandler(r, err)
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			ci.setFailing(err)
+		}
+	})
+	return ci
+}
+
+// setFailing records the result of a list or watch call and notifies the listeners
+// when the cluster starts or stops failing
+func (ci *clusterInformer) setFailing(err error) {
+	ci.mu.Lock()
+	if ci.failing == (err != nil) {
+		ci.mu.Unlock()
+		return
+	}
+	ci.failing = err != nil
+	listeners := make([]func(WatchEvent), 0, len(ci.listeners))
+	for _, listener := range ci.listeners {
+		listeners = append(listeners, listener)
+	}
+	ci.mu.Unlock()
+
+	event := WatchEvent{Type: WatchReconnected, Cluster: ci.cluster, Message: "watching again"}
+	if err != nil {
+		event = WatchEvent{Type: WatchError, Cluster: ci.cluster, Message: fmt.Sprintf("%v; retrying", err)}
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// listen calls fn with the notices of the informer until the returned function is
+// called
+func (ci *clusterInformer) listen(fn func(WatchEvent)) func() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	id := ci.nextListener
+	ci.nextListener++
+	ci.listeners[id] = fn
+	return func() {
+		ci.mu.Lock()
+		defer ci.mu.Unlock()
+		delete(ci.listeners, id)
+	}
+}
+
+// List returns the objects of a resource type in the given clusters, or in all
+// clusters when none are given, and the failures of the clusters that could not be
+// read
+func (s *Server) List(resourceType string, clusterNames []string, opts ServerOptions) ([]Object, []cluster.ClusterFailure) {
+	var objects []Object
+	var failures []cluster.ClusterFailure
+	for _, clusterInfo := range s.clusters(clusterNames) {
+		ci, err := s.informer(s.ctx, clusterInfo, resourceType, opts)
+		if err != nil {
+			failures = append(failures, cluster.ClusterFailure{Cluster: clusterInfo.Name, Operation: "list " + resourceType, Err: err})
+			continue
+		}
+		var clusterObjects []Object
+		for _, item := range ci.informer.GetStore().List() {
+			obj, ok := item.(*unstructured.Unstructured)
+			if !ok || !matches(obj, opts) {
+				continue
+			}
+			clusterObjects = append(clusterObjects, Object{Cluster: clusterInfo.Name, Unstructured: *obj.DeepCopy()})
+		}
+		sort.Slice(clusterObjects, func(i, j int) bool {
+			if clusterObjects[i].GetNamespace() != clusterObjects[j].GetNamespace() {
+				return clusterObjects[i].GetNamespace() < clusterObjects[j].GetNamespace()
+			}
+			return clusterObjects[i].GetName() < clusterObjects[j].GetName()
+		})
+		objects = append(objects, clusterObjects...)
+	}
+	return objects, failures
+}
+
+// Watch sends the changes of a resource type in the given clusters, or in all
+// clusters when none are given, until ctx is done. The current objects are sent as
+// ADDED events first unless opts.WatchOnly is set. A cluster that fails is not dropped: a WatchError is sent and
+// the cluster is re-listed and watched again until it answers. Only the clusters that
+// cannot be watched at all are returned as failures.
+func (s *Server) Watch(ctx context.Context, resourceType string, clusterNames []string, opts ServerOptions, send func(WatchEvent)) []cluster.ClusterFailure {
+	events := make(chan WatchEvent, 100)
+	deliver := func(event WatchEvent) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	// Clusters are set up concurrently so one slow to sync does not hold back the others
+	var mu sync.Mutex
+	var failures []cluster.ClusterFailure
+	var stops []func()
+	var wg sync.WaitGroup
+	for _, clusterInfo := range s.clusters(clusterNames) {
+		wg.Add(1)
+		go func(clusterInfo cluster.ClusterInfo) {
+			defer wg.Done()
+			stop, err := s.watchCluster(ctx, clusterInfo, resourceType, opts, deliver)
+			if err != nil {
+				deliver(WatchEvent{Type: WatchError, Cluster: clusterInfo.Name, Message: err.Error()})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, cluster.ClusterFailure{Cluster: clusterInfo.Name, Operation: "watch " + resourceType, Err: err})
+				return
+			}
+			stops = append(stops, stop)
+		}(clusterInfo)
+	}
+
+	for {
+		select {
+		case event := <-events:
+			send(event)
+		case <-ctx.Done():
+			wg.Wait()
+			for _, stop := range stops {
+				stop()
+			}
+			return failures
+		}
+	}
+}
+
+// watchCluster sends the changes of a resource type in one cluster and the notices of
+// its informer to deliver until the returned function is called
+func (s *Server) watchCluster(ctx context.Context, clusterInfo cluster.ClusterInfo, resourceType string, opts ServerOptions, deliver func(WatchEvent)) (func(), error) {
+	ci, err := s.informer(ctx, clusterInfo, resourceType, opts)
+	if ci == nil {
+		return nil, err
+	}
+	if err != nil {
+		// The objects are sent once the informer syncs
+		deliver(WatchEvent{Type: WatchError, Cluster: clusterInfo.Name, Message: fmt.Sprintf("%v; retrying", err)})
+	}
+
+	emit := func(eventType string, item, oldItem interface{}) {
+		if tombstone, ok := item.(cache.DeletedFinalStateUnknown); ok {
+			item = tombstone.Obj
+		}
+		obj, ok := item.(*unstructured.Unstructured)
+		if !ok || !matches(obj, opts) {
+			return
+		}
+		event := WatchEvent{Type: eventType, Cluster: clusterInfo.Name, Object: obj.DeepCopy()}
+		if oldObj, ok := oldItem.(*unstructured.Unstructured); ok {
+			event.OldObject = oldObj.DeepCopy()
+		}
+		deliver(event)
+	}
+	registration, err := ci.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList || !opts.WatchOnly {
+				emit("ADDED", obj, nil)
+			}
+		},
+		UpdateFunc: func(oldObj, obj interface{}) { emit("MODIFIED", obj, oldObj) },
+		DeleteFunc: func(obj interface{}) { emit("DELETED", obj, nil) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	unlisten := ci.listen(deliver)
+	return func() {
+		unlisten()
+		ci.informer.RemoveEventHandler(registration)
+	}, nil
+}
+
+// clusters returns the clusters of the client with the given names, or all clusters
+func (s *Server) clusters(names []string) []cluster.ClusterInfo {
+	if len(names) == 0 {
+		return s.client.Clusters()
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var clusters []cluster.ClusterInfo
+	for _, clusterInfo := range s.client.Clusters() {
+		if wanted[clusterInfo.Name] {
+			clusters = append(clusters, clusterInfo)
+		}
+	}
+	return clusters
+}
+
+// Handler returns the HTTP API of the server:
+//
+//	GET /v1/list?resource=pods&namespace=shop&selector=app=web&clusters=c1,c2
+//	GET /v1/get?resource=deployments&name=nginx
+//	GET /v1/watch?resource=pods&allNamespaces=true
+//
+// list and get answer with one List like kubectl multi get -o json --layout merged,
+// watch streams one JSON WatchEvent per line. The read-only REST endpoints for
+// dashboards are served next to them, see serveClusters.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/list", s.serveList)
+	mux.HandleFunc("/v1/get", s.serveList)
+	mux.HandleFunc("/v1/watch", s.serveWatch)
+	mux.HandleFunc("/clusters", s.serveClusters)
+	mux.HandleFunc("/clusters/", s.serveClusterResources)
+	mux.HandleFunc("/fleet/", s.serveFleet)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request) {
+	resourceType, clusterNames, opts, err := parseServerRequest(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.URL.Path == "/v1/get" && opts.Name == "" {
+		http.Error(w, "the name parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	objects, failures := s.List(resourceType, clusterNames, opts)
+	writeList(w, objects, failures)
+}
+
+func (s *Server) serveWatch(w http.ResponseWriter, r *http.Request) {
+	resourceType, clusterNames, opts, err := parseServerRequest(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	failures := s.Watch(r.Context(), resourceType, clusterNames, opts, func(event WatchEvent) {
+		writeWatchEvent(encoder, event)
+		flusher.Flush()
+	})
+	for _, failure := range failures {
+		klog.Warningf("Watch of %s failed in cluster %s: %v", resourceType, failure.Cluster, failure.Err)
+	}
+}
+
+// writeWatchEvent writes one event of a watch stream with secret values redacted like
+// in writeList. Watch hands out copies of the cached objects, so the object is
+// redacted in place.
+func writeWatchEvent(encoder *json.Encoder, event WatchEvent) {
+	if event.Object != nil {
+		RedactSecret(event.Object)
+	}
+	if err := encoder.Encode(event); err != nil {
+		klog.V(2).Infof("Failed to write watch event: %v", err)
+	}
+}
+
+// parseServerRequest reads the query parameters shared by all endpoints. The resource
+// type is read from the resource parameter unless the path already named it.
+func parseServerRequest(r *http.Request, resourceType string) (string, []string, ServerOptions, error) {
+	if r.Method != http.MethodGet {
+		return "", nil, ServerOptions{}, fmt.Errorf("only GET is supported")
+	}
+	query := r.URL.Query()
+	if resourceType == "" {
+		resourceType = query.Get("resource")
+	}
+	if resourceType == "" {
+		return "", nil, ServerOptions{}, fmt.Errorf("the resource parameter is required")
+	}
+
+	opts := ServerOptions{
+		Namespace:     query.Get("namespace"),
+		AllNamespaces: query.Get("allNamespaces") == "true",
+		Name:          query.Get("name"),
+		WatchOnly:     query.Get("watchOnly") == "true",
+	}
+	if selector := query.Get("selector"); selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return "", nil, ServerOptions{}, fmt.Errorf("invalid selector: %v", err)
+		}
+		opts.Selector = parsed
+	}
+
+	var clusterNames []string
+	if clusters := query.Get("clusters"); clusters != "" {
+		for _, name := range strings.Split(clusters, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				clusterNames = append(clusterNames, name)
+			}
+		}
+	}
+	return resourceType, clusterNames, opts, nil
+}
+
+// writeList writes objects as one List with the cluster of each object in the
+// ClusterAnnotation and secret values redacted. Failed clusters are listed in a
+// failures field next to the items.
+func writeList(w http.ResponseWriter, objects []Object, failures []cluster.ClusterFailure) {
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"metadata":   map[string]interface{}{},
+	}
+	items := make([]interface{}, 0, len(objects))
+	for _, obj := range objects {
+		item := withClusterAnnotation(obj.Cluster, &obj.Unstructured)
+		RedactSecret(item)
+		items = append(items, item.Object)
+	}
+	list["items"] = items
+	if len(failures) > 0 {
+		failed := make([]map[string]string, 0, len(failures))
+		for _, failure := range failures {
+			failed = append(failed, map[string]string{"cluster": failure.Cluster, "error": failure.Err.Error()})
+		}
+		list["failures"] = failed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		klog.Warningf("Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package multicluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// ServerClient reads from a running kubectl multi serve through its HTTP API, so a
+// command is answered from the warm caches of the daemon instead of discovering and
+// listing the clusters itself. The daemon serves JSON over HTTP only; it has no gRPC
+// API.
+type ServerClient struct {
+	base   *url.URL
+	token  string
+	client *http.Client
+}
+
+// NewServerClient returns a client of the daemon at address, such as
+// localhost:8765 or http://localhost:8765, that authenticates with the bearer token
+// of the daemon unless token is empty
+func NewServerClient(address, token string) (*ServerClient, error) {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	base, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server address %q: %v", address, err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("invalid server address %q: only http and https are supported", address)
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	// Watches stream for as long as they run, so requests are bounded by their context
+	return &ServerClient{base: base, token: token, client: &http.Client{}}, nil
+}
+
+// List returns the objects of a resource type in the given clusters of the daemon, or
+// in all its clusters when none are given, and the failures of the clusters the daemon
+// could not read. The error is set when the daemon itself cannot be used.
+func (c *ServerClient) List(ctx context.Context, resourceType string, clusterNames []string, opts ServerOptions) ([]Object, []cluster.ClusterFailure, error) {
+	resp, err := c.get(ctx, "/v1/list", resourceType, clusterNames, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Items    []map[string]interface{} `json:"items"`
+		Failures []struct {
+			Cluster string `json:"cluster"`
+			Error   string `json:"error"`
+		} `json:"failures"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, nil, fmt.Errorf("failed to read the response of the server: %v", err)
+	}
+
+	objects := make([]Object, 0, len(list.Items))
+	for _, item := range list.Items {
+		obj := unstructured.Unstructured{Object: item}
+		objects = append(objects, Object{Cluster: withoutClusterAnnotation(&obj), Unstructured: obj})
+	}
+	var failures []cluster.ClusterFailure
+	for _, failure := range list.Failures {
+		failures = append(failures, cluster.ClusterFailure{Cluster: failure.Cluster, Operation: "list " + resourceType, Err: fmt.Errorf("%s", failure.Error)})
+	}
+	return objects, failures, nil
+}
+
+// Watch sends the events the daemon streams for a resource type, like Server.Watch,
+// until ctx is done or the daemon ends the stream. The OldObject of MODIFIED events is
+// not sent by the daemon and is always nil.
+func (c *ServerClient) Watch(ctx context.Context, resourceType string, clusterNames []string, opts ServerOptions, send func(WatchEvent)) error {
+	resp, err := c.get(ctx, "/v1/watch", resourceType, clusterNames, opts)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event WatchEvent
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read the watch stream of the server: %v", err)
+		}
+		send(event)
+	}
+}
+
+// get sends a request to an endpoint of the daemon and returns the response when it
+// succeeded
+func (c *ServerClient) get(ctx context.Context, path, resourceType string, clusterNames []string, opts ServerOptions) (*http.Response, error) {
+	query := url.Values{"resource": {resourceType}}
+	if opts.Namespace != "" {
+		query.Set("namespace", opts.Namespace)
+	}
+	if opts.AllNamespaces {
+		query.Set("allNamespaces", "true")
+	}
+	if opts.Name != "" {
+		query.Set("name", opts.Name)
+	}
+	if opts.WatchOnly {
+		query.Set("watchOnly", "true")
+	}
+	if opts.Selector != nil && !opts.Selector.Empty() {
+		query.Set("selector", opts.Selector.String())
+	}
+	if len(clusterNames) > 0 {
+		query.Set("clusters", strings.Join(clusterNames, ","))
+	}
+
+	endpoint := *c.base
+	endpoint.Path += path
+	endpoint.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the server at %s: %v", c.base, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("the server at %s answered %s: %s", c.base, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// withoutClusterAnnotation removes the ClusterAnnotation the daemon adds to listed
+// objects and returns the cluster it named
+func withoutClusterAnnotation(obj *unstructured.Unstructured) string {
+	annotations := obj.GetAnnotations()
+	clusterName, ok := annotations[ClusterAnnotation]
+	if !ok {
+		return ""
+	}
+	delete(annotations, ClusterAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return clusterName
+}
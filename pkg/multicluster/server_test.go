@@ -0,0 +1,104 @@
+package multicluster
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"kubectl-multi/pkg/cluster"
+)
+
+// TestServeWatchRedactsSecrets ensures the watch endpoint masks secret values like the
+// list endpoint does
+func TestServeWatchRedactsSecrets(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db", "namespace": "default"},
+		"data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+	}}
+	secretsGVR := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{secretsGVR: "SecretList"}, secret)
+
+	server := NewServer(&MultiClusterClient{clusters: []cluster.ClusterInfo{
+		cluster.NewClusterInfo("cluster1", "cluster1", nil, dyn, nil, nil),
+	}})
+	defer server.Close()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/v1/watch?resource=secrets&namespace=default", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	if !scanner.Scan() {
+		t.Fatalf("no watch event received: %v", scanner.Err())
+	}
+	var event WatchEvent
+	if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+		t.Fatalf("failed to decode watch event %s: %v", scanner.Text(), err)
+	}
+	if event.Object == nil {
+		t.Fatalf("expected an object in the first event, got %s", scanner.Text())
+	}
+	password, _, _ := unstructured.NestedString(event.Object.Object, "data", "password")
+	if password != "<redacted: 7 bytes>" {
+		t.Errorf("expected the password to be redacted, got %q", password)
+	}
+}
+
+// TestProtect ensures the server only answers requests with the bearer token and a
+// known Host header, and leaves /healthz open to probes
+func TestProtect(t *testing.T) {
+	handler := Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "secret", []string{"localhost:8765"})
+
+	tests := []struct {
+		name   string
+		path   string
+		host   string
+		auth   string
+		status int
+	}{
+		{name: "token", path: "/clusters", host: "localhost:8765", auth: "Bearer secret", status: http.StatusOK},
+		{name: "no token", path: "/clusters", host: "localhost:8765", status: http.StatusUnauthorized},
+		{name: "wrong token", path: "/clusters", host: "localhost:8765", auth: "Bearer guess", status: http.StatusUnauthorized},
+		{name: "basic auth", path: "/clusters", host: "localhost:8765", auth: "Basic secret", status: http.StatusUnauthorized},
+		{name: "rebound host", path: "/clusters", host: "attacker.example:8765", auth: "Bearer secret", status: http.StatusForbidden},
+		{name: "healthz", path: "/healthz", host: "localhost:8765", status: http.StatusOK},
+		{name: "healthz of other host", path: "/healthz", host: "attacker.example:8765", status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			if recorder.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColumnFilter removes columns from tab separated tables before they are aligned by a
+// tabwriter. A line starting with CLUSTER is a header and selects the columns of the
+// rows after it; lines without tabs pass unchanged. The CLUSTER column is always kept.
+type ColumnFilter struct {
+	// MaxWidth shortens longer cells with TruncateMiddle; 0 keeps them whole
+	MaxWidth int
+
+	out  io.Writer
+	show map[string]bool
+	hide map[string]bool
+	keep []bool
+	line []byte
+}
+
+// NewColumnFilter writes the tables written to it to out, with only the show columns
+// when any are given and without the hide columns. Column names are matched
+// case-insensitively against the headers.
+func NewColumnFilter(out io.Writer, show, hide []string) *ColumnFilter {
+	return &ColumnFilter{out: out, show: columnSet(show), hide: columnSet(hide)}
+}
+
+func columnSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			set[strings.ToUpper(name)] = true
+		}
+	}
+	return set
+}
+
+func (f *ColumnFilter) Write(p []byte) (int, error) {
+	f.line = append(f.line, p...)
+	for {
+		i := bytes.IndexByte(f.line, '\n')
+		if i < 0 {
+			break
+		}
+		if err := f.writeLine(string(f.line[:i+1])); err != nil {
+			return 0, err
+		}
+		f.line = f.line[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a pending line without newline and flushes out when it buffers, like a
+// tabwriter does
+func (f *ColumnFilter) Flush() error {
+	if len(f.line) > 0 {
+		if err := f.writeLine(string(f.line)); err != nil {
+			return err
+		}
+		f.line = nil
+	}
+	if flusher, ok := f.out.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+func (f *ColumnFilter) writeLine(line string) error {
+	text := strings.TrimSuffix(line, "\n")
+	cells := strings.Split(text, "\t")
+	if len(cells) < 2 {
+		_, err := io.WriteString(f.out, line)
+		return err
+	}
+
+	if cells[0] == "CLUSTER" {
+		f.keep = make([]bool, len(cells))
+		for i, header := range cells {
+			header = strings.ToUpper(header)
+			f.keep[i] = i == 0 || ((len(f.show) == 0 || f.show[header]) && !f.hide[header])
+		}
+	}
+
+	kept := make([]string, 0, len(cells))
+	for i, cell := range cells {
+		// Cells beyond the header, e.g. of a later table without header, are kept
+		if f.keep == nil || i >= len(f.keep) || f.keep[i] {
+			kept = append(kept, TruncateMiddle(cell, f.MaxWidth))
+		}
+	}
+	_, err := io.WriteString(f.out, strings.Join(kept, "\t")+line[len(text):])
+	return err
+}
+
+// StreamTable keeps the columns of a table aligned across flushes of the tabwriter it
+// writes to. A tabwriter only aligns the rows since its last flush, so a table printed
+// a cluster at a time would shift its columns per cluster; StreamTable pads the cells
+// to the widest cell of their column since the last CLUSTER header instead.
+type StreamTable struct {
+	out    TableFlusher
+	widths []int
+	block  []int
+	line   []byte
+}
+
+// TableFlusher is a writer aligning tab separated rows, such as a tabwriter
+type TableFlusher interface {
+	io.Writer
+	Flush() error
+}
+
+// NewStreamTable returns a StreamTable writing to the tabwriter out
+func NewStreamTable(out TableFlusher) *StreamTable {
+	return &StreamTable{out: out}
+}
+
+func (s *StreamTable) Write(p []byte) (int, error) {
+	s.line = append(s.line, p...)
+	for {
+		i := bytes.IndexByte(s.line, '\n')
+		if i < 0 {
+			break
+		}
+		if err := s.writeLine(string(s.line[:i+1])); err != nil {
+			return 0, err
+		}
+		s.line = s.line[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a pending line without newline, flushes the tabwriter and keeps the
+// widths of the flushed columns for the rows after
+func (s *StreamTable) Flush() error {
+	if len(s.line) > 0 {
+		if err := s.writeLine(string(s.line)); err != nil {
+			return err
+		}
+		s.line = nil
+	}
+	for i, width := range s.block {
+		if i >= len(s.widths) {
+			s.widths = append(s.widths, width)
+		} else if width > s.widths[i] {
+			s.widths[i] = width
+		}
+	}
+	s.block = nil
+	return s.out.Flush()
+}
+
+func (s *StreamTable) writeLine(line string) error {
+	text := strings.TrimSuffix(line, "\n")
+	cells := strings.Split(text, "\t")
+	if len(cells) < 2 {
+		_, err := io.WriteString(s.out, line)
+		return err
+	}
+	if cells[0] == "CLUSTER" {
+		s.widths = nil
+	}
+
+	// The last cell is not aligned by a tabwriter, so it is not padded either
+	for i, cell := range cells[:len(cells)-1] {
+		width := utf8.RuneCountInString(cell)
+		if i < len(s.widths) && width < s.widths[i] {
+			cells[i] = cell + strings.Repeat(" ", s.widths[i]-width)
+			width = s.widths[i]
+		}
+		if i >= len(s.block) {
+			s.block = append(s.block, width)
+		} else if width > s.block[i] {
+			s.block[i] = width
+		}
+	}
+	_, err := io.WriteString(s.out, strings.Join(cells, "\t")+line[len(text):])
+	return err
+}
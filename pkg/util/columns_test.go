@@ -0,0 +1,76 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"text/tabwriter"
+)
+
+// TestColumnFilter ensures columns are selected per header and CLUSTER is always kept
+func TestColumnFilter(t *testing.T) {
+	tests := []struct {
+		show []string
+		hide []string
+		want string
+	}{
+		{nil, nil, "CLUSTER\tNAME\tSTATUS\tAGE\nc1\tnginx\tRunning\t3d\nNo resource found.\n"},
+		{[]string{"name", "AGE"}, nil, "CLUSTER\tNAME\tAGE\nc1\tnginx\t3d\nNo resource found.\n"},
+		{nil, []string{"STATUS"}, "CLUSTER\tNAME\tAGE\nc1\tnginx\t3d\nNo resource found.\n"},
+		{[]string{"STATUS"}, []string{"CLUSTER"}, "CLUSTER\tSTATUS\nc1\tRunning\nNo resource found.\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		f := NewColumnFilter(&out, tt.show, tt.hide)
+		fmt.Fprintf(f, "CLUSTER\tNAME\tSTATUS\tAGE\n")
+		fmt.Fprintf(f, "c1\tnginx\tRunning\t3d\nNo resource ")
+		fmt.Fprintf(f, "found.\n")
+		if err := f.Flush(); err != nil {
+			t.Fatalf("Flush() returned error: %v", err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("show %v hide %v: got %q, want %q", tt.show, tt.hide, out.String(), tt.want)
+		}
+	}
+}
+
+// TestColumnFilterMaxWidth ensures long cells are shortened in the middle
+func TestColumnFilterMaxWidth(t *testing.T) {
+	var out bytes.Buffer
+	f := NewColumnFilter(&out, nil, nil)
+	f.MaxWidth = 13
+	fmt.Fprintf(f, "CLUSTER\tIMAGE\nc1\tregistry.example.com/team/app:v1.2.3\nc2\tnginx:1.25\n")
+	if err := f.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+	want := "CLUSTER\tIMAGE\nc1\tregis...1.2.3\nc2\tnginx:1.25\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+// TestStreamTable ensures rows flushed a cluster at a time stay aligned with the
+// columns printed before, and a new header starts a new table
+func TestStreamTable(t *testing.T) {
+	var out bytes.Buffer
+	s := NewStreamTable(tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0))
+	fmt.Fprintf(s, "CLUSTER\tNAME\tSTATUS\n")
+	fmt.Fprintf(s, "cluster1\tnginx-7d9c\tRunning\n")
+	s.Flush()
+	fmt.Fprintf(s, "c2\tweb\tPending\n")
+	s.Flush()
+	fmt.Fprintf(s, "CLUSTER\tNAME\n")
+	fmt.Fprintf(s, "c3\tdb\n")
+	s.Flush()
+
+	want := "" +
+		"CLUSTER   NAME        STATUS\n" +
+		"cluster1  nginx-7d9c  Running\n" +
+		"c2        web         Pending\n" +
+		"CLUSTER  NAME\n" +
+		"c3       db\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
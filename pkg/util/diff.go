@@ -0,0 +1,149 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffLine is a line of an edit script: ' ' kept, '-' removed from a, '+' added in b
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns the unified diff of the lines of a and b with the given lines of
+// context, as diff -u prints it, or an empty string when they are equal. It computes a
+// longest common subsequence, which is fine for the size of describe outputs and
+// manifests but quadratic in the number of lines.
+func UnifiedDiff(aName, bName, a, b string, context int) string {
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+	script := editScript(aLines, bLines)
+
+	var out strings.Builder
+	for start := 0; start < len(script); {
+		// Find the next change and the end of its hunk, which runs until more than
+		// two contexts of kept lines separate it from the next change
+		first := start
+		for first < len(script) && script[first].op == ' ' {
+			first++
+		}
+		if first == len(script) {
+			break
+		}
+		end := first
+		for i := first; i < len(script); i++ {
+			if script[i].op != ' ' {
+				end = i + 1
+			} else if i-end >= 2*context {
+				break
+			}
+		}
+		from := first - context
+		if from < start {
+			from = start
+		}
+		to := end + context
+		if to > len(script) {
+			to = len(script)
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+		}
+		aStart, bStart := hunkStart(script, from)
+		aCount, bCount := 0, 0
+		for _, line := range script[from:to] {
+			if line.op != '+' {
+				aCount++
+			}
+			if line.op != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, line := range script[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", line.op, line.text)
+		}
+		start = to
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// editScript returns the lines of a and b as kept, removed and added lines
+func editScript(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var script []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			script = append(script, diffLine{'-', a[i]})
+			i++
+		default:
+			script = append(script, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		script = append(script, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		script = append(script, diffLine{'+', b[j]})
+	}
+	return script
+}
+
+// hunkStart returns the 1-based line numbers in a and b of the script line at index
+func hunkStart(script []diffLine, index int) (int, int) {
+	aLine, bLine := 1, 1
+	for _, line := range script[:index] {
+		if line.op != '+' {
+			aLine++
+		}
+		if line.op != '-' {
+			bLine++
+		}
+	}
+	return aLine, bLine
+}
+
+// hunkRange formats the range of a hunk like diff -u, where an empty range starts
+// before its first line
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
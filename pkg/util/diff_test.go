@@ -0,0 +1,35 @@
+package util
+
+import "testing"
+
+// TestUnifiedDiff ensures the diff matches the hunks diff -u prints
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", 3, ""},
+		{
+			"changed line",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n",
+			3,
+			"--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			1,
+			"--- a\n+++ b\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -9,2 +9,2 @@\n 9\n-10\n+ten\n",
+		},
+		{"added to empty", "", "x\n", 3, "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n"},
+	}
+	for _, tt := range tests {
+		if got := UnifiedDiff("a", "b", tt.a, tt.b, tt.context); got != tt.want {
+			t.Errorf("%s: UnifiedDiff() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
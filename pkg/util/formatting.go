@@ -2,21 +2,43 @@ package util
 
 import (
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
 )
 
-// GetOutputStream returns the output stream (stdout)
-func GetOutputStream() *os.File {
+// GetOutputStream returns the output stream (stdout), coloring status values when a
+// theme is active
+func GetOutputStream() io.Writer {
+	if activeTheme != nil {
+		return colorWriter{out: os.Stdout}
+	}
 	return os.Stdout
 }
 
+// FormatAge returns the time since t as a compact duration like kubectl, e.g. 5d3h,
+// 92m or 12s, and <unknown> for unset times
+func FormatAge(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t))
+}
+
 // GetNodeStatus returns the status of a node
 func GetNodeStatus(node corev1.Node) string {
 	for _, condition := range node.Status.Conditions {
@@ -64,6 +86,218 @@ func GetPodRestarts(pod *corev1.Pod) int32 {
 	return restarts
 }
 
+// FormatReady returns the READY column of a workload as ready/desired, e.g. 2/3. Unset
+// desired replicas default to 1, like the API server does.
+func FormatReady(ready int32, desired *int32) string {
+	want := int32(1)
+	if desired != nil {
+		want = *desired
+	}
+	return fmt.Sprintf("%d/%d", ready, want)
+}
+
+// GetPodStatus returns the status of a pod as kubectl prints it, e.g. Running,
+// CrashLoopBackOff, Init:1/2, Completed, Evicted or Terminating
+func GetPodStatus(pod *corev1.Pod) string {
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, status := range pod.Status.InitContainerStatuses {
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode == 0:
+			continue
+		case status.State.Terminated != nil:
+			if status.State.Terminated.Reason != "" {
+				reason = "Init:" + status.State.Terminated.Reason
+			} else if status.State.Terminated.Signal != 0 {
+				reason = fmt.Sprintf("Init:Signal:%d", status.State.Terminated.Signal)
+			} else {
+				reason = fmt.Sprintf("Init:ExitCode:%d", status.State.Terminated.ExitCode)
+			}
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + status.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		hasRunning := false
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			status := pod.Status.ContainerStatuses[i]
+			switch {
+			case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+				reason = status.State.Waiting.Reason
+			case status.State.Terminated != nil && status.State.Terminated.Reason != "":
+				reason = status.State.Terminated.Reason
+			case status.State.Terminated != nil && status.State.Terminated.Signal != 0:
+				reason = fmt.Sprintf("Signal:%d", status.State.Terminated.Signal)
+			case status.State.Terminated != nil:
+				reason = fmt.Sprintf("ExitCode:%d", status.State.Terminated.ExitCode)
+			case status.Ready && status.State.Running != nil:
+				hasRunning = true
+			}
+		}
+		// A pod whose containers all completed but one is still running is Running
+		if reason == "Completed" && hasRunning {
+			reason = "Running"
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.PodReady && condition.Status != corev1.ConditionTrue {
+					reason = "NotReady"
+				}
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		if pod.Status.Reason == "NodeLost" {
+			return "Unknown"
+		}
+		return "Terminating"
+	}
+	return reason
+}
+
+// maxListedEndpoints is the number of addresses printed before the rest are counted
+const maxListedEndpoints = 3
+
+// FormatEndpoints returns the ready addresses of endpoints like kubectl, listing the
+// first three and counting the rest, e.g. "10.0.0.1:80,10.0.0.2:80,10.0.0.3:80 + 5 more..."
+func FormatEndpoints(ep *corev1.Endpoints) string {
+	var addresses []string
+	for _, subset := range ep.Subsets {
+		// Headless services may have no ports
+		if len(subset.Ports) == 0 {
+			for _, addr := range subset.Addresses {
+				addresses = append(addresses, addr.IP)
+			}
+			continue
+		}
+		for _, port := range subset.Ports {
+			for _, addr := range subset.Addresses {
+				addresses = append(addresses, net.JoinHostPort(addr.IP, strconv.Itoa(int(port.Port))))
+			}
+		}
+	}
+	return truncateList(addresses, "<none>")
+}
+
+// FormatEndpointSlicePorts returns the ports of an endpoint slice like kubectl
+func FormatEndpointSlicePorts(slice *discoveryv1.EndpointSlice) string {
+	if len(slice.Ports) == 0 {
+		return "<unset>"
+	}
+	ports := make([]string, 0, len(slice.Ports))
+	for _, port := range slice.Ports {
+		if port.Port == nil {
+			ports = append(ports, "<unset>")
+			continue
+		}
+		ports = append(ports, strconv.Itoa(int(*port.Port)))
+	}
+	return strings.Join(ports, ",")
+}
+
+// FormatEndpointSliceAddresses returns the addresses of an endpoint slice, truncated
+// like FormatEndpoints
+func FormatEndpointSliceAddresses(slice *discoveryv1.EndpointSlice) string {
+	var addresses []string
+	for _, endpoint := range slice.Endpoints {
+		addresses = append(addresses, endpoint.Addresses...)
+	}
+	return truncateList(addresses, "<unset>")
+}
+
+// truncateList joins the first maxListedEndpoints values and counts the others
+func truncateList(values []string, empty string) string {
+	if len(values) == 0 {
+		return empty
+	}
+	if len(values) <= maxListedEndpoints {
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprintf("%s + %d more...", strings.Join(values[:maxListedEndpoints], ","), len(values)-maxListedEndpoints)
+}
+
+// GetEventLastSeen returns when an event was last observed, preferring the series of
+// events.k8s.io style events over the legacy timestamps
+func GetEventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.EventTime.Time
+}
+
+// GetEventCount returns how often an event occurred, merging the count of event series
+func GetEventCount(event *corev1.Event) int32 {
+	if event.Series != nil {
+		return event.Series.Count
+	}
+	if event.Count == 0 {
+		return 1
+	}
+	return event.Count
+}
+
+// GetEventSource returns the component and host that reported an event like kubectl,
+// e.g. "kubelet, node-1"
+func GetEventSource(event *corev1.Event) string {
+	component := event.Source.Component
+	if component == "" {
+		component = event.ReportingController
+	}
+	host := event.Source.Host
+	if host == "" {
+		host = event.ReportingInstance
+	}
+	if host == "" {
+		if component == "" {
+			return "<none>"
+		}
+		return component
+	}
+	return component + ", " + host
+}
+
+// Truncate shortens text to width characters, ending it with "..." when it was cut.
+// A width of 0 or less leaves text unchanged.
+func Truncate(text string, width int) string {
+	runes := []rune(text)
+	if width <= 0 || len(runes) <= width {
+		return text
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// TruncateMiddle shortens text to width characters by replacing its middle with
+// "...", which keeps the registry and the tag of image names readable. A width of 0
+// or less leaves text unchanged.
+func TruncateMiddle(text string, width int) string {
+	runes := []rune(text)
+	if width <= 0 || len(runes) <= width {
+		return text
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	head := (width - 3 + 1) / 2
+	tail := width - 3 - head
+	return string(runes[:head]) + "..." + string(runes[len(runes)-tail:])
+}
+
 // GetServiceExternalIP returns the external IP of a service
 func GetServiceExternalIP(svc *corev1.Service) string {
 	if len(svc.Status.LoadBalancer.Ingress) > 0 {
@@ -81,6 +315,27 @@ func GetServiceExternalIP(svc *corev1.Service) string {
 	return "<none>"
 }
 
+// GetIngressClass returns the ingress class of an ingress, falling back to the
+// deprecated kubernetes.io/ingress.class annotation
+func GetIngressClass(ing *networkingv1.Ingress) string {
+	if ing.Spec.IngressClassName != nil && *ing.Spec.IngressClassName != "" {
+		return *ing.Spec.IngressClassName
+	}
+	if class := ing.Annotations["kubernetes.io/ingress.class"]; class != "" {
+		return class
+	}
+	return "<none>"
+}
+
+// GetIngressPorts returns the ports an ingress is served on like kubectl: 80, and
+// 443 as well when it terminates TLS
+func GetIngressPorts(ing *networkingv1.Ingress) string {
+	if len(ing.Spec.TLS) > 0 {
+		return "80, 443"
+	}
+	return "80"
+}
+
 // GetServicePorts returns the ports of a service formatted as a string
 func GetServicePorts(svc *corev1.Service) string {
 	var ports []string
@@ -191,118 +446,46 @@ func GetPVCStorageClass(pvc *corev1.PersistentVolumeClaim) string {
 	return "<none>"
 }
 
-// DiscoverGVR discovers the GroupVersionResource for a given resource type
+// DiscoverGVR discovers the GroupVersionResource for a given resource type. The type is
+// resolved like kubectl does it: plural, singular and short names of every resource
+// the cluster serves are accepted, optionally qualified as resource.group or
+// resource.version.group.
 func DiscoverGVR(discoveryClient discovery.DiscoveryInterface, resourceType string) (schema.GroupVersionResource, bool, error) {
-	// Get all API resources
-	_, apiResourceLists, err := discoveryClient.ServerGroupsAndResources()
-	if err != nil {
-		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to discover API resources: %v", err)
-	}
+	mapper := NewRESTMapper(discoveryClient)
 
-	// Normalize the resource type (handle plurals and common aliases)
-	normalizedType := normalizeResourceType(resourceType)
-
-	// Search through all API resources
-	for _, apiResourceList := range apiResourceLists {
-		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
-		if err != nil {
-			continue
-		}
-
-		for _, apiResource := range apiResourceList.APIResources {
-			// Check if this matches our resource type
-			if matchesResourceType(apiResource, normalizedType) {
-				gvr := gv.WithResource(apiResource.Name)
-				return gvr, apiResource.Namespaced, nil
-			}
-		}
+	var gvr schema.GroupVersionResource
+	var err error
+	fullySpecifiedGVR, groupResource := schema.ParseResourceArg(strings.ToLower(resourceType))
+	if fullySpecifiedGVR != nil {
+		gvr, err = mapper.ResourceFor(*fullySpecifiedGVR)
 	}
-
-	// If not found, try some common defaults
-	return getDefaultGVR(normalizedType), true, nil
-}
-
-// normalizeResourceType converts common resource type aliases to standard forms
-func normalizeResourceType(resourceType string) string {
-	aliases := map[string]string{
-		"po":     "pods",
-		"svc":    "services",
-		"no":     "nodes",
-		"ns":     "namespaces",
-		"pv":     "persistentvolumes",
-		"pvc":    "persistentvolumeclaims",
-		"cm":     "configmaps",
-		"deploy": "deployments",
-		"rs":     "replicasets",
-		"ds":     "daemonsets",
-		"sts":    "statefulsets",
-		"job":    "jobs",
-		"cj":     "cronjobs",
-		"ing":    "ingresses",
-		"ep":     "endpoints",
-		"sa":     "serviceaccounts",
-	}
-
-	if normalized, exists := aliases[strings.ToLower(resourceType)]; exists {
-		return normalized
-	}
-
-	// Ensure it's lowercase and plural
-	lower := strings.ToLower(resourceType)
-	if !strings.HasSuffix(lower, "s") {
-		lower += "s"
-	}
-	return lower
-}
-
-// matchesResourceType checks if an API resource matches the given resource type
-func matchesResourceType(apiResource metav1.APIResource, resourceType string) bool {
-	// Check exact match with name
-	if strings.EqualFold(apiResource.Name, resourceType) {
-		return true
+	if fullySpecifiedGVR == nil || err != nil {
+		gvr, err = mapper.ResourceFor(groupResource.WithVersion(""))
 	}
-
-	// Check singular name
-	if strings.EqualFold(apiResource.SingularName, resourceType) {
-		return true
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to resolve resource type %s: %v", resourceType, err)
 	}
 
-	// Check short names
-	for _, shortName := range apiResource.ShortNames {
-		if strings.EqualFold(shortName, resourceType) {
-			return true
-		}
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to resolve kind of %s: %v", gvr.String(), err)
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to resolve mapping of %s: %v", gvk.String(), err)
 	}
 
-	return false
+	return gvr, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
 }
 
-// getDefaultGVR returns a default GVR for common resource types
-func getDefaultGVR(resourceType string) schema.GroupVersionResource {
-	defaults := map[string]schema.GroupVersionResource{
-		"pods":                   {Group: "", Version: "v1", Resource: "pods"},
-		"services":               {Group: "", Version: "v1", Resource: "services"},
-		"nodes":                  {Group: "", Version: "v1", Resource: "nodes"},
-		"namespaces":             {Group: "", Version: "v1", Resource: "namespaces"},
-		"persistentvolumes":      {Group: "", Version: "v1", Resource: "persistentvolumes"},
-		"persistentvolumeclaims": {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
-		"configmaps":             {Group: "", Version: "v1", Resource: "configmaps"},
-		"secrets":                {Group: "", Version: "v1", Resource: "secrets"},
-		"deployments":            {Group: "apps", Version: "v1", Resource: "deployments"},
-		"replicasets":            {Group: "apps", Version: "v1", Resource: "replicasets"},
-		"daemonsets":             {Group: "apps", Version: "v1", Resource: "daemonsets"},
-		"statefulsets":           {Group: "apps", Version: "v1", Resource: "statefulsets"},
-		"jobs":                   {Group: "batch", Version: "v1", Resource: "jobs"},
-		"cronjobs":               {Group: "batch", Version: "v1", Resource: "cronjobs"},
-		"ingresses":              {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
-		"endpoints":              {Group: "", Version: "v1", Resource: "endpoints"},
-		"serviceaccounts":        {Group: "", Version: "v1", Resource: "serviceaccounts"},
-	}
-
-	if gvr, exists := defaults[resourceType]; exists {
-		return gvr
-	}
-
-	// Default fallback
-	return schema.GroupVersionResource{Group: "", Version: "v1", Resource: resourceType}
+// NewRESTMapper returns a discovery-backed RESTMapper that also expands short names.
+// Discovery results are cached for the lifetime of the mapper, and a stale on-disk
+// cache is refreshed automatically when a resource cannot be found.
+func NewRESTMapper(discoveryClient discovery.DiscoveryInterface) meta.RESTMapper {
+	cached, ok := discoveryClient.(discovery.CachedDiscoveryInterface)
+	if !ok {
+		cached = memory.NewMemCacheClient(discoveryClient)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(cached)
+	return restmapper.NewShortcutExpander(mapper, cached, nil)
 }
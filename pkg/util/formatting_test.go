@@ -0,0 +1,57 @@
+package util
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestGetPodStatus ensures pod statuses follow the reasons kubectl prints
+func TestGetPodStatus(t *testing.T) {
+	waiting := func(reason string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+	}
+	terminated := func(reason string, exitCode int32) corev1.ContainerStatus {
+		return corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode}}}
+	}
+	now := metav1.Now()
+
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{"phase", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}}, "Running"},
+		{"evicted", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}, "Evicted"},
+		{"crash loop", corev1.Pod{Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{waiting("CrashLoopBackOff")},
+		}}, "CrashLoopBackOff"},
+		{"completed", corev1.Pod{Status: corev1.PodStatus{
+			Phase:             corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{terminated("Completed", 0)},
+		}}, "Completed"},
+		{"init progress", corev1.Pod{
+			Spec: corev1.PodSpec{InitContainers: []corev1.Container{{Name: "a"}, {Name: "b"}}},
+			Status: corev1.PodStatus{
+				Phase:                 corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{terminated("Completed", 0), waiting("PodInitializing")},
+			},
+		}, "Init:1/2"},
+		{"init image pull", corev1.Pod{
+			Spec:   corev1.PodSpec{InitContainers: []corev1.Container{{Name: "a"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{waiting("ImagePullBackOff")}},
+		}, "Init:ImagePullBackOff"},
+		{"terminating", corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}, "Terminating"},
+	}
+
+	for _, tt := range tests {
+		if got := GetPodStatus(&tt.pod); got != tt.want {
+			t.Errorf("%s: GetPodStatus() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
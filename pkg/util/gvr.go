@@ -0,0 +1,123 @@
+package util
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+)
+
+// builtinResource is a built-in Kubernetes resource whose GVR is stable across clusters
+type builtinResource struct {
+	GVR        schema.GroupVersionResource
+	Namespaced bool
+	Aliases    []string // singular and short names
+}
+
+// builtinResources can be resolved without a discovery round-trip
+var builtinResources = []builtinResource{
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}, true, []string{"pod", "po"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}, true, []string{"service", "svc"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}, true, []string{"configmap", "cm"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}, true, []string{"secret"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "serviceaccounts"}, true, []string{"serviceaccount", "sa"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "endpoints"}, true, []string{"ep"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "events"}, true, []string{"event", "ev"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumeclaims"}, true, []string{"persistentvolumeclaim", "pvc"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "resourcequotas"}, true, []string{"resourcequota", "quota"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "limitranges"}, true, []string{"limitrange", "limits"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}, false, []string{"node", "no"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}, false, []string{"namespace", "ns"}},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumes"}, false, []string{"persistentvolume", "pv"}},
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, true, []string{"deployment", "deploy"}},
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}, true, []string{"replicaset", "rs"}},
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, true, []string{"statefulset", "sts"}},
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, true, []string{"daemonset", "ds"}},
+	{schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}, true, []string{"job"}},
+	{schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}, true, []string{"cronjob", "cj"}},
+	{schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}, true, []string{"endpointslice"}},
+	{schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}, true, []string{"ingress", "ing"}},
+	{schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}, true, []string{"networkpolicy", "netpol"}},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"}, true, []string{"role"}},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}, true, []string{"rolebinding"}},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}, false, []string{"clusterrole"}},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}, false, []string{"clusterrolebinding"}},
+	{schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}, false, []string{"storageclass", "sc"}},
+	{schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}, true, []string{"poddisruptionbudget", "pdb"}},
+}
+
+// builtinResourceIndex maps the plural, singular and short names of builtinResources
+// to their entry
+var builtinResourceIndex = func() map[string]builtinResource {
+	index := make(map[string]builtinResource)
+	for _, r := range builtinResources {
+		index[r.GVR.Resource] = r
+		for _, alias := range r.Aliases {
+			index[alias] = r
+		}
+	}
+	return index
+}()
+
+// ResourceNames returns the plural, singular and short names of a built-in resource
+// type, or just the given name for other types
+func ResourceNames(resourceType string) []string {
+	builtin, ok := builtinResourceIndex[strings.ToLower(resourceType)]
+	if !ok {
+		return []string{resourceType}
+	}
+	return append([]string{builtin.GVR.Resource}, builtin.Aliases...)
+}
+
+// GVRResolver resolves one resource type for many clusters. Built-in types never
+// trigger discovery; other types are discovered once and then only verified against
+// the group version of each further cluster, falling back to full discovery there
+// when the cluster serves the resource differently.
+type GVRResolver struct {
+	resourceType string
+	builtin      bool
+	resolved     *builtinResource
+}
+
+// NewGVRResolver creates a resolver for the given resource type
+func NewGVRResolver(resourceType string) *GVRResolver {
+	r := &GVRResolver{resourceType: resourceType}
+	if builtin, ok := builtinResourceIndex[strings.ToLower(resourceType)]; ok {
+		r.builtin = true
+		r.resolved = &builtin
+	}
+	return r
+}
+
+// Resolve returns the GVR of the resource type in the cluster behind discoveryClient
+// and whether it is namespaced
+func (r *GVRResolver) Resolve(discoveryClient discovery.DiscoveryInterface) (schema.GroupVersionResource, bool, error) {
+	if r.builtin {
+		klog.V(4).Infof("Resolved %s to built-in %s", r.resourceType, r.resolved.GVR)
+		return r.resolved.GVR, r.resolved.Namespaced, nil
+	}
+
+	if r.resolved != nil {
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(r.resolved.GVR.GroupVersion().String())
+		if err == nil {
+			for _, apiResource := range resources.APIResources {
+				if apiResource.Name == r.resolved.GVR.Resource {
+					klog.V(4).Infof("Resolved %s to %s, verified against its group version", r.resourceType, r.resolved.GVR)
+					return r.resolved.GVR, apiResource.Namespaced, nil
+				}
+			}
+		}
+	}
+
+	gvr, namespaced, err := DiscoverGVR(discoveryClient, r.resourceType)
+	if err != nil {
+		klog.V(2).Infof("Failed to resolve %s through discovery: %v", r.resourceType, err)
+		return gvr, namespaced, err
+	}
+	klog.V(3).Infof("Resolved %s to %s through discovery (namespaced: %v)", r.resourceType, gvr, namespaced)
+	if r.resolved == nil {
+		r.resolved = &builtinResource{GVR: gvr, Namespaced: namespaced}
+	}
+	return gvr, namespaced, nil
+}
@@ -0,0 +1,29 @@
+package util
+
+import "testing"
+
+// TestGVRResolverBuiltin ensures built-in types resolve without a discovery client
+func TestGVRResolverBuiltin(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		group        string
+		resource     string
+		namespaced   bool
+	}{
+		{"po", "", "pods", true},
+		{"deploy", "apps", "deployments", true},
+		{"nodes", "", "nodes", false},
+		{"storageclass", "storage.k8s.io", "storageclasses", false},
+	}
+
+	for _, tt := range tests {
+		gvr, namespaced, err := NewGVRResolver(tt.resourceType).Resolve(nil)
+		if err != nil {
+			t.Errorf("Resolve(%q) returned error: %v", tt.resourceType, err)
+			continue
+		}
+		if gvr.Group != tt.group || gvr.Resource != tt.resource || namespaced != tt.namespaced {
+			t.Errorf("Resolve(%q) = %v, %v, want %s/%s, %v", tt.resourceType, gvr, namespaced, tt.group, tt.resource, tt.namespaced)
+		}
+	}
+}
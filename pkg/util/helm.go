@@ -0,0 +1,60 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// HelmReleaseSecretType is the type of the secrets Helm 3 stores releases in
+const HelmReleaseSecretType = "helm.sh/release.v1"
+
+// HelmRelease holds the fields of a Helm release that are shown in listings
+type HelmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int    `json:"version"`
+	Info      struct {
+		Status       string    `json:"status"`
+		LastDeployed time.Time `json:"last_deployed"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// gzipMagic starts the gzip compressed releases that Helm writes
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// DecodeHelmRelease decodes the release key of a Helm release secret, which holds the
+// base64 encoded and usually gzip compressed JSON of the release
+func DecodeHelmRelease(data []byte) (*HelmRelease, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release: %v", err)
+	}
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %v", err)
+		}
+		defer reader.Close()
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %v", err)
+		}
+	}
+
+	var release HelmRelease
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %v", err)
+	}
+	return &release, nil
+}
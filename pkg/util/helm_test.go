@@ -0,0 +1,27 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+)
+
+// TestDecodeHelmRelease ensures compressed and plain releases are decoded
+func TestDecodeHelmRelease(t *testing.T) {
+	release := `{"name":"web","namespace":"shop","version":3,"info":{"status":"deployed"},"chart":{"metadata":{"name":"nginx","version":"1.2.0","appVersion":"1.25"}}}`
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(release))
+	writer.Close()
+
+	for _, raw := range [][]byte{compressed.Bytes(), []byte(release)} {
+		got, err := DecodeHelmRelease([]byte(base64.StdEncoding.EncodeToString(raw)))
+		if err != nil {
+			t.Fatalf("DecodeHelmRelease() returned error: %v", err)
+		}
+		if got.Name != "web" || got.Revision != 3 || got.Info.Status != "deployed" || got.Chart.Metadata.Version != "1.2.0" {
+			t.Errorf("DecodeHelmRelease() = %+v", got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Theme holds the ANSI color sequences used for cluster headers and status values
+type Theme struct {
+	Header  string
+	Good    string
+	Warning string
+	Bad     string
+}
+
+const ansiReset = "\033[0m"
+
+// Themes are the built-in color themes selectable with --theme or the config file
+var Themes = map[string]Theme{
+	"default": {Header: "\033[1;36m", Good: "\033[32m", Warning: "\033[33m", Bad: "\033[31m"},
+	"bright":  {Header: "\033[1;96m", Good: "\033[1;92m", Warning: "\033[1;93m", Bad: "\033[1;91m"},
+	"mono":    {Header: "\033[1m", Good: "", Warning: "\033[4m", Bad: "\033[1;7m"},
+	"none":    {},
+}
+
+// statusLevels maps status values as printed by get to the theme color they use
+var statusLevels = map[string]string{
+	"Running":                  "good",
+	"Ready":                    "good",
+	"Active":                   "good",
+	"Bound":                    "good",
+	"Available":                "good",
+	"Completed":                "good",
+	"Complete":                 "good",
+	"Succeeded":                "good",
+	"Pending":                  "warning",
+	"ContainerCreating":        "warning",
+	"Terminating":              "warning",
+	"Unknown":                  "warning",
+	"NearLimit":                "warning",
+	"NotReady":                 "bad",
+	"Failed":                   "bad",
+	"Error":                    "bad",
+	"Evicted":                  "bad",
+	"CrashLoopBackOff":         "bad",
+	"ImagePullBackOff":         "bad",
+	"ErrImagePull":             "bad",
+	"Lost":                     "bad",
+	"OverLimit":                "bad",
+	"ProgressDeadlineExceeded": "bad",
+}
+
+// color returns the color sequence of a status level
+func (t Theme) color(level string) string {
+	switch level {
+	case "good":
+		return t.Good
+	case "warning":
+		return t.Warning
+	}
+	return t.Bad
+}
+
+var statusPattern = func() *regexp.Regexp {
+	var words []string
+	for word := range statusLevels {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return regexp.MustCompile(`\b(` + strings.Join(words, "|") + `)\b`)
+}()
+
+// activeTheme is nil while colors are disabled
+var activeTheme *Theme
+
+// SetTheme enables the named theme, or disables colors when enabled is false
+func SetTheme(name string, enabled bool) error {
+	theme, ok := Themes[name]
+	if !ok {
+		var names []string
+		for n := range Themes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown theme %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	activeTheme = nil
+	if enabled && name != "none" {
+		activeTheme = &theme
+	}
+	return nil
+}
+
+// ColorizeHeader colors a cluster section header
+func ColorizeHeader(header string) string {
+	if activeTheme == nil || activeTheme.Header == "" {
+		return header
+	}
+	return activeTheme.Header + header + ansiReset
+}
+
+// ColorizeStatus colors the known status values in text
+func ColorizeStatus(text string) string {
+	if activeTheme == nil {
+		return text
+	}
+	return statusPattern.ReplaceAllStringFunc(text, func(word string) string {
+		color := activeTheme.color(statusLevels[word])
+		if color == "" {
+			return word
+		}
+		return color + word + ansiReset
+	})
+}
+
+// ColorizeDiffLine colors a line of a unified diff: added lines good, removed lines
+// bad and hunk headers like cluster headers
+func ColorizeDiffLine(line string) string {
+	if activeTheme == nil {
+		return line
+	}
+	var color string
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+		color = activeTheme.Header
+	case strings.HasPrefix(line, "+"):
+		color = activeTheme.Good
+	case strings.HasPrefix(line, "-"):
+		color = activeTheme.Bad
+	}
+	if color == "" {
+		return line
+	}
+	return color + line + ansiReset
+}
+
+// colorWriter colors status values on their way to the terminal. Tables are aligned
+// by their tabwriter before they get here, so the escape sequences do not shift columns.
+type colorWriter struct {
+	out io.Writer
+}
+
+func (w colorWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, ColorizeStatus(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}